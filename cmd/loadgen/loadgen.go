@@ -0,0 +1,507 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	log "github.com/sirupsen/logrus"
+	"io"
+	"math/rand"
+	"net/http"
+	"os"
+	"seamless-api-wrapper/internal/logger"
+	"seamless-api-wrapper/internal/rpc"
+	"seamless-api-wrapper/package/dto"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	methodGetBalance          = "getBalance"
+	methodWithdrawAndDeposit  = "withdrawAndDeposit"
+	methodRollbackTransaction = "rollbackTransaction"
+)
+
+type options struct {
+	target       string
+	players      int
+	playerPrefix string
+	currency     string
+	callerId     int
+	duration     time.Duration
+	requests     int
+	batch        int
+	betMin       int64
+	betMax       int64
+	winRate      float64
+	mix          map[string]int
+}
+
+type call struct {
+	method string
+	params any
+	// apply is called with the result of a successful call.
+	apply func(result json.RawMessage) error
+}
+
+type response struct {
+	Result json.RawMessage `json:"result"`
+	Error  *rpc.Error      `json:"error"`
+	Id     int             `json:"id"`
+}
+
+func main() {
+	opts := options{}
+
+	flag.StringVar(&opts.target, "target", "http://localhost:8080", "server url")
+	flag.IntVar(&opts.players, "players", 10, "number of concurrent players")
+	flag.StringVar(&opts.playerPrefix, "player-prefix", "player", "player names are the prefix followed by 1..players")
+	flag.StringVar(&opts.currency, "currency", "EUR", "wallet currency")
+	flag.IntVar(&opts.callerId, "caller-id", 1, "callerId sent with every call")
+	flag.DurationVar(&opts.duration, "duration", 30*time.Second, "test duration")
+	flag.IntVar(&opts.requests, "requests", 0, "stop every player after this many HTTP requests, 0 runs for -duration")
+	flag.IntVar(&opts.batch, "batch", 1, "calls per HTTP request, more than 1 sends JSON-RPC batches")
+	flag.Int64Var(&opts.betMin, "bet-min", 10, "minimal bet in minor units")
+	flag.Int64Var(&opts.betMax, "bet-max", 100, "maximal bet in minor units")
+	flag.Float64Var(&opts.winRate, "win-rate", 0.45, "share of bets that win")
+	mix := flag.String("mix", "getBalance=20,withdrawAndDeposit=75,rollbackTransaction=5", "call mix as method=weight pairs")
+
+	flag.Parse()
+
+	logger.InitLogger(os.Stderr, log.InfoLevel)
+
+	var err error
+	if opts.mix, err = parseMix(*mix); err != nil {
+		log.Fatal(err)
+	}
+
+	if opts.players < 1 || opts.batch < 1 || opts.betMin < 1 || opts.betMax < opts.betMin {
+		log.Fatal("players, batch and bet sizes must be positive and bet-min <= bet-max")
+	}
+
+	client := &http.Client{
+		Timeout: 30 * time.Second,
+		Transport: &http.Transport{
+			MaxIdleConns:        opts.players,
+			MaxIdleConnsPerHost: opts.players,
+		},
+	}
+
+	stats := newStats()
+	runID := strconv.FormatInt(time.Now().UnixNano(), 36)
+
+	players := make([]*player, opts.players)
+	for i := range players {
+		players[i] = &player{
+			name:   fmt.Sprintf("%s%d", opts.playerPrefix, i+1),
+			ref:    fmt.Sprintf("loadgen:%s:%d", runID, i+1),
+			opts:   &opts,
+			client: client,
+			stats:  stats,
+			rand:   rand.New(rand.NewSource(time.Now().UnixNano() + int64(i))),
+		}
+	}
+
+	var wg sync.WaitGroup
+	deadline := time.Now().Add(opts.duration)
+	started := time.Now()
+
+	for _, p := range players {
+		wg.Add(1)
+		go func(p *player) {
+			defer wg.Done()
+			p.run(deadline)
+		}(p)
+	}
+	wg.Wait()
+
+	elapsed := time.Since(started)
+
+	mismatches := 0
+	for _, p := range players {
+		if err := p.verify(); err != nil {
+			mismatches++
+			log.Errorf("%s: %v", p.name, err)
+		}
+	}
+
+	stats.report(os.Stdout, elapsed, len(players), mismatches)
+
+	if mismatches > 0 {
+		os.Exit(1)
+	}
+}
+
+func parseMix(mix string) (map[string]int, error) {
+	weights := make(map[string]int)
+	for _, part := range strings.Split(mix, ",") {
+		name, value, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if !ok {
+			return nil, fmt.Errorf("mix: expected method=weight, got %q", part)
+		}
+
+		switch name {
+		case methodGetBalance, methodWithdrawAndDeposit, methodRollbackTransaction:
+		default:
+			return nil, fmt.Errorf("mix: unknown method %q", name)
+		}
+
+		weight, err := strconv.Atoi(value)
+		if err != nil || weight < 0 {
+			return nil, fmt.Errorf("mix: bad weight for %s: %q", name, value)
+		}
+		weights[name] = weight
+	}
+
+	if weights[methodGetBalance]+weights[methodWithdrawAndDeposit]+weights[methodRollbackTransaction] == 0 {
+		return nil, errors.New("mix: all weights are zero")
+	}
+
+	return weights, nil
+}
+
+// player runs calls for one wallet sequentially and keeps the balance it
+// expects the server to hold after every successful call.
+type player struct {
+	name   string
+	ref    string
+	opts   *options
+	client *http.Client
+	stats  *stats
+	rand   *rand.Rand
+
+	seq       int
+	expected  int64
+	committed []*dto.WithdrawAndDepositReq
+	failed    bool
+}
+
+func (p *player) run(deadline time.Time) {
+	if err := p.do([]*call{p.balanceCall(func(balance int64) { p.expected = balance })}); err != nil {
+		p.failed = true
+		log.Errorf("%s: initial balance: %v", p.name, err)
+		return
+	}
+
+	for i := 0; p.opts.requests == 0 || i < p.opts.requests; i++ {
+		if p.opts.requests == 0 && time.Now().After(deadline) {
+			return
+		}
+
+		calls := make([]*call, 0, p.opts.batch)
+		rollbacks := make(map[string]struct{})
+		for len(calls) < p.opts.batch {
+			calls = append(calls, p.nextCall(rollbacks))
+		}
+
+		if err := p.do(calls); err != nil {
+			p.failed = true
+			log.Errorf("%s: %v", p.name, err)
+			return
+		}
+	}
+}
+
+func (p *player) verify() error {
+	if p.failed {
+		return errors.New("stopped on an error, balance not checked")
+	}
+
+	var actual int64
+	if err := p.do([]*call{p.balanceCall(func(balance int64) { actual = balance })}); err != nil {
+		return err
+	}
+
+	if actual != p.expected {
+		return fmt.Errorf("balance %d, expected %d", actual, p.expected)
+	}
+	return nil
+}
+
+func (p *player) nextCall(rollbacks map[string]struct{}) *call {
+	mix := p.opts.mix
+	n := p.rand.Intn(mix[methodGetBalance] + mix[methodWithdrawAndDeposit] + mix[methodRollbackTransaction])
+
+	switch {
+	case n < mix[methodGetBalance]:
+		return p.balanceCall(nil)
+	case n < mix[methodGetBalance]+mix[methodWithdrawAndDeposit]:
+		return p.betCall()
+	default:
+		return p.rollbackCall(rollbacks)
+	}
+}
+
+func (p *player) balanceCall(fn func(balance int64)) *call {
+	return &call{
+		method: methodGetBalance,
+		params: &dto.GetBalanceReq{CallerId: p.opts.callerId, PlayerName: p.name, Currency: p.opts.currency},
+		apply: func(result json.RawMessage) error {
+			if fn == nil {
+				return nil
+			}
+
+			var resp dto.GetBalanceResp
+			if err := json.Unmarshal(result, &resp); err != nil {
+				return err
+			}
+			fn(resp.Balance)
+			return nil
+		},
+	}
+}
+
+func (p *player) betCall() *call {
+	p.seq++
+
+	bet := p.opts.betMin + p.rand.Int63n(p.opts.betMax-p.opts.betMin+1)
+	var win int64
+	if p.rand.Float64() < p.opts.winRate {
+		win = bet + p.rand.Int63n(2*bet+1)
+	}
+
+	req := &dto.WithdrawAndDepositReq{
+		CallerId:       p.opts.callerId,
+		PlayerName:     p.name,
+		Withdraw:       bet,
+		Deposit:        win,
+		Currency:       p.opts.currency,
+		TransactionRef: fmt.Sprintf("%s:%d", p.ref, p.seq),
+	}
+
+	return &call{
+		method: methodWithdrawAndDeposit,
+		params: req,
+		apply: func(json.RawMessage) error {
+			p.expected += req.Deposit - req.Withdraw
+			p.committed = append(p.committed, req)
+			return nil
+		},
+	}
+}
+
+// rollbackCall reverses a bet committed by an earlier request, or sends a
+// rollback for an unknown reference when there is none.
+func (p *player) rollbackCall(rollbacks map[string]struct{}) *call {
+	req := &dto.RollbackTransactionReq{CallerId: p.opts.callerId, PlayerName: p.name}
+
+	var target *dto.WithdrawAndDepositReq
+	if len(p.committed) > 0 {
+		i := p.rand.Intn(len(p.committed))
+		if _, ok := rollbacks[p.committed[i].TransactionRef]; !ok {
+			target = p.committed[i]
+			p.committed[i] = p.committed[len(p.committed)-1]
+			p.committed = p.committed[:len(p.committed)-1]
+		}
+	}
+
+	if target != nil {
+		req.TransactionRef = target.TransactionRef
+		rollbacks[target.TransactionRef] = struct{}{}
+	} else {
+		p.seq++
+		req.TransactionRef = fmt.Sprintf("%s:%d", p.ref, p.seq)
+	}
+
+	return &call{
+		method: methodRollbackTransaction,
+		params: req,
+		apply: func(json.RawMessage) error {
+			if target != nil {
+				p.expected += target.Withdraw - target.Deposit
+			}
+			return nil
+		},
+	}
+}
+
+// do sends the calls as one request, or as a batch when there are several,
+// and applies every successful result.
+func (p *player) do(calls []*call) error {
+	requests := make([]*rpc.BaseRequest, len(calls))
+	for i, c := range calls {
+		params, err := json.Marshal(c.params)
+		if err != nil {
+			return err
+		}
+
+		requests[i] = &rpc.BaseRequest{
+			JsonRPC: rpc.Version,
+			Method:  c.method,
+			Params:  params,
+			Id:      json.RawMessage(strconv.Itoa(i)),
+		}
+	}
+
+	var body []byte
+	var err error
+	if len(requests) == 1 {
+		body, err = json.Marshal(requests[0])
+	} else {
+		body, err = json.Marshal(requests)
+	}
+	if err != nil {
+		return err
+	}
+
+	started := time.Now()
+	resp, err := p.client.Post(p.opts.target, "application/json", bytes.NewReader(body))
+	if err != nil {
+		p.stats.transportError(time.Since(started))
+		return err
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	latency := time.Since(started)
+	if err != nil {
+		p.stats.transportError(latency)
+		return err
+	}
+
+	var responses []*response
+	if len(requests) == 1 {
+		var single response
+		err = json.Unmarshal(data, &single)
+		responses = append(responses, &single)
+	} else {
+		err = json.Unmarshal(data, &responses)
+	}
+	if err != nil {
+		p.stats.transportError(latency)
+		return fmt.Errorf("http %d: %w: %s", resp.StatusCode, err, data)
+	}
+
+	p.stats.request(latency)
+
+	for _, r := range responses {
+		if r.Id < 0 || r.Id >= len(calls) {
+			return fmt.Errorf("unexpected response id %d", r.Id)
+		}
+
+		c := calls[r.Id]
+		if r.Error != nil && r.Error.Code == rpc.InvalidParamsCode {
+			// the server refused a request the tool built, the run would
+			// measure nothing but its own bug
+			return fmt.Errorf("%s rejected as invalid params: %+v", c.method, c.params)
+		}
+		if r.Error != nil {
+			p.stats.callError(c.method, r.Error)
+			continue
+		}
+
+		p.stats.callOK(c.method)
+		if err := c.apply(r.Result); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+type stats struct {
+	lock      sync.Mutex
+	latencies []time.Duration
+	calls     map[string]int
+	errors    map[string]map[string]int
+	transport int
+}
+
+func newStats() *stats {
+	return &stats{
+		calls:  make(map[string]int),
+		errors: make(map[string]map[string]int),
+	}
+}
+
+func (s *stats) request(latency time.Duration) {
+	s.lock.Lock()
+	s.latencies = append(s.latencies, latency)
+	s.lock.Unlock()
+}
+
+func (s *stats) transportError(latency time.Duration) {
+	s.lock.Lock()
+	s.latencies = append(s.latencies, latency)
+	s.transport++
+	s.lock.Unlock()
+}
+
+func (s *stats) callOK(method string) {
+	s.lock.Lock()
+	s.calls[method]++
+	s.lock.Unlock()
+}
+
+func (s *stats) callError(method string, err *rpc.Error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.calls[method]++
+	if s.errors[method] == nil {
+		s.errors[method] = make(map[string]int)
+	}
+	s.errors[method][fmt.Sprintf("%d %s", err.Code, err.Message)]++
+}
+
+func (s *stats) report(w io.Writer, elapsed time.Duration, players, mismatches int) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	sort.Slice(s.latencies, func(i, j int) bool { return s.latencies[i] < s.latencies[j] })
+
+	totalCalls := 0
+	for _, n := range s.calls {
+		totalCalls += n
+	}
+
+	seconds := elapsed.Seconds()
+	fmt.Fprintf(w, "duration:    %s\n", elapsed.Round(time.Millisecond))
+	fmt.Fprintf(w, "players:     %d\n", players)
+	fmt.Fprintf(w, "requests:    %d (%.1f/s), transport errors %d\n", len(s.latencies), float64(len(s.latencies))/seconds, s.transport)
+	fmt.Fprintf(w, "calls:       %d (%.1f/s)\n", totalCalls, float64(totalCalls)/seconds)
+	fmt.Fprintf(w, "latency:     p50 %s  p90 %s  p99 %s  max %s\n",
+		s.percentile(0.5), s.percentile(0.9), s.percentile(0.99), s.percentile(1))
+
+	methods := make([]string, 0, len(s.calls))
+	for method := range s.calls {
+		methods = append(methods, method)
+	}
+	sort.Strings(methods)
+
+	for _, method := range methods {
+		fmt.Fprintf(w, "%-22s %d calls\n", method, s.calls[method])
+
+		codes := make([]string, 0, len(s.errors[method]))
+		for code := range s.errors[method] {
+			codes = append(codes, code)
+		}
+		sort.Strings(codes)
+
+		for _, code := range codes {
+			fmt.Fprintf(w, "    error %-30s %d\n", code, s.errors[method][code])
+		}
+	}
+
+	fmt.Fprintf(w, "balances:    %d of %d players match the expected total\n", players-mismatches, players)
+}
+
+func (s *stats) percentile(p float64) time.Duration {
+	if len(s.latencies) == 0 {
+		return 0
+	}
+
+	i := int(float64(len(s.latencies))*p+0.5) - 1
+	if i < 0 {
+		i = 0
+	}
+	if i >= len(s.latencies) {
+		i = len(s.latencies) - 1
+	}
+	return s.latencies[i].Round(time.Microsecond)
+}
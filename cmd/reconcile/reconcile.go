@@ -0,0 +1,148 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	log "github.com/sirupsen/logrus"
+	"io"
+	"os"
+	"seamless-api-wrapper/internal/config"
+	"seamless-api-wrapper/internal/logger"
+	"seamless-api-wrapper/internal/model"
+	"seamless-api-wrapper/internal/postgres"
+	"seamless-api-wrapper/internal/service"
+	"strconv"
+)
+
+// Discrepancy is written for every stored value that differs from what it
+// is recomputed from.
+type Discrepancy struct {
+	Check      string `json:"check"`
+	PlayerName string `json:"player_name"`
+	Currency   string `json:"currency"`
+	BalanceID  int    `json:"balance_id"`
+	Ref        string `json:"ref,omitempty"`
+	Stored     int64  `json:"stored"`
+	Expected   int64  `json:"expected"`
+	Difference int64  `json:"difference"`
+}
+
+func main() {
+	configFile := flag.String("config", "./config.toml", "config file with the postgres section")
+	format := flag.String("format", "csv", "report format, csv or json")
+	out := flag.String("out", "", "report output file, stdout by default")
+	repair := flag.Bool("repair", false, "write adjusting entries and rebuild the cached balances")
+
+	flag.Parse()
+
+	logger.InitLogger(os.Stderr, log.InfoLevel)
+
+	if *format != "csv" && *format != "json" {
+		log.Fatalf("unknown report format '%s'", *format)
+	}
+
+	cfg, err := config.ParseServerConfig(*configFile)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	db, err := postgres.InitDB(&cfg.Postgres)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer db.Close()
+
+	seamlessService := postgres.NewSeamlessService(db)
+	ctx := context.Background()
+
+	discrepancies, err := seamlessService.Reconcile(ctx)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	var reportOut io.Writer = os.Stdout
+	if *out != "" {
+		file, err := os.Create(*out)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer file.Close()
+		reportOut = file
+	}
+
+	if err := report(reportOut, *format, discrepancies); err != nil {
+		log.Fatal(err)
+	}
+
+	log.Infof("found %d discrepancies", len(discrepancies))
+
+	if len(discrepancies) == 0 {
+		return
+	}
+
+	if !*repair {
+		os.Exit(1)
+	}
+
+	if err := seamlessService.Repair(service.WithActor(ctx, "reconcile"), discrepancies); err != nil {
+		log.Fatal(err)
+	}
+
+	left, err := seamlessService.Reconcile(ctx)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	log.Infof("repaired, %d discrepancies left", len(left))
+
+	if len(left) > 0 {
+		os.Exit(1)
+	}
+}
+
+func report(w io.Writer, format string, discrepancies []model.Discrepancy) error {
+	if format == "json" {
+		encoder := json.NewEncoder(w)
+		for _, d := range discrepancies {
+			if err := encoder.Encode(newDiscrepancy(d)); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	writer := csv.NewWriter(w)
+	err := writer.Write([]string{"check", "player_name", "currency", "balance_id", "ref", "stored", "expected", "difference"})
+	if err != nil {
+		return err
+	}
+
+	for _, d := range discrepancies {
+		r := newDiscrepancy(d)
+		err := writer.Write([]string{
+			r.Check, r.PlayerName, r.Currency, strconv.Itoa(r.BalanceID), r.Ref,
+			strconv.FormatInt(r.Stored, 10), strconv.FormatInt(r.Expected, 10), strconv.FormatInt(r.Difference, 10),
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+func newDiscrepancy(d model.Discrepancy) Discrepancy {
+	return Discrepancy{
+		Check:      string(d.Check),
+		PlayerName: d.PlayerName,
+		Currency:   d.Currency,
+		BalanceID:  d.BalanceID,
+		Ref:        d.Ref,
+		Stored:     d.Stored,
+		Expected:   d.Expected,
+		Difference: d.Expected - d.Stored,
+	}
+}
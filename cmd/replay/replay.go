@@ -0,0 +1,260 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	log "github.com/sirupsen/logrus"
+	"io"
+	"net/http"
+	"os"
+	"reflect"
+	"seamless-api-wrapper/internal/capture"
+	"seamless-api-wrapper/internal/logger"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Diff is written for every replayed request whose response differs from
+// the captured one.
+type Diff struct {
+	RequestID  string          `json:"request_id"`
+	Method     string          `json:"method,omitempty"`
+	Request    json.RawMessage `json:"request,omitempty"`
+	RequestRaw []byte          `json:"request_raw,omitempty"`
+	Expected   json.RawMessage `json:"expected,omitempty"`
+	Actual     json.RawMessage `json:"actual,omitempty"`
+	Error      string          `json:"error,omitempty"`
+}
+
+type replayer struct {
+	target  string
+	client  *http.Client
+	ignore  map[string]struct{}
+	diffs   chan *Diff
+	total   atomic.Int64
+	matched atomic.Int64
+	failed  atomic.Int64
+}
+
+func main() {
+	capturePath := flag.String("capture", "./capture", "capture file or directory")
+	target := flag.String("target", "http://localhost:8080", "server url")
+	speed := flag.Float64("speed", 1, "replay speed relative to the capture, 0 sends without delays")
+	concurrency := flag.Int("concurrency", 1, "max requests in flight, above 1 calls of one player may be reordered")
+	ignore := flag.String("ignore", "transactionId", "comma separated JSON keys ignored by the diff")
+	out := flag.String("out", "", "diff output file, stdout by default")
+
+	flag.Parse()
+
+	logger.InitLogger(os.Stderr, log.InfoLevel)
+
+	files, err := captureFiles(*capturePath)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	var diffOut io.Writer = os.Stdout
+	if *out != "" {
+		file, err := os.Create(*out)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer file.Close()
+		diffOut = file
+	}
+
+	r := &replayer{
+		target: *target,
+		client: &http.Client{Timeout: 30 * time.Second},
+		ignore: make(map[string]struct{}),
+		diffs:  make(chan *Diff, *concurrency),
+	}
+	for _, key := range strings.Split(*ignore, ",") {
+		if key = strings.TrimSpace(key); key != "" {
+			r.ignore[key] = struct{}{}
+		}
+	}
+
+	written := make(chan struct{})
+	go func() {
+		defer close(written)
+		encoder := json.NewEncoder(diffOut)
+		for diff := range r.diffs {
+			if err := encoder.Encode(diff); err != nil {
+				log.Error(err)
+			}
+		}
+	}()
+
+	started := time.Now()
+	if err := r.replay(files, *speed, *concurrency); err != nil {
+		log.Error(err)
+	}
+	close(r.diffs)
+	<-written
+
+	total, matched := r.total.Load(), r.matched.Load()
+	log.Infof("replayed %d requests in %s: %d matched, %d differ, %d failed",
+		total, time.Since(started).Round(time.Millisecond), matched, total-matched-r.failed.Load(), r.failed.Load())
+
+	if matched != total {
+		os.Exit(1)
+	}
+}
+
+func captureFiles(path string) ([]string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if !info.IsDir() {
+		return []string{path}, nil
+	}
+
+	return capture.Files(path)
+}
+
+// replay sends the records in capture order, keeping their original spacing
+// divided by speed.
+func (r *replayer) replay(files []string, speed float64, concurrency int) error {
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, concurrency)
+
+	var first time.Time
+	start := time.Now()
+
+	for _, file := range files {
+		err := capture.ReadFile(file, func(record *capture.Record) error {
+			if first.IsZero() {
+				first = record.StartedAt
+			}
+
+			if speed > 0 {
+				offset := time.Duration(float64(record.StartedAt.Sub(first)) / speed)
+				time.Sleep(time.Until(start.Add(offset)))
+			}
+
+			sem <- struct{}{}
+			wg.Add(1)
+			go func() {
+				defer func() {
+					<-sem
+					wg.Done()
+				}()
+				r.send(record)
+			}()
+
+			return nil
+		})
+		if err != nil {
+			wg.Wait()
+			return fmt.Errorf("%s: %w", file, err)
+		}
+	}
+
+	wg.Wait()
+	return nil
+}
+
+func (r *replayer) send(record *capture.Record) {
+	r.total.Add(1)
+
+	diff := &Diff{
+		RequestID:  record.RequestID,
+		Method:     record.Method,
+		Request:    record.Request,
+		RequestRaw: record.RequestRaw,
+		Expected:   record.Response,
+	}
+
+	req, err := http.NewRequest(http.MethodPost, r.target, bytes.NewReader(record.Body()))
+	if err != nil {
+		r.fail(diff, err)
+		return
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		r.fail(diff, err)
+		return
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		r.fail(diff, err)
+		return
+	}
+
+	body = bytes.TrimSpace(body)
+	if len(body) > 0 {
+		diff.Actual = body
+	}
+
+	if r.equal(record.Response, body) {
+		r.matched.Add(1)
+		return
+	}
+
+	r.diffs <- diff
+}
+
+func (r *replayer) fail(diff *Diff, err error) {
+	r.failed.Add(1)
+	diff.Error = err.Error()
+	r.diffs <- diff
+}
+
+func (r *replayer) equal(expected, actual []byte) bool {
+	if len(expected) == 0 || len(actual) == 0 {
+		return len(expected) == len(actual)
+	}
+
+	a, err := r.normalize(expected)
+	if err != nil {
+		return false
+	}
+
+	b, err := r.normalize(actual)
+	if err != nil {
+		return false
+	}
+
+	return reflect.DeepEqual(a, b)
+}
+
+func (r *replayer) normalize(data []byte) (any, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var value any
+	if err := decoder.Decode(&value); err != nil {
+		return nil, err
+	}
+
+	r.strip(value)
+	return value, nil
+}
+
+func (r *replayer) strip(value any) {
+	switch v := value.(type) {
+	case map[string]any:
+		for key, item := range v {
+			if _, ok := r.ignore[key]; ok {
+				delete(v, key)
+				continue
+			}
+			r.strip(item)
+		}
+	case []any:
+		for _, item := range v {
+			r.strip(item)
+		}
+	}
+}
@@ -3,16 +3,21 @@ package main
 import (
 	"context"
 	"flag"
+	"fmt"
 	log "github.com/sirupsen/logrus"
 	"os"
 	"os/signal"
 	"seamless-api-wrapper/internal/api/seamless"
+	"seamless-api-wrapper/internal/capture"
 	"seamless-api-wrapper/internal/config"
 	"seamless-api-wrapper/internal/logger"
+	"seamless-api-wrapper/internal/memory"
 	"seamless-api-wrapper/internal/postgres"
 	"seamless-api-wrapper/internal/rpc"
+	"seamless-api-wrapper/internal/service"
 	"seamless-api-wrapper/internal/transport"
 	"syscall"
+	"time"
 )
 
 func main() {
@@ -27,38 +32,168 @@ func main() {
 		log.Fatal(err)
 	}
 
+	if cfg.Admin.Enabled && len(cfg.Admin.Server.Auth.Tokens) == 0 {
+		log.Fatal("admin server needs [[admin.server.auth.tokens]]")
+	}
+
+	checkTokens(cfg.Server.Auth.Tokens)
+	if cfg.Admin.Enabled {
+		checkTokens(cfg.Admin.Server.Auth.Tokens)
+	}
+
 	serverConf := cfg.Server
 
-	httpTransport := transport.NewHttpTransport(serverConf.Address, serverConf.ReadTimeout.Duration, serverConf.WriteTimeout.Duration)
+	httpTransport := transport.NewHttpTransport(&serverConf)
 
 	rpcServer := rpc.NewServer(httpTransport)
 
-	db, err := postgres.InitDB(&cfg.Postgres)
+	if cfg.Capture.Enabled {
+		recorder, err := capture.NewRecorder(&cfg.Capture)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer func() {
+			if err := recorder.Close(); err != nil {
+				log.Error(err)
+			}
+		}()
+
+		rpcServer.Use(recorder.Middleware)
+	}
+
+	seamlessService, err := newSeamlessService(cfg)
 	if err != nil {
 		log.Fatal(err)
 	}
 
-	seamlessService := postgres.NewSeamlessService(db)
+	switch order := service.SpendOrder(cfg.Bonus.SpendOrder); order {
+	case "":
+	case service.CashFirst, service.BonusFirst:
+		seamlessService.SetSpendOrder(order)
+	default:
+		log.Fatalf("unknown bonus spend order '%s'", order)
+	}
+
+	for _, currency := range cfg.Currencies {
+		if _, err := seamlessService.CreateCurrency(context.Background(), currency.Code, currency.Exponent); err != nil {
+			log.Fatalf("currency %s: %v", currency.Code, err)
+		}
+	}
 
 	api := seamless.NewSeamless(seamlessService)
 
-	rpcServer.Register("getBalance", rpc.HandlerWithPointer(api.GetBalance))
-	rpcServer.Register("withdrawAndDeposit", rpc.HandlerWithPointer(api.WithdrawAndDeposit))
-	rpcServer.Register("rollbackTransaction", rpc.HandlerWithPointer(api.RollbackTransaction))
+	api.Register(rpcServer)
 
 	ctx, cancel := context.WithCancel(context.Background())
 
 	done := make(chan os.Signal, 1)
 	signal.Notify(done, os.Interrupt, syscall.SIGINT, syscall.SIGTERM)
 
+	if cfg.Rounds.InactivityTimeout.Duration > 0 {
+		go closeInactiveRounds(ctx, seamlessService, &cfg.Rounds)
+	}
+
+	stopped := make(chan struct{})
 	go func() {
+		defer close(stopped)
 		if err := rpcServer.Run(ctx); err != nil {
 			log.Error(err)
 		}
 	}()
 	log.Info("Server Started")
-	<-done
-	log.Info("Server Stopped")
+
+	adminStopped := make(chan struct{})
+	if cfg.Admin.Enabled {
+		go func() {
+			defer close(adminStopped)
+			if err := runAdmin(ctx, &cfg.Admin, seamlessService); err != nil {
+				log.Error(err)
+			}
+		}()
+		log.Info("Admin Server Started")
+	} else {
+		close(adminStopped)
+	}
+
+	select {
+	case <-done:
+	case <-stopped:
+	}
 
 	cancel()
+	<-stopped
+	<-adminStopped
+	log.Info("Server Stopped")
+}
+
+// runAdmin serves the back-office methods on their own address, only to
+// callers with one of the admin tokens.
+func runAdmin(ctx context.Context, cfg *config.Admin, storage storage) error {
+	adminServer := rpc.NewServer(transport.NewHttpTransport(&cfg.Server))
+	seamless.NewAdmin(storage).Register(adminServer)
+
+	return adminServer.Run(ctx)
+}
+
+// shippedToken is the placeholder token of config.toml, anyone who read the
+// repository knows it.
+const shippedToken = "change-me"
+
+// checkTokens stops the server when a token is still the shipped one.
+func checkTokens(tokens []config.Token) {
+	for _, token := range tokens {
+		if token.Token == shippedToken {
+			log.Fatalf("token '%s' is still '%s', set a secret one", token.Name, shippedToken)
+		}
+	}
+}
+
+// storage is a storage driver, the bonus spend order is set on it at start.
+type storage interface {
+	seamless.Storage
+	SetSpendOrder(order service.SpendOrder)
+}
+
+func newSeamlessService(cfg *config.ServerConfig) (storage, error) {
+	switch cfg.Storage.Driver {
+	case "", "postgres":
+		db, err := postgres.InitDB(&cfg.Postgres)
+		if err != nil {
+			return nil, err
+		}
+		return postgres.NewSeamlessService(db), nil
+	case "memory":
+		log.Warn("using in-memory storage, wallets are lost on restart")
+		return memory.Init(&cfg.Memory), nil
+	}
+
+	return nil, fmt.Errorf("unknown storage driver '%s'", cfg.Storage.Driver)
+}
+
+func closeInactiveRounds(ctx context.Context, rounds service.RoundService, cfg *config.Rounds) {
+	interval := cfg.CheckInterval.Duration
+	if interval <= 0 {
+		interval = time.Minute
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		closed, err := rounds.CloseInactiveRounds(ctx, time.Now().Add(-cfg.InactivityTimeout.Duration))
+		if err != nil {
+			log.Error(err)
+			continue
+		}
+
+		if closed > 0 {
+			log.Infof("closed %d inactive rounds", closed)
+		}
+	}
 }
@@ -7,6 +7,7 @@ require (
 	github.com/antonfisher/nested-logrus-formatter v1.3.1
 	github.com/go-playground/validator/v10 v10.11.0
 	github.com/jmoiron/sqlx v1.3.5
+	github.com/klauspost/compress v1.15.9
 	github.com/lib/pq v1.2.0
 	github.com/sirupsen/logrus v1.9.0
 	github.com/stretchr/testify v1.7.0
@@ -1,29 +1,38 @@
 CREATE TABLE IF NOT EXISTS currencies
 (
-    "id"   BIGSERIAL NOT NULL PRIMARY KEY,
-    "code" VARCHAR   NOT NULL
+    "id"       BIGSERIAL NOT NULL PRIMARY KEY,
+    "code"     VARCHAR   NOT NULL,
+    "exponent" SMALLINT  NOT NULL DEFAULT 2 CHECK (exponent BETWEEN 0 AND 18)
 );
 
-INSERT INTO currencies(id, code)
-VALUES (1, 'EUR');
+CREATE UNIQUE INDEX currencies_code ON currencies (code);
+
+INSERT INTO currencies(code, exponent)
+VALUES ('EUR', 2),
+       ('USD', 2),
+       ('GBP', 2),
+       ('JPY', 0),
+       ('IDR', 2),
+       ('BTC', 8),
+       ('ETH', 18),
+       ('USDT', 6);
 
 CREATE TABLE IF NOT EXISTS balances
 (
     "id"                          BIGSERIAL NOT NULL PRIMARY KEY,
     "player_name"                 VARCHAR   NOT NULL,
     "currency_id"                 BIGINT    NOT NULL,
-    "amount"                      INTEGER   NOT NULL,
+    "amount"                      BIGINT    NOT NULL,
     "game_id"                     VARCHAR,
     "last_session_id"             VARCHAR,
     "last_session_alternative_id" VARCHAR,
-    "free_round_left"             INTEGER,
     "created_at"                  TIMESTAMP WITHOUT TIME ZONE,
     "updated_at"                  TIMESTAMP WITHOUT TIME ZONE,
     CONSTRAINT
         fk_currency FOREIGN KEY (currency_id) REFERENCES currencies (id)
 );
 
-CREATE UNIQUE INDEX balances_player ON balances (player_name);
+CREATE UNIQUE INDEX balances_player_currency ON balances (player_name, currency_id);
 
 INSERT INTO balances(player_name, currency_id, amount, game_id, created_at, updated_at)
 VALUES ('player1', 1, 10000, 'riot', NOW(), NOW());
@@ -32,10 +41,10 @@ CREATE TABLE IF NOT EXISTS transactions
 (
     "id"                     BIGSERIAL NOT NULL PRIMARY KEY,
     "balance_id"             BIGINT    NOT NULL,
-    "withdraw"               INTEGER   NOT NULL,
-    "deposit"                INTEGER   NOT NULL,
+    "withdraw"               BIGINT    NOT NULL,
+    "deposit"                BIGINT    NOT NULL,
     "transaction_ref"        VARCHAR   NOT NULL,
-    "is_rollback"            BOOLEAN   NOT NULL DEFAULT FALSE,
+    "status"                 VARCHAR   NOT NULL,
     "game_id"                VARCHAR,
     "game_round_ref"         VARCHAR,
     "source"                 VARCHAR,
@@ -44,6 +53,14 @@ CREATE TABLE IF NOT EXISTS transactions
     "session_alternative_id" VARCHAR,
     "bonus_id"               VARCHAR,
     "charge_free_rounds"     INTEGER,
+    "wagered_bonus_id"       BIGINT,
+    "bonus_withdraw"         BIGINT    NOT NULL DEFAULT 0,
+    "bonus_deposit"          BIGINT    NOT NULL DEFAULT 0,
+    "bonus_converted"        BIGINT    NOT NULL DEFAULT 0,
+    "balance_after"          BIGINT,
+    "bonus_amount_after"     BIGINT,
+    "free_round_left_after"  INTEGER,
+    "close_round"            BOOLEAN   NOT NULL DEFAULT FALSE,
     "created_at"             TIMESTAMP WITHOUT TIME ZONE,
     "updated_at"             TIMESTAMP WITHOUT TIME ZONE,
     CONSTRAINT
@@ -51,7 +68,23 @@ CREATE TABLE IF NOT EXISTS transactions
 );
 
 CREATE UNIQUE INDEX transactions_uniq_idx ON transactions (transaction_ref);
-CREATE INDEX transactions_balance_idx ON transactions (balance_id);
+CREATE INDEX transactions_balance_idx ON transactions (balance_id, id);
+CREATE INDEX transactions_round_idx ON transactions (balance_id, game_round_ref);
+CREATE INDEX transactions_created_idx ON transactions (balance_id, created_at);
+
+CREATE TABLE IF NOT EXISTS transaction_transitions
+(
+    "id"             BIGSERIAL NOT NULL PRIMARY KEY,
+    "transaction_id" BIGINT    NOT NULL,
+    "from_status"    VARCHAR,
+    "to_status"      VARCHAR   NOT NULL,
+    "reason"         VARCHAR   NOT NULL,
+    "created_at"     TIMESTAMP WITHOUT TIME ZONE NOT NULL,
+    CONSTRAINT
+        fk_transaction FOREIGN KEY (transaction_id) REFERENCES transactions (id)
+);
+
+CREATE INDEX transaction_transitions_transaction_idx ON transaction_transitions (transaction_id);
 
 CREATE TABLE IF NOT EXISTS spin_details
 (
@@ -63,4 +96,175 @@ CREATE TABLE IF NOT EXISTS spin_details
         fk_transaction FOREIGN KEY (transaction_id) REFERENCES transactions (id)
 );
 
-CREATE INDEX spin_details_transaction_idx ON spin_details (transaction_id);
\ No newline at end of file
+CREATE INDEX spin_details_transaction_idx ON spin_details (transaction_id);
+
+CREATE TABLE IF NOT EXISTS game_rounds
+(
+    "id"             BIGSERIAL NOT NULL PRIMARY KEY,
+    "balance_id"     BIGINT    NOT NULL,
+    "game_round_ref" VARCHAR   NOT NULL,
+    "game_id"        VARCHAR,
+    "status"         VARCHAR   NOT NULL,
+    "total_bet"      BIGINT    NOT NULL DEFAULT 0,
+    "total_win"      BIGINT    NOT NULL DEFAULT 0,
+    "transactions"   INTEGER   NOT NULL DEFAULT 0,
+    "close_reason"   VARCHAR,
+    "opened_at"      TIMESTAMP WITHOUT TIME ZONE NOT NULL,
+    "updated_at"     TIMESTAMP WITHOUT TIME ZONE NOT NULL,
+    "closed_at"      TIMESTAMP WITHOUT TIME ZONE,
+    CONSTRAINT
+        fk_balance FOREIGN KEY (balance_id) REFERENCES balances (id)
+);
+
+CREATE UNIQUE INDEX game_rounds_uniq_idx ON game_rounds (balance_id, game_round_ref);
+CREATE INDEX game_rounds_open_idx ON game_rounds (updated_at) WHERE status = 'open';
+
+CREATE TABLE IF NOT EXISTS free_rounds
+(
+    "id"          BIGSERIAL NOT NULL PRIMARY KEY,
+    "balance_id"  BIGINT    NOT NULL,
+    "bonus_id"    VARCHAR   NOT NULL,
+    "game_ids"    VARCHAR[] NOT NULL DEFAULT '{}',
+    "bet_value"   BIGINT    NOT NULL DEFAULT 0,
+    "rounds"      INTEGER   NOT NULL CHECK (rounds > 0),
+    "rounds_left" INTEGER   NOT NULL CHECK (rounds_left >= 0),
+    "status"      VARCHAR   NOT NULL,
+    "expires_at"  TIMESTAMP WITHOUT TIME ZONE,
+    "created_at"  TIMESTAMP WITHOUT TIME ZONE NOT NULL,
+    "updated_at"  TIMESTAMP WITHOUT TIME ZONE NOT NULL,
+    CONSTRAINT
+        fk_balance FOREIGN KEY (balance_id) REFERENCES balances (id)
+);
+
+CREATE UNIQUE INDEX free_rounds_uniq_idx ON free_rounds (balance_id, bonus_id);
+
+CREATE TABLE IF NOT EXISTS bonuses
+(
+    "id"           BIGSERIAL NOT NULL PRIMARY KEY,
+    "balance_id"   BIGINT    NOT NULL,
+    "bonus_id"     VARCHAR   NOT NULL,
+    "granted"      BIGINT    NOT NULL CHECK (granted > 0),
+    "amount"       BIGINT    NOT NULL,
+    "wagering"     BIGINT    NOT NULL CHECK (wagering > 0),
+    "wagered"      BIGINT    NOT NULL DEFAULT 0,
+    "status"       VARCHAR   NOT NULL,
+    "created_at"   TIMESTAMP WITHOUT TIME ZONE NOT NULL,
+    "updated_at"   TIMESTAMP WITHOUT TIME ZONE NOT NULL,
+    "converted_at" TIMESTAMP WITHOUT TIME ZONE,
+    CONSTRAINT
+        fk_balance FOREIGN KEY (balance_id) REFERENCES balances (id)
+);
+
+CREATE UNIQUE INDEX bonuses_uniq_idx ON bonuses (balance_id, bonus_id);
+
+CREATE TABLE IF NOT EXISTS limits
+(
+    "id"           BIGSERIAL NOT NULL PRIMARY KEY,
+    "balance_id"   BIGINT    NOT NULL,
+    "limit_type"   VARCHAR   NOT NULL,
+    "limit_window" VARCHAR   NOT NULL,
+    "amount"       BIGINT    NOT NULL CHECK (amount >= 0),
+    "created_at"   TIMESTAMP WITHOUT TIME ZONE NOT NULL,
+    "updated_at"   TIMESTAMP WITHOUT TIME ZONE NOT NULL,
+    CONSTRAINT
+        fk_balance FOREIGN KEY (balance_id) REFERENCES balances (id)
+);
+
+CREATE UNIQUE INDEX limits_uniq_idx ON limits (balance_id, limit_type, limit_window);
+
+CREATE TABLE IF NOT EXISTS players
+(
+    "player_name" VARCHAR NOT NULL PRIMARY KEY,
+    "status"      VARCHAR NOT NULL,
+    "reason"      VARCHAR NOT NULL,
+    "until"       TIMESTAMP WITHOUT TIME ZONE,
+    "updated_at"  TIMESTAMP WITHOUT TIME ZONE NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS player_status_history
+(
+    "id"          BIGSERIAL NOT NULL PRIMARY KEY,
+    "player_name" VARCHAR   NOT NULL,
+    "from_status" VARCHAR   NOT NULL,
+    "to_status"   VARCHAR   NOT NULL,
+    "reason"      VARCHAR   NOT NULL,
+    "until"       TIMESTAMP WITHOUT TIME ZONE,
+    "actor"       VARCHAR   NOT NULL,
+    "created_at"  TIMESTAMP WITHOUT TIME ZONE NOT NULL
+);
+
+CREATE INDEX player_status_history_player_idx ON player_status_history (player_name);
+
+CREATE SEQUENCE IF NOT EXISTS ledger_posting_seq;
+
+CREATE TABLE IF NOT EXISTS ledger_entries
+(
+    "id"             BIGSERIAL NOT NULL PRIMARY KEY,
+    "posting_id"     BIGINT    NOT NULL,
+    "balance_id"     BIGINT    NOT NULL,
+    "currency_id"    BIGINT    NOT NULL,
+    "transaction_id" BIGINT,
+    "bonus_id"       BIGINT,
+    "account"        VARCHAR   NOT NULL,
+    "kind"           VARCHAR   NOT NULL,
+    "amount"         BIGINT    NOT NULL,
+    "created_at"     TIMESTAMP WITHOUT TIME ZONE NOT NULL,
+    CONSTRAINT
+        fk_balance FOREIGN KEY (balance_id) REFERENCES balances (id),
+    CONSTRAINT
+        fk_currency FOREIGN KEY (currency_id) REFERENCES currencies (id)
+);
+
+CREATE INDEX ledger_entries_balance_idx ON ledger_entries (balance_id, account);
+CREATE INDEX ledger_entries_bonus_idx ON ledger_entries (bonus_id) WHERE bonus_id IS NOT NULL;
+CREATE INDEX ledger_entries_posting_idx ON ledger_entries (posting_id);
+
+INSERT INTO ledger_entries(posting_id, balance_id, currency_id, account, kind, amount, created_at)
+SELECT nextval('ledger_posting_seq'), id, currency_id, 'cash', 'opening', amount, NOW()
+FROM balances
+WHERE amount <> 0;
+
+INSERT INTO ledger_entries(posting_id, balance_id, currency_id, account, kind, amount, created_at)
+SELECT posting_id, balance_id, currency_id, 'cashier', 'opening', -amount, created_at
+FROM ledger_entries
+WHERE kind = 'opening';
+
+CREATE TABLE IF NOT EXISTS balance_history
+(
+    "id"             BIGSERIAL NOT NULL PRIMARY KEY,
+    "balance_id"     BIGINT    NOT NULL,
+    "transaction_id" BIGINT,
+    "cause"          VARCHAR   NOT NULL,
+    "actor"          VARCHAR   NOT NULL,
+    "amount_before"  BIGINT    NOT NULL,
+    "amount_after"   BIGINT    NOT NULL,
+    "delta"          BIGINT    NOT NULL,
+    "bonus_delta"    BIGINT    NOT NULL,
+    "created_at"     TIMESTAMP WITHOUT TIME ZONE NOT NULL,
+    CONSTRAINT
+        fk_balance FOREIGN KEY (balance_id) REFERENCES balances (id),
+    CONSTRAINT
+        fk_transaction FOREIGN KEY (transaction_id) REFERENCES transactions (id)
+);
+
+CREATE INDEX balance_history_balance_idx ON balance_history (balance_id, created_at);
+
+INSERT INTO balance_history(balance_id, cause, actor, amount_before, amount_after, delta, bonus_delta, created_at)
+SELECT id, 'opening', '', 0, amount, amount, 0, NOW()
+FROM balances
+WHERE amount <> 0;
+
+CREATE TABLE IF NOT EXISTS adjustments
+(
+    "id"          BIGSERIAL NOT NULL PRIMARY KEY,
+    "balance_id"  BIGINT    NOT NULL,
+    "amount"      BIGINT    NOT NULL,
+    "reason_code" VARCHAR   NOT NULL,
+    "comment"     VARCHAR   NOT NULL,
+    "actor"       VARCHAR   NOT NULL,
+    "created_at"  TIMESTAMP WITHOUT TIME ZONE NOT NULL,
+    CONSTRAINT
+        fk_balance FOREIGN KEY (balance_id) REFERENCES balances (id)
+);
+
+CREATE INDEX adjustments_balance_idx ON adjustments (balance_id);
@@ -0,0 +1,191 @@
+package seamless
+
+import (
+	"context"
+	"seamless-api-wrapper/internal/model"
+	"seamless-api-wrapper/internal/rpc"
+	"seamless-api-wrapper/internal/service"
+	"seamless-api-wrapper/internal/validate"
+	"seamless-api-wrapper/package/dto"
+	"strconv"
+)
+
+// Admin serves the back-office methods. It is registered on a server of its
+// own with auth, the authenticated user is the actor of its changes.
+type Admin struct {
+	seamlessService  service.SeamlessService
+	freeRoundService service.FreeRoundService
+	bonusService     service.BonusService
+	limitService     service.LimitService
+	playerService    service.PlayerService
+	ledgerService    service.LedgerService
+	adminService     service.AdminService
+}
+
+func NewAdmin(storage Storage) *Admin {
+	return &Admin{
+		seamlessService:  storage,
+		freeRoundService: storage,
+		bonusService:     storage,
+		limitService:     storage,
+		playerService:    storage,
+		ledgerService:    storage,
+		adminService:     storage,
+	}
+}
+
+// Register adds every admin method to the rpc server.
+func (a *Admin) Register(r Registrar) {
+	r.Register("createWallet", rpc.HandlerWithPointer(a.CreateWallet))
+	r.Register("adjustBalance", rpc.HandlerWithPointer(a.AdjustBalance))
+	r.Register("listAdjustments", rpc.HandlerWithPointer(a.ListAdjustments))
+	r.Register("forceRollback", rpc.HandlerWithPointer(a.ForceRollback))
+	r.Register("getTransaction", rpc.HandlerWithPointer(a.GetTransaction))
+	r.Register("grantFreeRounds", rpc.HandlerWithPointer(a.GrantFreeRounds))
+	r.Register("cancelFreeRounds", rpc.HandlerWithPointer(a.CancelFreeRounds))
+	r.Register("editFreeRounds", rpc.HandlerWithPointer(a.EditFreeRounds))
+	r.Register("grantBonus", rpc.HandlerWithPointer(a.GrantBonus))
+	r.Register("setLimit", rpc.HandlerWithPointer(a.SetLimit))
+	r.Register("removeLimit", rpc.HandlerWithPointer(a.RemoveLimit))
+	r.Register("setPlayerStatus", rpc.HandlerWithPointer(a.SetPlayerStatus))
+	r.Register("getPlayerStatus", rpc.HandlerWithPointer(a.GetPlayerStatus))
+	r.Register("getPlayerStatusHistory", rpc.HandlerWithPointer(a.GetPlayerStatusHistory))
+	r.Register("getLedgerEntries", rpc.HandlerWithPointer(a.GetLedgerEntries))
+	r.Register("getBalanceHistory", rpc.HandlerWithPointer(a.GetBalanceHistory))
+	r.Register("getTransactionHistory", rpc.HandlerWithPointer(a.GetTransactionHistory))
+	r.Register("getErrorCodes", rpc.HandlerWithPointer(a.GetErrorCodes))
+}
+
+func (a *Admin) CreateWallet(ctx context.Context, req *dto.CreateWalletReq) (*dto.Wallet, error) {
+	if err := validate.Req(req); err != nil {
+		return nil, rpc.InvalidParamsError
+	}
+
+	balance, err := a.adminService.CreateWallet(withUser(ctx), req.PlayerName, req.Currency, req.GameId)
+	if err != nil {
+		return nil, rpcError(err, "fail create wallet")
+	}
+
+	resp := walletResp(balance)
+	return &resp, nil
+}
+
+func (a *Admin) AdjustBalance(ctx context.Context, req *dto.AdjustBalanceReq) (*dto.AdjustBalanceResp, error) {
+	if err := validate.Req(req); err != nil {
+		return nil, rpc.InvalidParamsError
+	}
+
+	adjustment := model.Adjustment{
+		Amount:  req.Amount,
+		Reason:  model.AdjustmentReason(req.ReasonCode),
+		Comment: req.Comment,
+	}
+
+	balance, err := a.adminService.Adjust(withUser(ctx), req.PlayerName, req.Currency, &adjustment)
+	if err != nil {
+		return nil, rpcError(err, "fail adjust balance")
+	}
+
+	return &dto.AdjustBalanceResp{
+		AdjustmentId: strconv.Itoa(adjustment.ID),
+		Wallet:       walletResp(balance),
+	}, nil
+}
+
+func (a *Admin) ListAdjustments(ctx context.Context, req *dto.ListAdjustmentsReq) (*dto.ListAdjustmentsResp, error) {
+	if err := validate.Req(req); err != nil {
+		return nil, rpc.InvalidParamsError
+	}
+
+	adjustments, err := a.adminService.Adjustments(ctx, req.PlayerName)
+	if err != nil {
+		return nil, rpcError(err, "fail list adjustments")
+	}
+
+	resp := &dto.ListAdjustmentsResp{Adjustments: make([]dto.Adjustment, 0, len(adjustments))}
+	for _, adjustment := range adjustments {
+		resp.Adjustments = append(resp.Adjustments, dto.Adjustment{
+			AdjustmentId: strconv.Itoa(adjustment.ID),
+			Currency:     adjustment.Currency.Code,
+			Amount:       adjustment.Amount,
+			ReasonCode:   string(adjustment.Reason),
+			Comment:      adjustment.Comment,
+			Actor:        adjustment.Actor,
+			CreatedAt:    adjustment.CreatedAt,
+		})
+	}
+
+	return resp, nil
+}
+
+func (a *Admin) ForceRollback(ctx context.Context, req *dto.ForceRollbackReq) (*dto.Transaction, error) {
+	if err := validate.Req(req); err != nil {
+		return nil, rpc.InvalidParamsError
+	}
+
+	record, err := a.adminService.TransactionByRef(ctx, req.TransactionRef)
+	if err != nil {
+		return nil, rpcError(err, "fail force rollback")
+	}
+
+	err = a.seamlessService.Rollback(withUser(ctx), record.PlayerName, &model.Transaction{TransactionRef: req.TransactionRef})
+	if err != nil {
+		return nil, rpcError(err, "fail force rollback")
+	}
+
+	return a.GetTransaction(ctx, &dto.GetTransactionReq{TransactionRef: req.TransactionRef})
+}
+
+func (a *Admin) GetTransaction(ctx context.Context, req *dto.GetTransactionReq) (*dto.Transaction, error) {
+	if err := validate.Req(req); err != nil {
+		return nil, rpc.InvalidParamsError
+	}
+
+	record, err := a.adminService.TransactionByRef(ctx, req.TransactionRef)
+	if err != nil {
+		return nil, rpcError(err, "fail get transaction")
+	}
+
+	resp := transactionResp(record)
+	return &resp, nil
+}
+
+func (a *Admin) EditFreeRounds(ctx context.Context, req *dto.EditFreeRoundsReq) (*dto.FreeRounds, error) {
+	if err := validate.Req(req); err != nil {
+		return nil, rpc.InvalidParamsError
+	}
+
+	if req.FreeroundsLeft == nil && req.ExpiresAt == nil {
+		return nil, rpc.InvalidParamsError
+	}
+
+	edit := model.FreeRoundEdit{RoundsLeft: req.FreeroundsLeft, ExpiresAt: req.ExpiresAt}
+
+	freeRound, err := a.freeRoundService.EditFreeRounds(withUser(ctx), req.PlayerName, req.Currency, req.BonusId, &edit)
+	if err != nil {
+		return nil, rpcError(err, "fail edit free rounds")
+	}
+
+	resp := freeRoundsResp(freeRound)
+	return &resp, nil
+}
+
+func (a *Admin) GetErrorCodes(_ context.Context, _ *dto.GetErrorCodesReq) (*dto.GetErrorCodesResp, error) {
+	return &dto.GetErrorCodesResp{Errors: errorCodeTable()}, nil
+}
+
+func walletResp(balance *model.Balance) dto.Wallet {
+	return dto.Wallet{
+		PlayerName:   balance.PlayerName,
+		Currency:     balance.Currency.Code,
+		Balance:      total(balance),
+		CashBalance:  balance.Amount,
+		BonusBalance: balance.BonusAmount,
+	}
+}
+
+// withUser makes the authenticated user the actor of the balance changes
+// done with ctx.
+func withUser(ctx context.Context) context.Context {
+	return service.WithActor(ctx, "admin:"+rpc.User(ctx))
+}
@@ -0,0 +1,66 @@
+package seamless
+
+import (
+	"context"
+	"seamless-api-wrapper/internal/model"
+	"seamless-api-wrapper/internal/rpc"
+	"seamless-api-wrapper/internal/validate"
+	"seamless-api-wrapper/package/dto"
+)
+
+func (a *Admin) GrantBonus(ctx context.Context, req *dto.GrantBonusReq) (*dto.Bonus, error) {
+	if err := validate.Req(req); err != nil {
+		return nil, rpc.InvalidParamsError
+	}
+
+	bonus := model.Bonus{
+		BonusID:  req.BonusId,
+		Granted:  req.Amount,
+		Wagering: req.WageringRequirement,
+	}
+
+	err := a.bonusService.GrantBonus(withUser(ctx), req.PlayerName, req.Currency, &bonus)
+	if err != nil {
+		return nil, rpcError(err, "fail grant bonus")
+	}
+
+	resp := bonusResp(&bonus)
+	return &resp, nil
+}
+
+func (s *Seamless) ListBonuses(ctx context.Context, req *dto.ListBonusesReq) (*dto.ListBonusesResp, error) {
+	if err := validate.Req(req); err != nil {
+		return nil, rpc.InvalidParamsError
+	}
+
+	bonuses, err := s.bonusService.Bonuses(ctx, req.PlayerName)
+	if err != nil {
+		return nil, rpcError(err, "fail list bonuses")
+	}
+
+	resp := &dto.ListBonusesResp{Bonuses: make([]dto.Bonus, 0, len(bonuses))}
+	for i := range bonuses {
+		resp.Bonuses = append(resp.Bonuses, bonusResp(&bonuses[i]))
+	}
+
+	return resp, nil
+}
+
+func bonusResp(bonus *model.Bonus) dto.Bonus {
+	return dto.Bonus{
+		BonusId:             bonus.BonusID,
+		Currency:            bonus.Currency.Code,
+		Granted:             bonus.Granted,
+		Amount:              bonus.Amount,
+		WageringRequirement: bonus.Wagering,
+		Wagered:             bonus.Wagered,
+		Status:              string(bonus.Status),
+		CreatedAt:           bonus.CreatedAt,
+		ConvertedAt:         bonus.ConvertedAt,
+	}
+}
+
+// total is the money a player can bet, cash and active bonuses.
+func total(balance *model.Balance) int64 {
+	return balance.Amount + balance.BonusAmount
+}
@@ -0,0 +1,97 @@
+package seamless
+
+import (
+	"errors"
+	log "github.com/sirupsen/logrus"
+	"net/http"
+	"seamless-api-wrapper/internal/rpc"
+	"seamless-api-wrapper/internal/service"
+	"seamless-api-wrapper/package/dto"
+)
+
+type errorCode struct {
+	err        error
+	code       rpc.ErrorCode
+	retryable  bool
+	httpStatus int
+}
+
+// errorCodes maps service errors to the codes clients see. Errors are
+// matched with errors.Is in this order, anything else is an internal error.
+// Every service.Err* needs an entry, otherwise it is reported as retryable.
+var errorCodes = []errorCode{
+	{err: service.ErrNotEnoughMoneyCode, code: dto.CodeNotEnoughMoney, httpStatus: http.StatusPaymentRequired},
+	{err: service.ErrIllegalCurrencyCode, code: dto.CodeIllegalCurrency, httpStatus: http.StatusBadRequest},
+	{err: service.ErrNegativeDepositCode, code: dto.CodeNegativeDeposit, httpStatus: http.StatusBadRequest},
+	{err: service.ErrNegativeWithdrawalCode, code: dto.CodeNegativeWithdrawal, httpStatus: http.StatusBadRequest},
+	{err: service.ErrSpendingBudgetExceeded, code: dto.CodeSpendingBudgetExceeded, httpStatus: http.StatusForbidden},
+	{err: service.ErrTransactionRollback, code: dto.CodeTransactionRollback, httpStatus: http.StatusConflict},
+	{err: service.ErrAmountOverflow, code: dto.CodeAmountOverflow, httpStatus: http.StatusUnprocessableEntity},
+	{err: service.ErrTransactionConflict, code: dto.CodeTransactionConflict, httpStatus: http.StatusConflict},
+	{err: service.ErrRoundNotFound, code: dto.CodeRoundNotFound, httpStatus: http.StatusNotFound},
+	{err: service.ErrFreeRoundsNotFound, code: dto.CodeFreeRoundsNotFound, httpStatus: http.StatusNotFound},
+	{err: service.ErrNotEnoughFreeRounds, code: dto.CodeNotEnoughFreeRounds, httpStatus: http.StatusUnprocessableEntity},
+	{err: service.ErrFreeRoundsExists, code: dto.CodeFreeRoundsExists, httpStatus: http.StatusConflict},
+	{err: service.ErrBonusExists, code: dto.CodeBonusExists, httpStatus: http.StatusConflict},
+	{err: service.ErrPlayerBlocked, code: dto.CodePlayerBlocked, httpStatus: http.StatusForbidden},
+	{err: service.ErrPlayerStatusLocked, code: dto.CodePlayerStatusLocked, httpStatus: http.StatusConflict},
+	{err: service.ErrPlayerNotFound, code: dto.CodePlayerNotFound, httpStatus: http.StatusNotFound},
+	{err: service.ErrWalletExists, code: dto.CodeWalletExists, httpStatus: http.StatusConflict},
+	{err: service.ErrTransactionNotFound, code: dto.CodeTransactionNotFound, httpStatus: http.StatusNotFound},
+	{err: service.ErrWalletNotFound, code: dto.CodeWalletNotFound, httpStatus: http.StatusNotFound},
+	{err: service.ErrForeignTransaction, code: dto.CodeForeignTransaction, httpStatus: http.StatusConflict},
+	{err: service.ErrInvalidTransition, code: dto.CodeInvalidTransition, httpStatus: http.StatusConflict},
+	{err: service.ErrTransactionBusy, code: dto.CodeTransactionBusy, retryable: true, httpStatus: http.StatusConflict},
+	{err: service.ErrSnapshotMissing, code: dto.CodeSnapshotMissing, httpStatus: http.StatusConflict},
+	{err: service.ErrInvalidFreeRound, code: dto.CodeInvalidFreeRound, httpStatus: http.StatusBadRequest},
+	{err: service.ErrInvalidBonus, code: dto.CodeInvalidBonus, httpStatus: http.StatusBadRequest},
+	{err: service.ErrInvalidLimit, code: dto.CodeInvalidLimit, httpStatus: http.StatusBadRequest},
+	{err: service.ErrInvalidPlayerStatus, code: dto.CodeInvalidPlayerStatus, httpStatus: http.StatusBadRequest},
+	{err: service.ErrInvalidAdjustment, code: dto.CodeInvalidAdjustment, httpStatus: http.StatusBadRequest},
+	{err: service.ErrLedgerUnbalanced, code: dto.CodeLedgerUnbalanced, httpStatus: http.StatusInternalServerError},
+	{err: service.ErrLedgerMismatch, code: dto.CodeLedgerMismatch, httpStatus: http.StatusInternalServerError},
+}
+
+// internalError is returned for errors missing from errorCodes, these are
+// storage or network failures a client may retry.
+var internalError = errorCode{code: rpc.ServerErrorCode, retryable: true, httpStatus: http.StatusInternalServerError}
+
+// rpcError converts a service error to its registered rpc error, a limit
+// error carries the limit as data. Unknown errors are logged and reported
+// with the fallback message.
+func rpcError(err error, fallback string) *rpc.Error {
+	for _, c := range errorCodes {
+		if errors.Is(err, c.err) {
+			rpcErr := &rpc.Error{Code: c.code, Message: c.err.Error(), Status: c.httpStatus}
+
+			var limitErr *service.LimitError
+			if errors.As(err, &limitErr) {
+				rpcErr.Data = limitExceeded(limitErr)
+			}
+
+			return rpcErr
+		}
+	}
+
+	log.Error(err)
+	return &rpc.Error{Code: internalError.code, Message: fallback, Status: internalError.httpStatus}
+}
+
+func errorCodeTable() []dto.ErrorCode {
+	table := make([]dto.ErrorCode, 0, len(errorCodes)+1)
+	for _, c := range errorCodes {
+		table = append(table, dto.ErrorCode{
+			Code:       c.code,
+			Message:    c.err.Error(),
+			Retryable:  c.retryable,
+			HttpStatus: c.httpStatus,
+		})
+	}
+
+	return append(table, dto.ErrorCode{
+		Code:       internalError.code,
+		Message:    "internal error",
+		Retryable:  internalError.retryable,
+		HttpStatus: internalError.httpStatus,
+	})
+}
@@ -0,0 +1,77 @@
+package seamless
+
+import (
+	"context"
+	"seamless-api-wrapper/internal/model"
+	"seamless-api-wrapper/internal/rpc"
+	"seamless-api-wrapper/internal/validate"
+	"seamless-api-wrapper/package/dto"
+)
+
+func (a *Admin) GrantFreeRounds(ctx context.Context, req *dto.GrantFreeRoundsReq) (*dto.FreeRounds, error) {
+	if err := validate.Req(req); err != nil {
+		return nil, rpc.InvalidParamsError
+	}
+
+	freeRound := model.FreeRound{
+		BonusID:   req.BonusId,
+		GameIDs:   req.GameIds,
+		BetValue:  req.BetValue,
+		Rounds:    req.Freerounds,
+		ExpiresAt: req.ExpiresAt,
+	}
+
+	err := a.freeRoundService.GrantFreeRounds(withUser(ctx), req.PlayerName, req.Currency, &freeRound)
+	if err != nil {
+		return nil, rpcError(err, "fail grant free rounds")
+	}
+
+	resp := freeRoundsResp(&freeRound)
+	return &resp, nil
+}
+
+func (a *Admin) CancelFreeRounds(ctx context.Context, req *dto.CancelFreeRoundsReq) (*dto.FreeRounds, error) {
+	if err := validate.Req(req); err != nil {
+		return nil, rpc.InvalidParamsError
+	}
+
+	freeRound, err := a.freeRoundService.CancelFreeRounds(withUser(ctx), req.PlayerName, req.Currency, req.BonusId)
+	if err != nil {
+		return nil, rpcError(err, "fail cancel free rounds")
+	}
+
+	resp := freeRoundsResp(freeRound)
+	return &resp, nil
+}
+
+func (s *Seamless) ListFreeRounds(ctx context.Context, req *dto.ListFreeRoundsReq) (*dto.ListFreeRoundsResp, error) {
+	if err := validate.Req(req); err != nil {
+		return nil, rpc.InvalidParamsError
+	}
+
+	freeRounds, err := s.freeRoundService.FreeRounds(ctx, req.PlayerName)
+	if err != nil {
+		return nil, rpcError(err, "fail list free rounds")
+	}
+
+	resp := &dto.ListFreeRoundsResp{FreeRounds: make([]dto.FreeRounds, 0, len(freeRounds))}
+	for i := range freeRounds {
+		resp.FreeRounds = append(resp.FreeRounds, freeRoundsResp(&freeRounds[i]))
+	}
+
+	return resp, nil
+}
+
+func freeRoundsResp(freeRound *model.FreeRound) dto.FreeRounds {
+	return dto.FreeRounds{
+		BonusId:        freeRound.BonusID,
+		Currency:       freeRound.Currency.Code,
+		GameIds:        freeRound.GameIDs,
+		BetValue:       freeRound.BetValue,
+		Freerounds:     freeRound.Rounds,
+		FreeroundsLeft: freeRound.RoundsLeft,
+		Status:         string(freeRound.Status),
+		ExpiresAt:      freeRound.ExpiresAt,
+		CreatedAt:      freeRound.CreatedAt,
+	}
+}
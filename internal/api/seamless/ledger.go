@@ -0,0 +1,71 @@
+package seamless
+
+import (
+	"context"
+	"seamless-api-wrapper/internal/rpc"
+	"seamless-api-wrapper/internal/validate"
+	"seamless-api-wrapper/package/dto"
+	"strconv"
+)
+
+func (a *Admin) GetLedgerEntries(ctx context.Context, req *dto.GetLedgerEntriesReq) (*dto.GetLedgerEntriesResp, error) {
+	if err := validate.Req(req); err != nil {
+		return nil, rpc.InvalidParamsError
+	}
+
+	entries, err := a.ledgerService.LedgerEntries(ctx, req.PlayerName, req.Currency)
+	if err != nil {
+		return nil, rpcError(err, "fail get ledger entries")
+	}
+
+	resp := &dto.GetLedgerEntriesResp{Entries: make([]dto.LedgerEntry, 0, len(entries))}
+	for _, entry := range entries {
+		e := dto.LedgerEntry{
+			PostingId: strconv.Itoa(entry.PostingID),
+			Account:   string(entry.Account),
+			Kind:      string(entry.Kind),
+			Amount:    entry.Amount,
+			CreatedAt: entry.CreatedAt,
+		}
+		if entry.TransactionID != nil {
+			e.TransactionId = strconv.Itoa(*entry.TransactionID)
+		}
+		resp.Entries = append(resp.Entries, e)
+	}
+
+	return resp, nil
+}
+
+func (a *Admin) GetBalanceHistory(ctx context.Context, req *dto.GetBalanceHistoryReq) (*dto.GetBalanceHistoryResp, error) {
+	if err := validate.Req(req); err != nil {
+		return nil, rpc.InvalidParamsError
+	}
+
+	if req.From != nil && req.To != nil && !req.From.Before(*req.To) {
+		return nil, rpc.InvalidParamsError
+	}
+
+	history, err := a.ledgerService.BalanceHistory(ctx, req.PlayerName, req.Currency, req.From, req.To)
+	if err != nil {
+		return nil, rpcError(err, "fail get balance history")
+	}
+
+	resp := &dto.GetBalanceHistoryResp{Changes: make([]dto.BalanceChange, 0, len(history))}
+	for _, change := range history {
+		c := dto.BalanceChange{
+			Cause:         string(change.Cause),
+			Actor:         change.Actor,
+			BalanceBefore: change.AmountBefore,
+			BalanceAfter:  change.AmountAfter,
+			Delta:         change.Delta,
+			BonusDelta:    change.BonusDelta,
+			CreatedAt:     change.CreatedAt,
+		}
+		if change.TransactionID != nil {
+			c.TransactionId = strconv.Itoa(*change.TransactionID)
+		}
+		resp.Changes = append(resp.Changes, c)
+	}
+
+	return resp, nil
+}
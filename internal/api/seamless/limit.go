@@ -0,0 +1,85 @@
+package seamless
+
+import (
+	"context"
+	"seamless-api-wrapper/internal/model"
+	"seamless-api-wrapper/internal/rpc"
+	"seamless-api-wrapper/internal/service"
+	"seamless-api-wrapper/internal/validate"
+	"seamless-api-wrapper/package/dto"
+)
+
+func (a *Admin) SetLimit(ctx context.Context, req *dto.SetLimitReq) (*dto.Limit, error) {
+	if err := validate.Req(req); err != nil {
+		return nil, rpc.InvalidParamsError
+	}
+
+	limit := model.Limit{
+		Type:   model.LimitType(req.Type),
+		Window: model.LimitWindow(req.Window),
+		Amount: req.Amount,
+	}
+
+	if service.CheckLimit(&limit) != nil {
+		return nil, rpc.InvalidParamsError
+	}
+
+	err := a.limitService.SetLimit(withUser(ctx), req.PlayerName, req.Currency, &limit)
+	if err != nil {
+		return nil, rpcError(err, "fail set limit")
+	}
+
+	resp := limitResp(&limit)
+	return &resp, nil
+}
+
+func (a *Admin) RemoveLimit(ctx context.Context, req *dto.RemoveLimitReq) (*Empty, error) {
+	if err := validate.Req(req); err != nil {
+		return nil, rpc.InvalidParamsError
+	}
+
+	err := a.limitService.RemoveLimit(withUser(ctx), req.PlayerName, req.Currency, model.LimitType(req.Type), model.LimitWindow(req.Window))
+	if err != nil {
+		return nil, rpcError(err, "fail remove limit")
+	}
+
+	return &Empty{}, nil
+}
+
+func (s *Seamless) GetLimits(ctx context.Context, req *dto.GetLimitsReq) (*dto.GetLimitsResp, error) {
+	if err := validate.Req(req); err != nil {
+		return nil, rpc.InvalidParamsError
+	}
+
+	limits, err := s.limitService.Limits(ctx, req.PlayerName)
+	if err != nil {
+		return nil, rpcError(err, "fail get limits")
+	}
+
+	resp := &dto.GetLimitsResp{Limits: make([]dto.Limit, 0, len(limits))}
+	for i := range limits {
+		resp.Limits = append(resp.Limits, limitResp(&limits[i]))
+	}
+
+	return resp, nil
+}
+
+func limitResp(limit *model.Limit) dto.Limit {
+	return dto.Limit{
+		Currency:  limit.Currency.Code,
+		Type:      string(limit.Type),
+		Window:    string(limit.Window),
+		Amount:    limit.Amount,
+		UpdatedAt: limit.UpdatedAt,
+	}
+}
+
+func limitExceeded(err *service.LimitError) *dto.LimitExceeded {
+	return &dto.LimitExceeded{
+		Type:     string(err.Limit.Type),
+		Window:   string(err.Limit.Window),
+		Limit:    err.Limit.Amount,
+		Used:     err.Used,
+		ResetsAt: err.ResetsAt,
+	}
+}
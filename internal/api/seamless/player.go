@@ -0,0 +1,90 @@
+package seamless
+
+import (
+	"context"
+	"seamless-api-wrapper/internal/model"
+	"seamless-api-wrapper/internal/rpc"
+	"seamless-api-wrapper/internal/service"
+	"seamless-api-wrapper/internal/validate"
+	"seamless-api-wrapper/package/dto"
+	"time"
+)
+
+func (a *Admin) SetPlayerStatus(ctx context.Context, req *dto.SetPlayerStatusReq) (*dto.PlayerStatus, error) {
+	if err := validate.Req(req); err != nil {
+		return nil, rpc.InvalidParamsError
+	}
+
+	player := model.Player{
+		PlayerName: req.PlayerName,
+		Status:     model.PlayerStatus(req.Status),
+		Reason:     req.Reason,
+		Until:      req.Until,
+	}
+
+	if service.CheckPlayer(&player, time.Now()) != nil {
+		return nil, rpc.InvalidParamsError
+	}
+
+	if err := a.playerService.SetPlayerStatus(withUser(ctx), &player); err != nil {
+		return nil, rpcError(err, "fail set player status")
+	}
+
+	return playerStatusResp(&player, player.UpdatedAt), nil
+}
+
+func (a *Admin) GetPlayerStatus(ctx context.Context, req *dto.GetPlayerStatusReq) (*dto.PlayerStatus, error) {
+	if err := validate.Req(req); err != nil {
+		return nil, rpc.InvalidParamsError
+	}
+
+	player, err := a.playerService.Player(ctx, req.PlayerName)
+	if err != nil {
+		return nil, rpcError(err, "fail get player status")
+	}
+
+	return playerStatusResp(player, time.Now()), nil
+}
+
+func (a *Admin) GetPlayerStatusHistory(ctx context.Context, req *dto.GetPlayerStatusHistoryReq) (*dto.GetPlayerStatusHistoryResp, error) {
+	if err := validate.Req(req); err != nil {
+		return nil, rpc.InvalidParamsError
+	}
+
+	history, err := a.playerService.PlayerStatusHistory(ctx, req.PlayerName)
+	if err != nil {
+		return nil, rpcError(err, "fail get player status history")
+	}
+
+	resp := &dto.GetPlayerStatusHistoryResp{Changes: make([]dto.PlayerStatusChange, 0, len(history))}
+	for _, change := range history {
+		resp.Changes = append(resp.Changes, dto.PlayerStatusChange{
+			FromStatus: string(change.FromStatus),
+			ToStatus:   string(change.ToStatus),
+			Reason:     change.Reason,
+			Until:      change.Until,
+			Actor:      change.Actor,
+			CreatedAt:  change.CreatedAt,
+		})
+	}
+
+	return resp, nil
+}
+
+// playerStatusResp reports the status in force at now, an ended
+// self-exclusion or cool-off is shown as active.
+func playerStatusResp(player *model.Player, now time.Time) *dto.PlayerStatus {
+	resp := &dto.PlayerStatus{PlayerName: player.PlayerName, Status: string(player.Current(now))}
+	if resp.Status == string(model.PlayerActive) && player.Status != model.PlayerActive {
+		return resp
+	}
+
+	resp.Reason = player.Reason
+	resp.Until = player.Until
+	if !player.UpdatedAt.IsZero() {
+		updatedAt := player.UpdatedAt
+		resp.UpdatedAt = &updatedAt
+	}
+
+	return resp
+}
@@ -0,0 +1,56 @@
+package seamless
+
+import (
+	"context"
+	"seamless-api-wrapper/internal/model"
+	"seamless-api-wrapper/internal/rpc"
+	"seamless-api-wrapper/internal/validate"
+	"seamless-api-wrapper/package/dto"
+)
+
+func (s *Seamless) GetRound(ctx context.Context, req *dto.GetRoundReq) (*dto.Round, error) {
+	if err := validate.Req(req); err != nil {
+		return nil, rpc.InvalidParamsError
+	}
+
+	round, err := s.roundService.Round(ctx, req.PlayerName, req.Currency, req.GameRoundRef)
+	if err != nil {
+		return nil, rpcError(err, "fail get round")
+	}
+
+	resp := roundResp(round)
+	return &resp, nil
+}
+
+func (s *Seamless) ListOpenRounds(ctx context.Context, req *dto.ListOpenRoundsReq) (*dto.ListOpenRoundsResp, error) {
+	if err := validate.Req(req); err != nil {
+		return nil, rpc.InvalidParamsError
+	}
+
+	rounds, err := s.roundService.OpenRounds(ctx, req.PlayerName)
+	if err != nil {
+		return nil, rpcError(err, "fail list rounds")
+	}
+
+	resp := &dto.ListOpenRoundsResp{Rounds: make([]dto.Round, 0, len(rounds))}
+	for i := range rounds {
+		resp.Rounds = append(resp.Rounds, roundResp(&rounds[i]))
+	}
+
+	return resp, nil
+}
+
+func roundResp(round *model.Round) dto.Round {
+	return dto.Round{
+		GameRoundRef: round.GameRoundRef,
+		GameId:       round.GameID,
+		Currency:     round.Currency.Code,
+		Status:       string(round.Status),
+		TotalBet:     round.TotalBet,
+		TotalWin:     round.TotalWin,
+		Transactions: round.Transactions,
+		CloseReason:  round.CloseReason,
+		OpenedAt:     round.OpenedAt,
+		ClosedAt:     round.ClosedAt,
+	}
+}
@@ -2,8 +2,6 @@ package seamless
 
 import (
 	"context"
-	"errors"
-	log "github.com/sirupsen/logrus"
 	"seamless-api-wrapper/internal/model"
 	"seamless-api-wrapper/internal/rpc"
 	"seamless-api-wrapper/internal/service"
@@ -13,12 +11,54 @@ import (
 	"time"
 )
 
+// Storage is implemented by every storage driver, the wallet and admin
+// methods are served from one.
+type Storage interface {
+	service.SeamlessService
+	service.RoundService
+	service.FreeRoundService
+	service.BonusService
+	service.LimitService
+	service.PlayerService
+	service.LedgerService
+	service.AdminService
+}
+
 type Seamless struct {
-	seamlessService service.SeamlessService
+	seamlessService  service.SeamlessService
+	roundService     service.RoundService
+	freeRoundService service.FreeRoundService
+	bonusService     service.BonusService
+	limitService     service.LimitService
+}
+
+func NewSeamless(storage Storage) *Seamless {
+	return &Seamless{
+		seamlessService:  storage,
+		roundService:     storage,
+		freeRoundService: storage,
+		bonusService:     storage,
+		limitService:     storage,
+	}
 }
 
-func NewSeamless(seamlessService service.SeamlessService) *Seamless {
-	return &Seamless{seamlessService: seamlessService}
+type Registrar interface {
+	Register(name string, f rpc.HandlerFunc)
+}
+
+// Register adds every wallet method to the rpc server.
+func (s *Seamless) Register(r Registrar) {
+	r.Register("getBalance", rpc.HandlerWithPointer(s.GetBalance))
+	r.Register("withdrawAndDeposit", rpc.HandlerWithPointer(s.WithdrawAndDeposit))
+	r.Register("rollbackTransaction", rpc.HandlerWithPointer(s.RollbackTransaction))
+	r.Register("rollbackRound", rpc.HandlerWithPointer(s.RollbackRound))
+	r.Register("getRound", rpc.HandlerWithPointer(s.GetRound))
+	r.Register("listOpenRounds", rpc.HandlerWithPointer(s.ListOpenRounds))
+	r.Register("listFreeRounds", rpc.HandlerWithPointer(s.ListFreeRounds))
+	r.Register("listBonuses", rpc.HandlerWithPointer(s.ListBonuses))
+	r.Register("getLimits", rpc.HandlerWithPointer(s.GetLimits))
+	r.Register("getErrorCodes", rpc.HandlerWithPointer(s.GetErrorCodes))
+	r.Register("getCurrencies", rpc.HandlerWithPointer(s.GetCurrencies))
 }
 
 func (s *Seamless) GetBalance(ctx context.Context, req *dto.GetBalanceReq) (*dto.GetBalanceResp, error) {
@@ -28,21 +68,19 @@ func (s *Seamless) GetBalance(ctx context.Context, req *dto.GetBalanceReq) (*dto
 
 	balance, err := s.seamlessService.Balance(ctx, req.PlayerName, req.Currency)
 	if err != nil {
-		switch err {
-		case service.ErrNotEnoughMoneyCode:
-			return nil, &rpc.Error{Code: 1, Message: err.Error()}
-		case service.ErrIllegalCurrencyCode:
-			return nil, &rpc.Error{Code: 2, Message: err.Error()}
-		}
-		log.Error(err)
-		return nil, &rpc.Error{Code: rpc.ServerErrorCode, Message: "fail get balance"}
+		return nil, rpcError(err, "fail get balance")
 	}
 
 	resp := new(dto.GetBalanceResp)
 
-	resp.Balance = balance.Amount
+	resp.Balance = total(balance)
 	resp.FreeRoundsLeft = balance.FreeRoundLeft
 
+	if balance.BonusAmount != 0 {
+		resp.CashBalance = &balance.Amount
+		resp.BonusBalance = &balance.BonusAmount
+	}
+
 	return resp, nil
 }
 
@@ -55,6 +93,7 @@ func (s *Seamless) WithdrawAndDeposit(ctx context.Context, req *dto.WithdrawAndD
 		Withdraw:             req.Withdraw,
 		Deposit:              req.Deposit,
 		TransactionRef:       req.TransactionRef,
+		GameID:               req.GameId,
 		GameRoundRef:         req.GameRoundRef,
 		Source:               req.Source,
 		Reason:               req.Reason,
@@ -62,6 +101,7 @@ func (s *Seamless) WithdrawAndDeposit(ctx context.Context, req *dto.WithdrawAndD
 		SessionAlternativeId: req.SessionAlternativeId,
 		BonusId:              req.BonusId,
 		ChargeFreeRounds:     req.ChargeFreeRounds,
+		CloseRound:           req.RoundClosed,
 	}
 
 	if req.SpinDetails != nil {
@@ -71,29 +111,14 @@ func (s *Seamless) WithdrawAndDeposit(ctx context.Context, req *dto.WithdrawAndD
 		}
 	}
 
-	newBalance, err := s.seamlessService.Transaction(ctx, req.PlayerName, req.Currency, &transaction)
+	newBalance, err := s.seamlessService.Transaction(withCaller(ctx, req.CallerId), req.PlayerName, req.Currency, &transaction)
 	if err != nil {
-		switch {
-		case errors.Is(err, service.ErrNotEnoughMoneyCode):
-			return nil, &rpc.Error{Code: 1, Message: err.Error()}
-		case errors.Is(err, service.ErrIllegalCurrencyCode):
-			return nil, &rpc.Error{Code: 2, Message: err.Error()}
-		case errors.Is(err, service.ErrNegativeDepositCode):
-			return nil, &rpc.Error{Code: 3, Message: err.Error()}
-		case errors.Is(err, service.ErrNegativeWithdrawalCode):
-			return nil, &rpc.Error{Code: 4, Message: err.Error()}
-		case errors.Is(err, service.ErrSpendingBudgetExceeded):
-			return nil, &rpc.Error{Code: 5, Message: err.Error()}
-		case errors.Is(err, service.ErrTransactionRollback):
-			return nil, &rpc.Error{Code: 6, Message: err.Error()}
-		}
-		log.Error(err)
-		return nil, &rpc.Error{Code: rpc.ServerErrorCode, Message: "fail transaction"}
+		return nil, rpcError(err, "fail transaction")
 	}
 
 	resp := new(dto.WithdrawAndDepositResp)
 
-	resp.NewBalance = newBalance.Amount
+	resp.NewBalance = total(newBalance)
 	resp.TransactionId = strconv.Itoa(transaction.ID)
 	resp.FreeRoundsLeft = newBalance.FreeRoundLeft
 
@@ -114,16 +139,55 @@ func (s *Seamless) RollbackTransaction(ctx context.Context, req *dto.RollbackTra
 		GameRoundRef:         req.GameRoundRef,
 		SessionId:            req.SessionId,
 		SessionAlternativeId: req.SessionAlternativeId,
-		IsRollback:           true,
 		CreatedAt:            now,
 		UpdatedAt:            now,
 	}
 
-	err := s.seamlessService.Rollback(ctx, req.PlayerName, &transactions)
+	err := s.seamlessService.Rollback(withCaller(ctx, req.CallerId), req.PlayerName, &transactions)
 	if err != nil {
-		log.Error(err)
-		return nil, &rpc.Error{Code: rpc.ServerErrorCode, Message: "fail rollback"}
+		return nil, rpcError(err, "fail rollback")
 	}
 
 	return &Empty{}, nil
 }
+
+func (s *Seamless) RollbackRound(ctx context.Context, req *dto.RollbackRoundReq) (*dto.RollbackRoundResp, error) {
+	if err := validate.Req(req); err != nil {
+		return nil, rpc.InvalidParamsError
+	}
+
+	balance, err := s.seamlessService.RollbackRound(withCaller(ctx, req.CallerId), req.PlayerName, req.Currency, req.GameRoundRef)
+	if err != nil {
+		return nil, rpcError(err, "fail rollback round")
+	}
+
+	resp := new(dto.RollbackRoundResp)
+
+	resp.NewBalance = total(balance)
+	resp.FreeRoundsLeft = balance.FreeRoundLeft
+
+	return resp, nil
+}
+
+func (s *Seamless) GetErrorCodes(_ context.Context, _ *dto.GetErrorCodesReq) (*dto.GetErrorCodesResp, error) {
+	return &dto.GetErrorCodesResp{Errors: errorCodeTable()}, nil
+}
+
+func (s *Seamless) GetCurrencies(ctx context.Context, _ *dto.GetCurrenciesReq) (*dto.GetCurrenciesResp, error) {
+	currencies, err := s.seamlessService.Currencies(ctx)
+	if err != nil {
+		return nil, rpcError(err, "fail get currencies")
+	}
+
+	resp := &dto.GetCurrenciesResp{Currencies: make([]dto.Currency, 0, len(currencies))}
+	for _, currency := range currencies {
+		resp.Currencies = append(resp.Currencies, dto.Currency{Code: currency.Code, Exponent: currency.Exponent})
+	}
+
+	return resp, nil
+}
+
+// withCaller makes the caller the actor of the balance changes done with ctx.
+func withCaller(ctx context.Context, callerId int) context.Context {
+	return service.WithActor(ctx, "caller:"+strconv.Itoa(callerId))
+}
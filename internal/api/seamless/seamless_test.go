@@ -0,0 +1,680 @@
+package seamless
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"seamless-api-wrapper/internal/memory"
+	"seamless-api-wrapper/internal/rpc"
+	"seamless-api-wrapper/package/dto"
+	"strings"
+	"testing"
+	"time"
+)
+
+type testTransport struct{}
+
+func (t *testTransport) Run(_ context.Context, _ rpc.Resolver) error {
+	return nil
+}
+
+type apiTest struct {
+	t      *testing.T
+	wallet *memory.SeamlessService
+	server rpc.Resolver
+	admin  rpc.Resolver
+}
+
+func newApiTest(t *testing.T) *apiTest {
+	wallet := memory.NewSeamlessService()
+
+	api := NewSeamless(wallet)
+
+	rpcServer := rpc.NewServer(&testTransport{})
+	api.Register(rpcServer)
+
+	adminServer := rpc.NewServer(&testTransport{})
+	NewAdmin(wallet).Register(adminServer)
+
+	return &apiTest{t: t, wallet: wallet, server: rpcServer, admin: adminServer}
+}
+
+func (a *apiTest) send(req, expected string) {
+	a.t.Helper()
+
+	if out := a.resolve(req); out != expected {
+		a.t.Errorf("got %q, expected %q", out, expected)
+	}
+}
+
+func (a *apiTest) resolve(req string) string {
+	out := bytes.NewBuffer([]byte{})
+	a.server.Resolve(context.Background(), out, bytes.NewReader([]byte(req)))
+	return out.String()
+}
+
+// adminSend and adminResolve call the admin server as the user alice.
+func (a *apiTest) adminSend(req, expected string) {
+	a.t.Helper()
+
+	if out := a.adminResolve(req); out != expected {
+		a.t.Errorf("got %q, expected %q", out, expected)
+	}
+}
+
+func (a *apiTest) adminResolve(req string) string {
+	out := bytes.NewBuffer([]byte{})
+	a.admin.Resolve(rpc.WithUser(context.Background(), "alice"), out, bytes.NewReader([]byte(req)))
+	return out.String()
+}
+
+func TestSeamless(t *testing.T) {
+	a := newApiTest(t)
+	a.wallet.AddBalance("player1", "EUR", 10000, nil)
+
+	a.send(`{"jsonrpc":"2.0","method":"getBalance","params":{"callerId":1,"playerName":"player1","currency":"EUR","gameId":"riot"},"id":0}`,
+		`{"jsonrpc":"2.0","result":{"balance":10000},"id":0}`)
+
+	a.send(`{"jsonrpc":"2.0","method":"withdrawAndDeposit","params":{"callerId":1,"playerName":"player1","withdraw":400,"deposit":200,"currency":"EUR","transactionRef":"1:UOwGgNHPgq3OkqRE","gameRoundRef":"1wawxl:39","gameId":"riot","reason":"GAME_PLAY_FINAL","sessionId":"qx9sgvvpihtrlug","spinDetails":{"betType":"spin","winType":"standart"}},"id":0}`,
+		`{"jsonrpc":"2.0","result":{"newBalance":9800,"transactionId":"1"},"id":0}`)
+
+	a.send(`{"jsonrpc":"2.0","method":"rollbackTransaction","params":{"callerId":1,"playerName":"player1","transactionRef":"1:UOwGgNHPgq3OkqRE","gameId":"riot","sessionId":"qx9sgvvpihtrlug","gameRoundRef":"1wawxl:39"},"id":0}`,
+		`{"jsonrpc":"2.0","result":{},"id":0}`)
+
+	a.send(`{"jsonrpc":"2.0","method":"getBalance","params":{"callerId":1,"playerName":"player1","currency":"EUR","gameId":"riot"},"id":0}`,
+		`{"jsonrpc":"2.0","result":{"balance":10000},"id":0}`)
+}
+
+func TestSeamlessRollback(t *testing.T) {
+	a := newApiTest(t)
+	a.wallet.AddBalance("player2", "EUR", 20000, nil)
+
+	a.send(`{"jsonrpc":"2.0","method":"rollbackTransaction","params":{"callerId":1,"playerName":"player2","transactionRef":"2:UOwGgNHPgq3OkqRE","gameId":"riot","sessionId":"qx9sgvvpihtrlug","gameRoundRef":"1wawxl:39"},"id":0}`,
+		`{"jsonrpc":"2.0","result":{},"id":0}`)
+
+	a.send(`{"jsonrpc":"2.0","method":"withdrawAndDeposit","params":{"callerId":1,"playerName":"player2","withdraw":100,"deposit":50,"currency":"EUR","transactionRef":"2:UOwGgNHPgq3OkqRE","gameRoundRef":"1wawxl:39","gameId":"riot","reason":"GAME_PLAY_FINAL","sessionId":"qx9sgvvpihtrlug","spinDetails":{"betType":"spin","winType":"standart"}},"id":0}`,
+		`{"jsonrpc":"2.0","error":{"code":6,"message":"ErrTransactionRollback"},"id":0}`)
+
+	a.send(`{"jsonrpc":"2.0","method":"getBalance","params":{"callerId":1,"playerName":"player2","currency":"EUR","gameId":"riot"},"id":0}`,
+		`{"jsonrpc":"2.0","result":{"balance":20000},"id":0}`)
+}
+
+func TestSeamlessTransactions(t *testing.T) {
+	a := newApiTest(t)
+	a.wallet.AddBalance("player3", "EUR", 200, nil)
+
+	transactionReq := `{"jsonrpc":"2.0","method":"withdrawAndDeposit","params":{"callerId":1,"playerName":"player3","withdraw":100,"deposit":50,"currency":"EUR","transactionRef":"3:UOwGgNHPgq3OkqRE","gameRoundRef":"1wawxl:39","gameId":"riot","reason":"GAME_PLAY_FINAL","sessionId":"qx9sgvvpihtrlug","spinDetails":{"betType":"spin","winType":"standart"}},"id":0}`
+	a.send(transactionReq, `{"jsonrpc":"2.0","result":{"newBalance":150,"transactionId":"1"},"id":0}`)
+	a.send(transactionReq, `{"jsonrpc":"2.0","result":{"newBalance":150,"transactionId":"1"},"id":0}`)
+
+	a.send(`{"jsonrpc":"2.0","method":"getBalance","params":{"callerId":1,"playerName":"player3","currency":"EUR","gameId":"riot"},"id":0}`,
+		`{"jsonrpc":"2.0","result":{"balance":150},"id":0}`)
+
+	a.send(`{"jsonrpc":"2.0","method":"withdrawAndDeposit","params":{"callerId":1,"playerName":"player3","withdraw":1000,"deposit":50,"currency":"EUR","transactionRef":"4:UOwGgNHPgq3OkqRE"},"id":0}`,
+		`{"jsonrpc":"2.0","error":{"code":5,"message":"ErrSpendingBudgetExceeded"},"id":0}`)
+
+	a.send(`{"jsonrpc":"2.0","method":"withdrawAndDeposit","params":{"callerId":1,"playerName":"player3","withdraw":10,"deposit":50,"currency":"USD","transactionRef":"5:UOwGgNHPgq3OkqRE"},"id":0}`,
+		`{"jsonrpc":"2.0","error":{"code":2,"message":"ErrIllegalCurrencyCode"},"id":0}`)
+}
+
+func TestSeamlessRetry(t *testing.T) {
+	a := newApiTest(t)
+	a.wallet.AddBalance("player7", "EUR", 1000, nil)
+	a.adminResolve(`{"jsonrpc":"2.0","method":"grantFreeRounds","params":{"playerName":"player7","currency":"EUR","bonusId":"b7","betValue":10,"freerounds":3},"id":0}`)
+
+	betReq := `{"jsonrpc":"2.0","method":"withdrawAndDeposit","params":{"callerId":1,"playerName":"player7","withdraw":300,"deposit":0,"currency":"EUR","transactionRef":"9:UOwGgNHPgq3OkqRE","bonusId":"b7","chargeFreerounds":1},"id":0}`
+	betResp := `{"jsonrpc":"2.0","result":{"newBalance":700,"transactionId":"1","freeroundsLeft":2},"id":0}`
+	a.send(betReq, betResp)
+
+	a.send(`{"jsonrpc":"2.0","method":"withdrawAndDeposit","params":{"callerId":1,"playerName":"player7","withdraw":200,"deposit":0,"currency":"EUR","transactionRef":"10:UOwGgNHPgq3OkqRE","bonusId":"b7","chargeFreerounds":1},"id":0}`,
+		`{"jsonrpc":"2.0","result":{"newBalance":500,"transactionId":"2","freeroundsLeft":1},"id":0}`)
+
+	a.send(betReq, betResp)
+
+	a.send(`{"jsonrpc":"2.0","method":"withdrawAndDeposit","params":{"callerId":1,"playerName":"player7","withdraw":301,"deposit":0,"currency":"EUR","transactionRef":"9:UOwGgNHPgq3OkqRE","bonusId":"b7","chargeFreerounds":1},"id":0}`,
+		`{"jsonrpc":"2.0","error":{"code":8,"message":"ErrTransactionConflict"},"id":0}`)
+
+	a.send(`{"jsonrpc":"2.0","method":"getBalance","params":{"callerId":1,"playerName":"player7","currency":"EUR"},"id":0}`,
+		`{"jsonrpc":"2.0","result":{"balance":500,"freeroundsLeft":1},"id":0}`)
+}
+
+func TestSeamlessRollbackRound(t *testing.T) {
+	a := newApiTest(t)
+	a.wallet.AddBalance("player8", "EUR", 1000, nil)
+
+	a.send(`{"jsonrpc":"2.0","method":"withdrawAndDeposit","params":{"callerId":1,"playerName":"player8","withdraw":300,"deposit":0,"currency":"EUR","transactionRef":"11:UOwGgNHPgq3OkqRE","gameRoundRef":"2wawxl:40"},"id":0}`,
+		`{"jsonrpc":"2.0","result":{"newBalance":700,"transactionId":"1"},"id":0}`)
+
+	a.send(`{"jsonrpc":"2.0","method":"withdrawAndDeposit","params":{"callerId":1,"playerName":"player8","withdraw":0,"deposit":100,"currency":"EUR","transactionRef":"12:UOwGgNHPgq3OkqRE","gameRoundRef":"2wawxl:40"},"id":0}`,
+		`{"jsonrpc":"2.0","result":{"newBalance":800,"transactionId":"2"},"id":0}`)
+
+	a.send(`{"jsonrpc":"2.0","method":"rollbackRound","params":{"callerId":1,"playerName":"player8","currency":"EUR","gameRoundRef":"2wawxl:40","gameId":"riot"},"id":0}`,
+		`{"jsonrpc":"2.0","result":{"newBalance":1000},"id":0}`)
+
+	a.send(`{"jsonrpc":"2.0","method":"rollbackRound","params":{"callerId":1,"playerName":"player8","currency":"EUR"},"id":0}`,
+		`{"jsonrpc":"2.0","error":{"code":-32602,"message":"invalid method parameters"},"id":0}`)
+}
+
+func TestSeamlessRounds(t *testing.T) {
+	a := newApiTest(t)
+	a.wallet.AddBalance("player9", "EUR", 1000, nil)
+
+	a.send(`{"jsonrpc":"2.0","method":"withdrawAndDeposit","params":{"callerId":1,"playerName":"player9","withdraw":300,"deposit":0,"currency":"EUR","transactionRef":"13:UOwGgNHPgq3OkqRE","gameRoundRef":"3wawxl:41","gameId":"riot"},"id":0}`,
+		`{"jsonrpc":"2.0","result":{"newBalance":700,"transactionId":"1"},"id":0}`)
+
+	var open struct {
+		Result dto.ListOpenRoundsResp `json:"result"`
+	}
+	out := a.resolve(`{"jsonrpc":"2.0","method":"listOpenRounds","params":{"callerId":1,"playerName":"player9"},"id":0}`)
+	if err := json.Unmarshal([]byte(out), &open); err != nil || len(open.Result.Rounds) != 1 {
+		t.Fatalf("unexpected open rounds %s", out)
+	}
+	if r := open.Result.Rounds[0]; r.GameRoundRef != "3wawxl:41" || r.Status != "open" || r.TotalBet != 300 || *r.GameId != "riot" {
+		t.Errorf("unexpected round %+v", r)
+	}
+
+	a.send(`{"jsonrpc":"2.0","method":"withdrawAndDeposit","params":{"callerId":1,"playerName":"player9","withdraw":0,"deposit":500,"currency":"EUR","transactionRef":"14:UOwGgNHPgq3OkqRE","gameRoundRef":"3wawxl:41","roundClosed":true},"id":0}`,
+		`{"jsonrpc":"2.0","result":{"newBalance":1200,"transactionId":"2"},"id":0}`)
+
+	var round struct {
+		Result dto.Round `json:"result"`
+	}
+	out = a.resolve(`{"jsonrpc":"2.0","method":"getRound","params":{"callerId":1,"playerName":"player9","currency":"EUR","gameRoundRef":"3wawxl:41"},"id":0}`)
+	if err := json.Unmarshal([]byte(out), &round); err != nil {
+		t.Fatal(err)
+	}
+	if r := round.Result; r.Status != "closed" || r.TotalBet != 300 || r.TotalWin != 500 || r.Transactions != 2 || r.ClosedAt == nil {
+		t.Errorf("unexpected round %s", out)
+	}
+
+	a.send(`{"jsonrpc":"2.0","method":"listOpenRounds","params":{"callerId":1,"playerName":"player9"},"id":0}`,
+		`{"jsonrpc":"2.0","result":{"rounds":[]},"id":0}`)
+
+	a.send(`{"jsonrpc":"2.0","method":"getRound","params":{"callerId":1,"playerName":"player9","currency":"EUR","gameRoundRef":"unknown"},"id":0}`,
+		`{"jsonrpc":"2.0","error":{"code":9,"message":"ErrRoundNotFound"},"id":0}`)
+}
+
+func TestSeamlessFreeRounds(t *testing.T) {
+	a := newApiTest(t)
+	a.wallet.AddBalance("player10", "EUR", 1000, nil)
+
+	var granted struct {
+		Result dto.FreeRounds `json:"result"`
+	}
+	out := a.adminResolve(`{"jsonrpc":"2.0","method":"grantFreeRounds","params":{"playerName":"player10","currency":"EUR","bonusId":"b10","gameIds":["riot"],"betValue":20,"freerounds":5},"id":0}`)
+	if err := json.Unmarshal([]byte(out), &granted); err != nil {
+		t.Fatal(err)
+	}
+	if r := granted.Result; r.BonusId != "b10" || r.Status != "active" || r.FreeroundsLeft != 5 || r.BetValue != 20 || len(r.GameIds) != 1 {
+		t.Errorf("unexpected free rounds %s", out)
+	}
+
+	a.adminSend(`{"jsonrpc":"2.0","method":"grantFreeRounds","params":{"playerName":"player10","currency":"EUR","bonusId":"b10","freerounds":1},"id":0}`,
+		`{"jsonrpc":"2.0","error":{"code":12,"message":"ErrFreeRoundsExists"},"id":0}`)
+
+	a.adminSend(`{"jsonrpc":"2.0","method":"grantFreeRounds","params":{"playerName":"player10","currency":"EUR","bonusId":"b11","freerounds":0},"id":0}`,
+		`{"jsonrpc":"2.0","error":{"code":-32602,"message":"invalid method parameters"},"id":0}`)
+
+	// Free rounds are granted on the admin server only.
+	a.send(`{"jsonrpc":"2.0","method":"grantFreeRounds","params":{"callerId":1,"playerName":"player10","currency":"EUR","bonusId":"b11","freerounds":1},"id":0}`,
+		`{"jsonrpc":"2.0","error":{"code":-32601,"message":"The method does not exist."},"id":0}`)
+
+	a.send(`{"jsonrpc":"2.0","method":"withdrawAndDeposit","params":{"callerId":1,"playerName":"player10","withdraw":0,"deposit":40,"currency":"EUR","transactionRef":"15:UOwGgNHPgq3OkqRE","gameId":"riot","bonusId":"b10","chargeFreerounds":2},"id":0}`,
+		`{"jsonrpc":"2.0","result":{"newBalance":1040,"transactionId":"1","freeroundsLeft":3},"id":0}`)
+
+	a.send(`{"jsonrpc":"2.0","method":"withdrawAndDeposit","params":{"callerId":1,"playerName":"player10","withdraw":0,"deposit":0,"currency":"EUR","transactionRef":"16:UOwGgNHPgq3OkqRE","gameId":"riot","bonusId":"b10","chargeFreerounds":4},"id":0}`,
+		`{"jsonrpc":"2.0","error":{"code":11,"message":"ErrNotEnoughFreeRounds"},"id":0}`)
+
+	a.send(`{"jsonrpc":"2.0","method":"withdrawAndDeposit","params":{"callerId":1,"playerName":"player10","withdraw":0,"deposit":0,"currency":"EUR","transactionRef":"17:UOwGgNHPgq3OkqRE","gameId":"riot","chargeFreerounds":1},"id":0}`,
+		`{"jsonrpc":"2.0","error":{"code":-32602,"message":"invalid method parameters"},"id":0}`)
+
+	var cancelled struct {
+		Result dto.FreeRounds `json:"result"`
+	}
+	out = a.adminResolve(`{"jsonrpc":"2.0","method":"cancelFreeRounds","params":{"playerName":"player10","currency":"EUR","bonusId":"b10"},"id":0}`)
+	if err := json.Unmarshal([]byte(out), &cancelled); err != nil {
+		t.Fatal(err)
+	}
+	if r := cancelled.Result; r.Status != "cancelled" || r.FreeroundsLeft != 3 {
+		t.Errorf("unexpected free rounds %s", out)
+	}
+
+	var list struct {
+		Result dto.ListFreeRoundsResp `json:"result"`
+	}
+	out = a.resolve(`{"jsonrpc":"2.0","method":"listFreeRounds","params":{"callerId":1,"playerName":"player10"},"id":0}`)
+	if err := json.Unmarshal([]byte(out), &list); err != nil || len(list.Result.FreeRounds) != 1 {
+		t.Fatalf("unexpected free rounds %s", out)
+	}
+
+	a.send(`{"jsonrpc":"2.0","method":"getBalance","params":{"callerId":1,"playerName":"player10","currency":"EUR"},"id":0}`,
+		`{"jsonrpc":"2.0","result":{"balance":1040},"id":0}`)
+}
+
+func TestSeamlessBonus(t *testing.T) {
+	a := newApiTest(t)
+	a.wallet.AddBalance("player11", "EUR", 100, nil)
+
+	var granted struct {
+		Result dto.Bonus `json:"result"`
+	}
+	out := a.adminResolve(`{"jsonrpc":"2.0","method":"grantBonus","params":{"playerName":"player11","currency":"EUR","bonusId":"d11","amount":500,"wageringRequirement":1000},"id":0}`)
+	if err := json.Unmarshal([]byte(out), &granted); err != nil {
+		t.Fatal(err)
+	}
+	if r := granted.Result; r.BonusId != "d11" || r.Status != "active" || r.Amount != 500 || r.WageringRequirement != 1000 {
+		t.Errorf("unexpected bonus %s", out)
+	}
+
+	a.adminSend(`{"jsonrpc":"2.0","method":"grantBonus","params":{"playerName":"player11","currency":"EUR","bonusId":"d11","amount":500,"wageringRequirement":1000},"id":0}`,
+		`{"jsonrpc":"2.0","error":{"code":13,"message":"ErrBonusExists"},"id":0}`)
+	a.send(`{"jsonrpc":"2.0","method":"grantBonus","params":{"callerId":1,"playerName":"player11","currency":"EUR","bonusId":"d12","amount":500,"wageringRequirement":1000},"id":0}`,
+		`{"jsonrpc":"2.0","error":{"code":-32601,"message":"The method does not exist."},"id":0}`)
+
+	a.send(`{"jsonrpc":"2.0","method":"getBalance","params":{"callerId":1,"playerName":"player11","currency":"EUR"},"id":0}`,
+		`{"jsonrpc":"2.0","result":{"balance":600,"cashBalance":100,"bonusBalance":500},"id":0}`)
+
+	a.send(`{"jsonrpc":"2.0","method":"withdrawAndDeposit","params":{"callerId":1,"playerName":"player11","withdraw":300,"deposit":0,"currency":"EUR","transactionRef":"18:UOwGgNHPgq3OkqRE","bonusId":"d11"},"id":0}`,
+		`{"jsonrpc":"2.0","result":{"newBalance":300,"transactionId":"1"},"id":0}`)
+
+	a.send(`{"jsonrpc":"2.0","method":"getBalance","params":{"callerId":1,"playerName":"player11","currency":"EUR"},"id":0}`,
+		`{"jsonrpc":"2.0","result":{"balance":300,"cashBalance":0,"bonusBalance":300},"id":0}`)
+
+	var list struct {
+		Result dto.ListBonusesResp `json:"result"`
+	}
+	out = a.resolve(`{"jsonrpc":"2.0","method":"listBonuses","params":{"callerId":1,"playerName":"player11"},"id":0}`)
+	if err := json.Unmarshal([]byte(out), &list); err != nil || len(list.Result.Bonuses) != 1 || list.Result.Bonuses[0].Wagered != 300 {
+		t.Fatalf("unexpected bonuses %s", out)
+	}
+}
+
+func TestSeamlessLimits(t *testing.T) {
+	a := newApiTest(t)
+	a.wallet.AddBalance("player12", "EUR", 1000, nil)
+
+	a.adminSend(`{"jsonrpc":"2.0","method":"setLimit","params":{"playerName":"player12","currency":"EUR","type":"loss","window":"session","amount":100},"id":0}`,
+		`{"jsonrpc":"2.0","error":{"code":-32602,"message":"invalid method parameters"},"id":0}`)
+
+	out := a.adminResolve(`{"jsonrpc":"2.0","method":"setLimit","params":{"playerName":"player12","currency":"EUR","type":"loss","window":"daily","amount":100},"id":0}`)
+	if !strings.Contains(out, `"type":"loss","window":"daily","amount":100`) {
+		t.Errorf("unexpected limit %s", out)
+	}
+
+	a.send(`{"jsonrpc":"2.0","method":"withdrawAndDeposit","params":{"callerId":1,"playerName":"player12","withdraw":80,"deposit":0,"currency":"EUR","transactionRef":"19:UOwGgNHPgq3OkqRE"},"id":0}`,
+		`{"jsonrpc":"2.0","result":{"newBalance":920,"transactionId":"1"},"id":0}`)
+
+	var failed struct {
+		Error struct {
+			Code    int               `json:"code"`
+			Message string            `json:"message"`
+			Data    dto.LimitExceeded `json:"data"`
+		} `json:"error"`
+	}
+	out = a.resolve(`{"jsonrpc":"2.0","method":"withdrawAndDeposit","params":{"callerId":1,"playerName":"player12","withdraw":30,"deposit":0,"currency":"EUR","transactionRef":"20:UOwGgNHPgq3OkqRE"},"id":0}`)
+	if err := json.Unmarshal([]byte(out), &failed); err != nil {
+		t.Fatal(err)
+	}
+	if e := failed.Error; e.Code != 5 || e.Message != "ErrSpendingBudgetExceeded" || e.Data.Type != "loss" || e.Data.Window != "daily" ||
+		e.Data.Limit != 100 || e.Data.Used != 80 || e.Data.ResetsAt == nil || !e.Data.ResetsAt.After(time.Now()) {
+		t.Errorf("unexpected error %s", out)
+	}
+
+	// A deposit limit counts manual deposits, not wins.
+	a.adminResolve(`{"jsonrpc":"2.0","method":"setLimit","params":{"playerName":"player12","currency":"EUR","type":"deposit","window":"daily","amount":100},"id":0}`)
+	a.send(`{"jsonrpc":"2.0","method":"withdrawAndDeposit","params":{"callerId":1,"playerName":"player12","withdraw":0,"deposit":500,"currency":"EUR","transactionRef":"21:UOwGgNHPgq3OkqRE"},"id":0}`,
+		`{"jsonrpc":"2.0","result":{"newBalance":1420,"transactionId":"3"},"id":0}`)
+	out = a.adminResolve(`{"jsonrpc":"2.0","method":"adjustBalance","params":{"playerName":"player12","currency":"EUR","amount":150,"reasonCode":"manual_deposit"},"id":0}`)
+	if !strings.Contains(out, `"code":5,"message":"ErrSpendingBudgetExceeded","data":{"type":"deposit","window":"daily","limit":100,"used":0`) {
+		t.Errorf("unexpected deposit %s", out)
+	}
+
+	a.send(`{"jsonrpc":"2.0","method":"setLimit","params":{"callerId":1,"playerName":"player12","currency":"EUR","type":"loss","window":"daily","amount":100},"id":0}`,
+		`{"jsonrpc":"2.0","error":{"code":-32601,"message":"The method does not exist."},"id":0}`)
+
+	a.adminSend(`{"jsonrpc":"2.0","method":"removeLimit","params":{"playerName":"player12","currency":"EUR","type":"loss","window":"daily"},"id":0}`,
+		`{"jsonrpc":"2.0","result":{},"id":0}`)
+	a.adminSend(`{"jsonrpc":"2.0","method":"removeLimit","params":{"playerName":"player12","currency":"EUR","type":"deposit","window":"daily"},"id":0}`,
+		`{"jsonrpc":"2.0","result":{},"id":0}`)
+
+	a.send(`{"jsonrpc":"2.0","method":"getLimits","params":{"callerId":1,"playerName":"player12"},"id":0}`,
+		`{"jsonrpc":"2.0","result":{"limits":[]},"id":0}`)
+}
+
+func TestSeamlessPlayerStatus(t *testing.T) {
+	a := newApiTest(t)
+	a.wallet.AddBalance("player13", "EUR", 1000, nil)
+
+	a.adminSend(`{"jsonrpc":"2.0","method":"setPlayerStatus","params":{"playerName":"player13","status":"cool_off","reason":"player request"},"id":0}`,
+		`{"jsonrpc":"2.0","error":{"code":-32602,"message":"invalid method parameters"},"id":0}`)
+
+	a.adminSend(`{"jsonrpc":"2.0","method":"setPlayerStatus","params":{"playerName":"player0","status":"blocked","reason":"fraud"},"id":0}`,
+		`{"jsonrpc":"2.0","error":{"code":16,"message":"ErrPlayerNotFound"},"id":0}`)
+
+	out := a.adminResolve(`{"jsonrpc":"2.0","method":"setPlayerStatus","params":{"playerName":"player13","status":"blocked","reason":"fraud"},"id":0}`)
+	if !strings.Contains(out, `"playerName":"player13","status":"blocked","reason":"fraud"`) {
+		t.Errorf("unexpected status %s", out)
+	}
+
+	a.send(`{"jsonrpc":"2.0","method":"withdrawAndDeposit","params":{"callerId":1,"playerName":"player13","withdraw":100,"deposit":0,"currency":"EUR","transactionRef":"21:UOwGgNHPgq3OkqRE"},"id":0}`,
+		`{"jsonrpc":"2.0","error":{"code":14,"message":"ErrPlayerBlocked"},"id":0}`)
+
+	a.send(`{"jsonrpc":"2.0","method":"withdrawAndDeposit","params":{"callerId":1,"playerName":"player13","withdraw":0,"deposit":50,"currency":"EUR","transactionRef":"22:UOwGgNHPgq3OkqRE"},"id":0}`,
+		`{"jsonrpc":"2.0","result":{"newBalance":1050,"transactionId":"2"},"id":0}`)
+
+	out = a.adminResolve(`{"jsonrpc":"2.0","method":"setPlayerStatus","params":{"playerName":"player13","status":"active","reason":"checked"},"id":0}`)
+	if !strings.Contains(out, `"playerName":"player13","status":"active","reason":"checked"`) {
+		t.Errorf("unexpected status %s", out)
+	}
+
+	a.send(`{"jsonrpc":"2.0","method":"withdrawAndDeposit","params":{"callerId":1,"playerName":"player13","withdraw":100,"deposit":0,"currency":"EUR","transactionRef":"21:UOwGgNHPgq3OkqRE"},"id":0}`,
+		`{"jsonrpc":"2.0","result":{"newBalance":950,"transactionId":"1"},"id":0}`)
+
+	var history struct {
+		Result dto.GetPlayerStatusHistoryResp `json:"result"`
+	}
+	out = a.adminResolve(`{"jsonrpc":"2.0","method":"getPlayerStatusHistory","params":{"playerName":"player13"},"id":0}`)
+	if err := json.Unmarshal([]byte(out), &history); err != nil || len(history.Result.Changes) != 2 ||
+		history.Result.Changes[0].FromStatus != "active" || history.Result.Changes[1].Reason != "checked" ||
+		history.Result.Changes[1].Actor != "admin:alice" {
+		t.Fatalf("unexpected history %s", out)
+	}
+
+	a.send(`{"jsonrpc":"2.0","method":"getPlayerStatus","params":{"callerId":1,"playerName":"player13"},"id":0}`,
+		`{"jsonrpc":"2.0","error":{"code":-32601,"message":"The method does not exist."},"id":0}`)
+}
+
+func TestSeamlessLedger(t *testing.T) {
+	a := newApiTest(t)
+	a.wallet.AddBalance("player14", "EUR", 1000, nil)
+
+	a.send(`{"jsonrpc":"2.0","method":"withdrawAndDeposit","params":{"callerId":1,"playerName":"player14","withdraw":100,"deposit":30,"currency":"EUR","transactionRef":"23:UOwGgNHPgq3OkqRE"},"id":0}`,
+		`{"jsonrpc":"2.0","result":{"newBalance":930,"transactionId":"1"},"id":0}`)
+
+	var ledger struct {
+		Result dto.GetLedgerEntriesResp `json:"result"`
+	}
+	out := a.adminResolve(`{"jsonrpc":"2.0","method":"getLedgerEntries","params":{"playerName":"player14","currency":"EUR"},"id":0}`)
+	if err := json.Unmarshal([]byte(out), &ledger); err != nil {
+		t.Fatal(err)
+	}
+
+	var legs []string
+	for _, entry := range ledger.Result.Entries {
+		legs = append(legs, fmt.Sprintf("%s:%s:%s:%d", entry.PostingId, entry.Account, entry.Kind, entry.Amount))
+	}
+	expected := "1:cashier:opening:-1000 1:cash:opening:1000 2:cash:bet:-100 2:house:bet:100 2:house:win:-30 2:cash:win:30"
+	if strings.Join(legs, " ") != expected {
+		t.Errorf("got %v, expected %s", legs, expected)
+	}
+	if ledger.Result.Entries[2].TransactionId != "1" {
+		t.Errorf("unexpected transaction id %s", out)
+	}
+
+	a.send(`{"jsonrpc":"2.0","method":"getLedgerEntries","params":{"callerId":1,"playerName":"player14","currency":"EUR"},"id":0}`,
+		`{"jsonrpc":"2.0","error":{"code":-32601,"message":"The method does not exist."},"id":0}`)
+}
+
+func TestSeamlessBalanceHistory(t *testing.T) {
+	a := newApiTest(t)
+	a.wallet.AddBalance("player15", "EUR", 1000, nil)
+
+	a.send(`{"jsonrpc":"2.0","method":"withdrawAndDeposit","params":{"callerId":7,"playerName":"player15","withdraw":100,"deposit":30,"currency":"EUR","transactionRef":"24:UOwGgNHPgq3OkqRE"},"id":0}`,
+		`{"jsonrpc":"2.0","result":{"newBalance":930,"transactionId":"1"},"id":0}`)
+	a.send(`{"jsonrpc":"2.0","method":"rollbackTransaction","params":{"callerId":8,"playerName":"player15","transactionRef":"24:UOwGgNHPgq3OkqRE"},"id":0}`,
+		`{"jsonrpc":"2.0","result":{},"id":0}`)
+
+	var history struct {
+		Result dto.GetBalanceHistoryResp `json:"result"`
+	}
+	out := a.adminResolve(`{"jsonrpc":"2.0","method":"getBalanceHistory","params":{"playerName":"player15","currency":"EUR"},"id":0}`)
+	if err := json.Unmarshal([]byte(out), &history); err != nil {
+		t.Fatal(err)
+	}
+
+	var changes []string
+	for _, change := range history.Result.Changes {
+		changes = append(changes, fmt.Sprintf("%s:%s:%d:%d:%d", change.Cause, change.Actor, change.BalanceBefore, change.BalanceAfter, change.Delta))
+	}
+	expected := "opening::0:1000:1000 withdrawAndDeposit:caller:7:1000:930:-70 rollbackTransaction:caller:8:930:1000:70"
+	if strings.Join(changes, " ") != expected {
+		t.Errorf("got %v, expected %s", changes, expected)
+	}
+	if history.Result.Changes[2].TransactionId != "1" {
+		t.Errorf("unexpected transaction id %s", out)
+	}
+
+	a.adminSend(`{"jsonrpc":"2.0","method":"getBalanceHistory","params":{"playerName":"player15","currency":"EUR","from":"2030-01-01T00:00:00Z"},"id":0}`,
+		`{"jsonrpc":"2.0","result":{"changes":[]},"id":0}`)
+	a.adminSend(`{"jsonrpc":"2.0","method":"getBalanceHistory","params":{"playerName":"player15","currency":"EUR","from":"2030-01-01T00:00:00Z","to":"2020-01-01T00:00:00Z"},"id":0}`,
+		`{"jsonrpc":"2.0","error":{"code":-32602,"message":"invalid method parameters"},"id":0}`)
+}
+
+func TestSeamlessTransactionHistory(t *testing.T) {
+	a := newApiTest(t)
+	a.wallet.AddBalance("player16", "EUR", 1000, nil)
+
+	for i := 1; i <= 3; i++ {
+		a.send(fmt.Sprintf(`{"jsonrpc":"2.0","method":"withdrawAndDeposit","params":{"callerId":1,"playerName":"player16","withdraw":100,"deposit":0,"currency":"EUR","transactionRef":"%d:history","gameRoundRef":"1wawxl:39","gameId":"riot","spinDetails":{"betType":"spin","winType":"standart"}},"id":0}`, i),
+			fmt.Sprintf(`{"jsonrpc":"2.0","result":{"newBalance":%d,"transactionId":"%d"},"id":0}`, 1000-100*i, i))
+	}
+	a.send(`{"jsonrpc":"2.0","method":"rollbackTransaction","params":{"callerId":1,"playerName":"player16","transactionRef":"2:history"},"id":0}`,
+		`{"jsonrpc":"2.0","result":{},"id":0}`)
+
+	page := func(params string) dto.GetTransactionHistoryResp {
+		var history struct {
+			Result dto.GetTransactionHistoryResp `json:"result"`
+		}
+		out := a.adminResolve(`{"jsonrpc":"2.0","method":"getTransactionHistory","params":{"playerName":"player16"` + params + `},"id":0}`)
+		if err := json.Unmarshal([]byte(out), &history); err != nil {
+			t.Fatal(err)
+		}
+		return history.Result
+	}
+
+	first := page(`,"limit":2`)
+	if len(first.Transactions) != 2 || first.Transactions[0].TransactionRef != "3:history" || first.NextCursor == "" {
+		t.Fatalf("unexpected first page %+v", first)
+	}
+	if spin := first.Transactions[0].SpinDetails; spin == nil || spin.BetType != "spin" {
+		t.Errorf("unexpected spin details %+v", spin)
+	}
+
+	second := page(`,"limit":2,"cursor":"` + first.NextCursor + `"`)
+	if len(second.Transactions) != 1 || second.Transactions[0].TransactionRef != "1:history" || second.NextCursor != "" {
+		t.Fatalf("unexpected second page %+v", second)
+	}
+
+	rolledBack := page(`,"currency":"EUR","gameId":"riot","status":"rolled_back"`)
+	if len(rolledBack.Transactions) != 1 || rolledBack.Transactions[0].TransactionId != "2" {
+		t.Fatalf("unexpected rolled back transactions %+v", rolledBack)
+	}
+
+	a.adminSend(`{"jsonrpc":"2.0","method":"getTransactionHistory","params":{"playerName":"player16","cursor":"not a cursor"},"id":0}`,
+		`{"jsonrpc":"2.0","error":{"code":-32602,"message":"invalid method parameters"},"id":0}`)
+	a.adminSend(`{"jsonrpc":"2.0","method":"getTransactionHistory","params":{"playerName":"player16","status":"open"},"id":0}`,
+		`{"jsonrpc":"2.0","error":{"code":-32602,"message":"invalid method parameters"},"id":0}`)
+
+	a.send(`{"jsonrpc":"2.0","method":"getTransactionHistory","params":{"callerId":1,"playerName":"player16"},"id":0}`,
+		`{"jsonrpc":"2.0","error":{"code":-32601,"message":"The method does not exist."},"id":0}`)
+}
+
+func TestSeamlessMinorUnits(t *testing.T) {
+	a := newApiTest(t)
+	a.wallet.AddBalance("player5", "EUR", 9_000_000_000_000, nil)
+
+	a.send(`{"jsonrpc":"2.0","method":"withdrawAndDeposit","params":{"callerId":1,"playerName":"player5","withdraw":0,"deposit":4000000000,"currency":"EUR","transactionRef":"6:UOwGgNHPgq3OkqRE"},"id":0}`,
+		`{"jsonrpc":"2.0","result":{"newBalance":9004000000000,"transactionId":"1"},"id":0}`)
+
+	a.send(`{"jsonrpc":"2.0","method":"withdrawAndDeposit","params":{"callerId":1,"playerName":"player5","withdraw":0,"deposit":9223372036854775807,"currency":"EUR","transactionRef":"7:UOwGgNHPgq3OkqRE"},"id":0}`,
+		`{"jsonrpc":"2.0","error":{"code":7,"message":"ErrAmountOverflow"},"id":0}`)
+
+	a.send(`{"jsonrpc":"2.0","method":"getBalance","params":{"callerId":1,"playerName":"player5","currency":"EUR"},"id":0}`,
+		`{"jsonrpc":"2.0","result":{"balance":9004000000000},"id":0}`)
+}
+
+func TestSeamlessCurrencies(t *testing.T) {
+	a := newApiTest(t)
+	a.wallet.AddBalance("player6", "EUR", 100, nil)
+	a.wallet.CreateCurrency(context.Background(), "USDT", 6)
+
+	a.send(`{"jsonrpc":"2.0","method":"getCurrencies","id":0}`,
+		`{"jsonrpc":"2.0","result":{"currencies":[{"code":"EUR","exponent":2},{"code":"USDT","exponent":6}]},"id":0}`)
+
+	a.send(`{"jsonrpc":"2.0","method":"withdrawAndDeposit","params":{"callerId":1,"playerName":"player6","withdraw":0,"deposit":2500000,"currency":"USDT","transactionRef":"8:UOwGgNHPgq3OkqRE"},"id":0}`,
+		`{"jsonrpc":"2.0","result":{"newBalance":2500000,"transactionId":"1"},"id":0}`)
+
+	a.send(`{"jsonrpc":"2.0","method":"getBalance","params":{"callerId":1,"playerName":"player6","currency":"BTC"},"id":0}`,
+		`{"jsonrpc":"2.0","error":{"code":2,"message":"ErrIllegalCurrencyCode"},"id":0}`)
+
+	a.send(`{"jsonrpc":"2.0","method":"getBalance","params":{"callerId":1,"playerName":"player6","currency":"usdt"},"id":0}`,
+		`{"jsonrpc":"2.0","error":{"code":-32602,"message":"invalid method parameters"},"id":0}`)
+}
+
+func TestErrorCodes(t *testing.T) {
+	a := newApiTest(t)
+	a.wallet.AddBalance("player4", "EUR", 0, nil)
+
+	a.send(`{"jsonrpc":"2.0","method":"getErrorCodes","id":0}`,
+		`{"jsonrpc":"2.0","result":{"errors":[`+
+			`{"code":1,"message":"ErrNotEnoughMoneyCode","retryable":false,"httpStatus":402},`+
+			`{"code":2,"message":"ErrIllegalCurrencyCode","retryable":false,"httpStatus":400},`+
+			`{"code":3,"message":"ErrNegativeDepositCode","retryable":false,"httpStatus":400},`+
+			`{"code":4,"message":"ErrNegativeWithdrawalCode","retryable":false,"httpStatus":400},`+
+			`{"code":5,"message":"ErrSpendingBudgetExceeded","retryable":false,"httpStatus":403},`+
+			`{"code":6,"message":"ErrTransactionRollback","retryable":false,"httpStatus":409},`+
+			`{"code":7,"message":"ErrAmountOverflow","retryable":false,"httpStatus":422},`+
+			`{"code":8,"message":"ErrTransactionConflict","retryable":false,"httpStatus":409},`+
+			`{"code":9,"message":"ErrRoundNotFound","retryable":false,"httpStatus":404},`+
+			`{"code":10,"message":"ErrFreeRoundsNotFound","retryable":false,"httpStatus":404},`+
+			`{"code":11,"message":"ErrNotEnoughFreeRounds","retryable":false,"httpStatus":422},`+
+			`{"code":12,"message":"ErrFreeRoundsExists","retryable":false,"httpStatus":409},`+
+			`{"code":13,"message":"ErrBonusExists","retryable":false,"httpStatus":409},`+
+			`{"code":14,"message":"ErrPlayerBlocked","retryable":false,"httpStatus":403},`+
+			`{"code":15,"message":"ErrPlayerStatusLocked","retryable":false,"httpStatus":409},`+
+			`{"code":16,"message":"ErrPlayerNotFound","retryable":false,"httpStatus":404},`+
+			`{"code":17,"message":"ErrWalletExists","retryable":false,"httpStatus":409},`+
+			`{"code":18,"message":"ErrTransactionNotFound","retryable":false,"httpStatus":404},`+
+			`{"code":19,"message":"ErrWalletNotFound","retryable":false,"httpStatus":404},`+
+			`{"code":20,"message":"ErrForeignTransaction","retryable":false,"httpStatus":409},`+
+			`{"code":21,"message":"ErrInvalidTransition","retryable":false,"httpStatus":409},`+
+			`{"code":22,"message":"ErrTransactionBusy","retryable":true,"httpStatus":409},`+
+			`{"code":23,"message":"ErrSnapshotMissing","retryable":false,"httpStatus":409},`+
+			`{"code":24,"message":"ErrInvalidFreeRound","retryable":false,"httpStatus":400},`+
+			`{"code":25,"message":"ErrInvalidBonus","retryable":false,"httpStatus":400},`+
+			`{"code":26,"message":"ErrInvalidLimit","retryable":false,"httpStatus":400},`+
+			`{"code":27,"message":"ErrInvalidPlayerStatus","retryable":false,"httpStatus":400},`+
+			`{"code":28,"message":"ErrInvalidAdjustment","retryable":false,"httpStatus":400},`+
+			`{"code":29,"message":"ErrLedgerUnbalanced","retryable":false,"httpStatus":500},`+
+			`{"code":30,"message":"ErrLedgerMismatch","retryable":false,"httpStatus":500},`+
+			`{"code":-32000,"message":"internal error","retryable":true,"httpStatus":500}]},"id":0}`)
+
+	a.send(`{"jsonrpc":"2.0","method":"getBalance","params":{"callerId":1,"playerName":"unknown","currency":"EUR"},"id":0}`,
+		`{"jsonrpc":"2.0","error":{"code":1,"message":"ErrNotEnoughMoneyCode"},"id":0}`)
+
+	a.send(`{"jsonrpc":"2.0","method":"rollbackTransaction","params":{"callerId":1,"playerName":"unknown","transactionRef":"1"},"id":0}`,
+		`{"jsonrpc":"2.0","error":{"code":16,"message":"ErrPlayerNotFound"},"id":0}`)
+}
+
+// TestErrorCodesRegistered keeps every service.Err* in the registry, a
+// missing one would be reported to clients as a retryable internal error.
+func TestErrorCodesRegistered(t *testing.T) {
+	files, err := filepath.Glob("../../service/*.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	registered := make(map[string]bool)
+	for _, c := range errorCodes {
+		registered[c.err.Error()] = true
+	}
+
+	for _, file := range files {
+		if strings.HasSuffix(file, "_test.go") {
+			continue
+		}
+
+		f, err := parser.ParseFile(token.NewFileSet(), file, nil, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for _, decl := range f.Decls {
+			if gen, ok := decl.(*ast.GenDecl); ok && gen.Tok == token.VAR {
+				for _, spec := range gen.Specs {
+					for _, name := range spec.(*ast.ValueSpec).Names {
+						if strings.HasPrefix(name.Name, "Err") && !registered[name.Name] {
+							t.Errorf("service.%s has no error code", name.Name)
+						}
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestAdmin(t *testing.T) {
+	a := newApiTest(t)
+	a.wallet.AddBalance("player17", "EUR", 1000, nil)
+
+	a.adminSend(`{"jsonrpc":"2.0","method":"createWallet","params":{"playerName":"player18","currency":"EUR"},"id":0}`,
+		`{"jsonrpc":"2.0","result":{"playerName":"player18","currency":"EUR","balance":0,"cashBalance":0,"bonusBalance":0},"id":0}`)
+	a.adminSend(`{"jsonrpc":"2.0","method":"createWallet","params":{"playerName":"player18","currency":"EUR"},"id":0}`,
+		`{"jsonrpc":"2.0","error":{"code":17,"message":"ErrWalletExists"},"id":0}`)
+
+	a.adminSend(`{"jsonrpc":"2.0","method":"adjustBalance","params":{"playerName":"player18","currency":"EUR","amount":250,"reasonCode":"goodwill","comment":"outage"},"id":0}`,
+		`{"jsonrpc":"2.0","result":{"adjustmentId":"1","wallet":{"playerName":"player18","currency":"EUR","balance":250,"cashBalance":250,"bonusBalance":0}},"id":0}`)
+	a.adminSend(`{"jsonrpc":"2.0","method":"adjustBalance","params":{"playerName":"player18","currency":"EUR","amount":250},"id":0}`,
+		`{"jsonrpc":"2.0","error":{"code":-32602,"message":"invalid method parameters"},"id":0}`)
+	a.adminSend(`{"jsonrpc":"2.0","method":"adjustBalance","params":{"playerName":"player18","currency":"EUR","amount":-300,"reasonCode":"correction"},"id":0}`,
+		`{"jsonrpc":"2.0","error":{"code":1,"message":"ErrNotEnoughMoneyCode"},"id":0}`)
+
+	var adjustments struct {
+		Result dto.ListAdjustmentsResp `json:"result"`
+	}
+	out := a.adminResolve(`{"jsonrpc":"2.0","method":"listAdjustments","params":{"playerName":"player18"},"id":0}`)
+	if err := json.Unmarshal([]byte(out), &adjustments); err != nil || len(adjustments.Result.Adjustments) != 1 ||
+		adjustments.Result.Adjustments[0].Actor != "admin:alice" || adjustments.Result.Adjustments[0].ReasonCode != "goodwill" {
+		t.Fatalf("unexpected adjustments %s", out)
+	}
+
+	a.send(`{"jsonrpc":"2.0","method":"withdrawAndDeposit","params":{"callerId":1,"playerName":"player17","withdraw":100,"deposit":0,"currency":"EUR","transactionRef":"1:admin"},"id":0}`,
+		`{"jsonrpc":"2.0","result":{"newBalance":900,"transactionId":"1"},"id":0}`)
+
+	var transaction struct {
+		Result dto.Transaction `json:"result"`
+	}
+	out = a.adminResolve(`{"jsonrpc":"2.0","method":"forceRollback","params":{"transactionRef":"1:admin"},"id":0}`)
+	if err := json.Unmarshal([]byte(out), &transaction); err != nil ||
+		transaction.Result.PlayerName != "player17" || transaction.Result.Status != "rolled_back" {
+		t.Fatalf("unexpected rollback %s", out)
+	}
+
+	a.send(`{"jsonrpc":"2.0","method":"getBalance","params":{"callerId":1,"playerName":"player17","currency":"EUR"},"id":0}`,
+		`{"jsonrpc":"2.0","result":{"balance":1000},"id":0}`)
+	a.adminSend(`{"jsonrpc":"2.0","method":"getTransaction","params":{"transactionRef":"2:admin"},"id":0}`,
+		`{"jsonrpc":"2.0","error":{"code":18,"message":"ErrTransactionNotFound"},"id":0}`)
+
+	a.adminResolve(`{"jsonrpc":"2.0","method":"grantFreeRounds","params":{"playerName":"player17","currency":"EUR","bonusId":"fr:admin","betValue":10,"freerounds":5},"id":0}`)
+
+	var freeRounds struct {
+		Result dto.FreeRounds `json:"result"`
+	}
+	out = a.adminResolve(`{"jsonrpc":"2.0","method":"editFreeRounds","params":{"playerName":"player17","currency":"EUR","bonusId":"fr:admin","freeroundsLeft":8},"id":0}`)
+	if err := json.Unmarshal([]byte(out), &freeRounds); err != nil ||
+		freeRounds.Result.FreeroundsLeft != 8 || freeRounds.Result.Freerounds != 8 || freeRounds.Result.Status != "active" {
+		t.Fatalf("unexpected free rounds %s", out)
+	}
+
+	a.adminSend(`{"jsonrpc":"2.0","method":"editFreeRounds","params":{"playerName":"player17","currency":"EUR","bonusId":"fr:admin"},"id":0}`,
+		`{"jsonrpc":"2.0","error":{"code":-32602,"message":"invalid method parameters"},"id":0}`)
+
+	// The wallet methods are not served by the admin server.
+	a.adminSend(`{"jsonrpc":"2.0","method":"getBalance","params":{"callerId":1,"playerName":"player17","currency":"EUR"},"id":0}`,
+		`{"jsonrpc":"2.0","error":{"code":-32601,"message":"The method does not exist."},"id":0}`)
+}
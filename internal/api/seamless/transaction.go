@@ -0,0 +1,119 @@
+package seamless
+
+import (
+	"context"
+	"encoding/base64"
+	"seamless-api-wrapper/internal/model"
+	"seamless-api-wrapper/internal/rpc"
+	"seamless-api-wrapper/internal/validate"
+	"seamless-api-wrapper/package/dto"
+	"strconv"
+)
+
+const defaultHistoryLimit = 50
+
+func (a *Admin) GetTransactionHistory(ctx context.Context, req *dto.GetTransactionHistoryReq) (*dto.GetTransactionHistoryResp, error) {
+	if err := validate.Req(req); err != nil {
+		return nil, rpc.InvalidParamsError
+	}
+
+	if req.From != nil && req.To != nil && !req.From.Before(*req.To) {
+		return nil, rpc.InvalidParamsError
+	}
+
+	filter := model.TransactionFilter{
+		PlayerName:   req.PlayerName,
+		Currency:     req.Currency,
+		From:         req.From,
+		To:           req.To,
+		GameID:       req.GameId,
+		GameRoundRef: req.GameRoundRef,
+		BonusID:      req.BonusId,
+		Limit:        req.Limit,
+	}
+
+	if req.Status != nil {
+		status := model.TransactionStatus(*req.Status)
+		filter.Status = &status
+	}
+
+	if filter.Limit == 0 {
+		filter.Limit = defaultHistoryLimit
+	}
+
+	if req.Cursor != "" {
+		beforeID, ok := decodeCursor(req.Cursor)
+		if !ok {
+			return nil, rpc.InvalidParamsError
+		}
+		filter.BeforeID = beforeID
+	}
+
+	// One more transaction than asked tells whether there is a next page.
+	filter.Limit++
+	records, err := a.seamlessService.TransactionHistory(ctx, &filter)
+	if err != nil {
+		return nil, rpcError(err, "fail get transaction history")
+	}
+
+	resp := &dto.GetTransactionHistoryResp{Transactions: make([]dto.Transaction, 0, len(records))}
+	if len(records) == filter.Limit {
+		records = records[:len(records)-1]
+		resp.NextCursor = encodeCursor(records[len(records)-1].ID)
+	}
+
+	for i := range records {
+		resp.Transactions = append(resp.Transactions, transactionResp(&records[i]))
+	}
+
+	return resp, nil
+}
+
+func transactionResp(record *model.TransactionRecord) dto.Transaction {
+	transaction := dto.Transaction{
+		TransactionId:    strconv.Itoa(record.ID),
+		TransactionRef:   record.TransactionRef,
+		PlayerName:       record.PlayerName,
+		Currency:         record.Currency,
+		Withdraw:         record.Withdraw,
+		Deposit:          record.Deposit,
+		GameId:           record.GameID,
+		GameRoundRef:     record.GameRoundRef,
+		SessionId:        record.SessionId,
+		BonusId:          record.BonusId,
+		ChargeFreeRounds: record.ChargeFreeRounds,
+		Status:           string(record.Status),
+		BalanceAfter:     record.BalanceAfter,
+		CreatedAt:        record.CreatedAt,
+		UpdatedAt:        record.UpdatedAt,
+	}
+
+	if record.SpinDetails != nil {
+		transaction.SpinDetails = &dto.SpinDetails{
+			BetType: record.SpinDetails.BetType,
+			WinType: record.SpinDetails.WinType,
+		}
+	}
+
+	return transaction
+}
+
+// encodeCursor hides the id the next page starts before, clients only pass
+// the cursor back.
+func encodeCursor(id int) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.Itoa(id)))
+}
+
+func decodeCursor(cursor string) (int, bool) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return 0, false
+	}
+
+	id, err := strconv.Atoi(string(raw))
+	if err != nil || id <= 0 {
+		return 0, false
+	}
+
+	return id, true
+}
@@ -0,0 +1,101 @@
+package capture
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+const filePattern = "capture-*.jsonl"
+
+// rotatingFile starts a new file once maxSize bytes have been written and
+// keeps at most maxFiles of them in dir. Zero limits disable rotation and
+// cleanup.
+type rotatingFile struct {
+	dir      string
+	maxSize  int64
+	maxFiles int
+	file     *os.File
+	size     int64
+}
+
+func newRotatingFile(dir string, maxSize int64, maxFiles int) (*rotatingFile, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+
+	f := &rotatingFile{dir: dir, maxSize: maxSize, maxFiles: maxFiles}
+	if err := f.rotate(); err != nil {
+		return nil, err
+	}
+
+	return f, nil
+}
+
+// Write never splits a line between files.
+func (f *rotatingFile) Write(line []byte) (int, error) {
+	if f.maxSize > 0 && f.size > 0 && f.size+int64(len(line)) > f.maxSize {
+		if err := f.rotate(); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := f.file.Write(line)
+	f.size += int64(n)
+	return n, err
+}
+
+func (f *rotatingFile) Close() error {
+	return f.file.Close()
+}
+
+func (f *rotatingFile) rotate() error {
+	if f.file != nil {
+		if err := f.file.Close(); err != nil {
+			return err
+		}
+	}
+
+	name := fmt.Sprintf("capture-%s.jsonl", time.Now().UTC().Format("20060102T150405.000000000"))
+	file, err := os.OpenFile(filepath.Join(f.dir, name), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+
+	f.file, f.size = file, 0
+
+	return f.cleanup()
+}
+
+func (f *rotatingFile) cleanup() error {
+	if f.maxFiles <= 0 {
+		return nil
+	}
+
+	files, err := Files(f.dir)
+	if err != nil {
+		return err
+	}
+
+	for len(files) > f.maxFiles {
+		if err := os.Remove(files[0]); err != nil {
+			return err
+		}
+		files = files[1:]
+	}
+
+	return nil
+}
+
+// Files lists the capture files of dir, oldest first.
+func Files(dir string) ([]string, error) {
+	files, err := filepath.Glob(filepath.Join(dir, filePattern))
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Strings(files)
+	return files, nil
+}
@@ -0,0 +1,104 @@
+package capture
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"os"
+	"time"
+)
+
+// Record is one request and response pair, written as a single JSON line. A
+// request that is not valid JSON is kept byte for byte in RequestRaw.
+type Record struct {
+	RequestID  string          `json:"request_id"`
+	Method     string          `json:"method,omitempty"`
+	Caller     json.RawMessage `json:"caller,omitempty"`
+	RemoteAddr string          `json:"remote_addr,omitempty"`
+	StartedAt  time.Time       `json:"started_at"`
+	DurationMs float64         `json:"duration_ms"`
+	Outcome    string          `json:"outcome"`
+	Request    json.RawMessage `json:"request,omitempty"`
+	RequestRaw []byte          `json:"request_raw,omitempty"`
+	Response   json.RawMessage `json:"response,omitempty"`
+}
+
+// ReadFile calls fn for every record of a capture file in order.
+func ReadFile(path string, fn func(*Record) error) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	return Read(file, fn)
+}
+
+func Read(r io.Reader, fn func(*Record) error) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 64*1024*1024)
+
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+
+		var record Record
+		if err := json.Unmarshal(line, &record); err != nil {
+			return err
+		}
+
+		if err := fn(&record); err != nil {
+			return err
+		}
+	}
+
+	return scanner.Err()
+}
+
+// Body returns the request as it was received.
+func (r *Record) Body() []byte {
+	if r.RequestRaw != nil {
+		return r.RequestRaw
+	}
+
+	return r.Request
+}
+
+// rawJSON keeps valid JSON as is and stores anything else as a JSON string,
+// so a malformed response can not break the line format.
+func rawJSON(data []byte) json.RawMessage {
+	if len(data) == 0 {
+		return nil
+	}
+
+	if json.Valid(data) {
+		return data
+	}
+
+	quoted, _ := json.Marshal(string(data))
+	return quoted
+}
+
+type envelope struct {
+	Method string `json:"method"`
+	Params struct {
+		CallerId json.RawMessage `json:"callerId"`
+	} `json:"params"`
+}
+
+// describe fills the method and caller of a record from its request. Batches
+// are described by their first call.
+func (r *Record) describe() {
+	var single envelope
+	if err := json.Unmarshal(r.Request, &single); err == nil {
+		r.Method, r.Caller = single.Method, single.Params.CallerId
+		return
+	}
+
+	var batch []envelope
+	if err := json.Unmarshal(r.Request, &batch); err == nil && len(batch) > 0 {
+		r.Method, r.Caller = "batch", batch[0].Params.CallerId
+	}
+}
@@ -0,0 +1,118 @@
+package capture
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	log "github.com/sirupsen/logrus"
+	"io"
+	"seamless-api-wrapper/internal/config"
+	"seamless-api-wrapper/internal/rpc"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Recorder writes every resolved request to rotating capture files. Records
+// are written by a single goroutine, when its queue is full they are dropped
+// rather than slowing down the wallet.
+type Recorder struct {
+	files   *rotatingFile
+	records chan *Record
+	dropped atomic.Int64
+	wg      sync.WaitGroup
+}
+
+func NewRecorder(conf *config.Capture) (*Recorder, error) {
+	files, err := newRotatingFile(conf.Dir, conf.MaxSize, conf.MaxFiles)
+	if err != nil {
+		return nil, err
+	}
+
+	buffer := conf.Buffer
+	if buffer <= 0 {
+		buffer = 1024
+	}
+
+	r := &Recorder{
+		files:   files,
+		records: make(chan *Record, buffer),
+	}
+
+	r.wg.Add(1)
+	go r.run()
+
+	return r, nil
+}
+
+// Middleware captures the raw request and response around the resolver.
+func (r *Recorder) Middleware(next rpc.Resolver) rpc.Resolver {
+	return rpc.ResolverFunc(func(ctx context.Context, w io.Writer, body io.Reader) rpc.Outcome {
+		var request, response bytes.Buffer
+
+		started := time.Now()
+		outcome := next.Resolve(ctx, io.MultiWriter(w, &response), io.TeeReader(body, &request))
+		duration := time.Since(started)
+
+		record := &Record{
+			RequestID:  newRequestID(),
+			RemoteAddr: rpc.Peer(ctx),
+			StartedAt:  started.UTC(),
+			DurationMs: float64(duration.Microseconds()) / 1000,
+			Outcome:    outcome.String(),
+			Request:    request.Bytes(),
+			Response:   response.Bytes(),
+		}
+
+		select {
+		case r.records <- record:
+		default:
+			r.dropped.Add(1)
+		}
+
+		return outcome
+	})
+}
+
+// Close flushes queued records and closes the current file.
+func (r *Recorder) Close() error {
+	close(r.records)
+	r.wg.Wait()
+
+	if dropped := r.dropped.Load(); dropped > 0 {
+		log.Warnf("capture: dropped %d records", dropped)
+	}
+
+	return r.files.Close()
+}
+
+func (r *Recorder) run() {
+	defer r.wg.Done()
+
+	var line bytes.Buffer
+	for record := range r.records {
+		if len(record.Request) > 0 && !json.Valid(record.Request) {
+			record.Request, record.RequestRaw = nil, record.Request
+		}
+		record.Response = rawJSON(record.Response)
+		record.describe()
+
+		line.Reset()
+		if err := json.NewEncoder(&line).Encode(record); err != nil {
+			log.Error(err)
+			continue
+		}
+
+		if _, err := r.files.Write(line.Bytes()); err != nil {
+			log.Error(err)
+		}
+	}
+}
+
+func newRequestID() string {
+	var id [12]byte
+	_, _ = rand.Read(id[:])
+	return hex.EncodeToString(id[:])
+}
@@ -0,0 +1,108 @@
+package capture
+
+import (
+	"context"
+	"io"
+	"os"
+	"seamless-api-wrapper/internal/config"
+	"seamless-api-wrapper/internal/rpc"
+	"strings"
+	"testing"
+)
+
+func TestRecorder(t *testing.T) {
+	dir := t.TempDir()
+
+	recorder, err := NewRecorder(&config.Capture{Dir: dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	response := `{"jsonrpc":"2.0","result":{"balance":10000},"id":0}`
+	resolver := recorder.Middleware(rpc.ResolverFunc(func(_ context.Context, w io.Writer, r io.Reader) rpc.Outcome {
+		_, _ = io.ReadAll(r)
+		_, _ = io.WriteString(w, response)
+		return rpc.Outcome{Kind: rpc.OutcomeOK}
+	}))
+
+	request := `{"jsonrpc":"2.0","method":"getBalance","params":{"callerId":1,"playerName":"player1","currency":"EUR"},"id":0}`
+	ctx := rpc.WithPeer(context.Background(), "10.0.0.1:5000")
+
+	var out strings.Builder
+	resolver.Resolve(ctx, &out, strings.NewReader(request))
+	resolver.Resolve(ctx, &out, strings.NewReader(`not json`))
+
+	if err := recorder.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if out.String() != response+response {
+		t.Errorf("response changed: %q", out.String())
+	}
+
+	files, err := Files(dir)
+	if err != nil || len(files) != 1 {
+		t.Fatalf("expected one capture file, got %v %v", files, err)
+	}
+
+	var records []*Record
+	err = ReadFile(files[0], func(record *Record) error {
+		records = append(records, record)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(records) != 2 {
+		t.Fatalf("expected 2 records, got %d", len(records))
+	}
+
+	first := records[0]
+	if first.RequestID == "" || first.Method != "getBalance" || string(first.Caller) != "1" ||
+		first.RemoteAddr != "10.0.0.1:5000" || first.Outcome != "ok" ||
+		string(first.Request) != request || string(first.Response) != response {
+		t.Errorf("unexpected record %+v", first)
+	}
+
+	if records[1].Request != nil || string(records[1].Body()) != "not json" {
+		t.Errorf("malformed request not kept as raw bytes: %s %q", records[1].Request, records[1].RequestRaw)
+	}
+}
+
+func TestRotatingFile(t *testing.T) {
+	dir := t.TempDir()
+
+	f, err := newRotatingFile(dir, 10, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 4; i++ {
+		if _, err := f.Write([]byte("0123456789\n")); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	files, err := Files(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(files) != 2 {
+		t.Fatalf("expected 2 files, got %v", files)
+	}
+
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(data) != "0123456789\n" {
+			t.Errorf("%s: got %q", file, data)
+		}
+	}
+}
@@ -7,10 +7,64 @@ import (
 
 type Duration struct{ time.Duration }
 
+// Server MaxBodySize caps the request body in bytes after decompression, zero
+// uses 1 MiB.
 type Server struct {
-	Address      string   `toml:"address"`
-	ReadTimeout  Duration `toml:"read-timeout"`
-	WriteTimeout Duration `toml:"write-timeout"`
+	Address      string      `toml:"address"`
+	ReadTimeout  Duration    `toml:"read-timeout"`
+	WriteTimeout Duration    `toml:"write-timeout"`
+	DrainDelay   Duration    `toml:"drain-delay"`
+	MaxBodySize  int64       `toml:"max-body-size"`
+	Compression  Compression `toml:"compression"`
+	Status       Status      `toml:"status"`
+	CORS         CORS        `toml:"cors"`
+	Auth         Auth        `toml:"auth"`
+}
+
+type Compression struct {
+	Enabled bool `toml:"enabled"`
+	MinSize int  `toml:"min-size"`
+}
+
+// Status maps RPC outcomes to HTTP status codes. A zero value keeps 200 OK,
+// a zero Draining keeps serving requests until shutdown. ErrorStatus answers
+// a failed single call with the HTTP status of its error code.
+type Status struct {
+	Notification   int  `toml:"notification"`
+	ParseError     int  `toml:"parse-error"`
+	InvalidRequest int  `toml:"invalid-request"`
+	InternalError  int  `toml:"internal-error"`
+	Draining       int  `toml:"draining"`
+	ErrorStatus    bool `toml:"error-status"`
+}
+
+// CORS lets browsers on AllowedOrigins call the API. Methods limits which
+// RPC methods cross-origin requests may call, empty allows all of them.
+type CORS struct {
+	AllowedOrigins []string `toml:"allowed-origins"`
+	AllowedHeaders []string `toml:"allowed-headers"`
+	ExposedHeaders []string `toml:"exposed-headers"`
+	Methods        []string `toml:"methods"`
+	MaxAge         Duration `toml:"max-age"`
+}
+
+// Auth requires every request to carry "Authorization: Bearer <token>" with
+// one of Tokens, an empty list lets every request in.
+type Auth struct {
+	Tokens []Token `toml:"tokens"`
+}
+
+// Token Name identifies who uses the token.
+type Token struct {
+	Name  string `toml:"name"`
+	Token string `toml:"token"`
+}
+
+// Admin serves the back-office methods on a server of its own, which must
+// have auth tokens.
+type Admin struct {
+	Enabled bool   `toml:"enabled"`
+	Server  Server `toml:"server"`
 }
 
 type Postgres struct {
@@ -22,9 +76,64 @@ type Postgres struct {
 	PoolSize int    `toml:"pool-size"`
 }
 
+// Capture records every request and response pair to JSON lines files in
+// Dir. A file is rotated after MaxSize bytes and only MaxFiles are kept.
+type Capture struct {
+	Enabled  bool   `toml:"enabled"`
+	Dir      string `toml:"dir"`
+	MaxSize  int64  `toml:"max-size"`
+	MaxFiles int    `toml:"max-files"`
+	Buffer   int    `toml:"buffer"`
+}
+
+// Storage selects the wallet backend, "postgres" (the default) or "memory".
+type Storage struct {
+	Driver string `toml:"driver"`
+}
+
+// Memory lists the wallets the in-memory backend starts with.
+type Memory struct {
+	Balances []MemoryBalance `toml:"balances"`
+}
+
+// MemoryBalance amount is in minor units of the currency.
+type MemoryBalance struct {
+	PlayerName string `toml:"player-name"`
+	Currency   string `toml:"currency"`
+	Amount     int64  `toml:"amount"`
+	GameID     string `toml:"game-id"`
+}
+
+// Rounds closes game rounds that had no transaction for InactivityTimeout,
+// checking every CheckInterval. A zero InactivityTimeout keeps them open.
+type Rounds struct {
+	InactivityTimeout Duration `toml:"inactivity-timeout"`
+	CheckInterval     Duration `toml:"check-interval"`
+}
+
+// Bonus SpendOrder is "cash-first" (the default) or "bonus-first", the funds
+// a bet against an active bonus is paid from first.
+type Bonus struct {
+	SpendOrder string `toml:"spend-order"`
+}
+
+// Currency is registered on startup, Exponent is the number of decimal
+// digits, from 0 to 18.
+type Currency struct {
+	Code     string `toml:"code"`
+	Exponent int    `toml:"exponent"`
+}
+
 type ServerConfig struct {
-	Server   Server   `toml:"server"`
-	Postgres Postgres `toml:"postgres"`
+	Server     Server     `toml:"server"`
+	Storage    Storage    `toml:"storage"`
+	Postgres   Postgres   `toml:"postgres"`
+	Memory     Memory     `toml:"memory"`
+	Capture    Capture    `toml:"capture"`
+	Rounds     Rounds     `toml:"rounds"`
+	Bonus      Bonus      `toml:"bonus"`
+	Currencies []Currency `toml:"currencies"`
+	Admin      Admin      `toml:"admin"`
 }
 
 func ParseServerConfig(configFile string) (*ServerConfig, error) {
@@ -0,0 +1,96 @@
+package memory
+
+import (
+	"context"
+	"seamless-api-wrapper/internal/model"
+	"seamless-api-wrapper/internal/service"
+	"time"
+)
+
+func (s *SeamlessService) CreateWallet(_ context.Context, playerName, currencyCode string, gameID *string) (*model.Balance, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	currency, ok := s.currencies[currencyCode]
+	if !ok {
+		return nil, service.ErrIllegalCurrencyCode
+	}
+
+	for _, balance := range s.balances[playerName] {
+		if balance.CurrencyID == currency.ID {
+			return nil, service.ErrWalletExists
+		}
+	}
+
+	balance := s.addBalance(playerName, currency)
+	balance.GameID = gameID
+
+	return s.result(balance), nil
+}
+
+func (s *SeamlessService) Adjust(ctx context.Context, playerName, currencyCode string, adjustment *model.Adjustment) (*model.Balance, error) {
+	if err := service.CheckAdjustment(adjustment); err != nil {
+		return nil, err
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	balance, err := s.balance(playerName, currencyCode)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := service.Adjusted(balance, adjustment); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+
+	if adjustment.Reason == model.AdjustmentDeposit {
+		if err := s.checkDepositLimits(balance, adjustment.Amount, now); err != nil {
+			return nil, err
+		}
+	}
+
+	s.nextAdjustmentID++
+	adjustment.ID = s.nextAdjustmentID
+	adjustment.BalanceID = balance.ID
+	adjustment.PlayerName = playerName
+	adjustment.Actor = service.Actor(ctx)
+	adjustment.CreatedAt = now
+	adjustment.Currency = balance.Currency
+	s.adjustments = append(s.adjustments, *adjustment)
+
+	s.post(ctx, model.CauseManualAdjustment, service.ManualEntries(balance, adjustment.Amount), now)
+	balance.UpdatedAt = now
+
+	return s.result(balance), nil
+}
+
+func (s *SeamlessService) Adjustments(_ context.Context, playerName string) ([]model.Adjustment, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	var adjustments []model.Adjustment
+	for _, adjustment := range s.adjustments {
+		if adjustment.PlayerName == playerName {
+			adjustments = append(adjustments, adjustment)
+		}
+	}
+
+	return adjustments, nil
+}
+
+func (s *SeamlessService) TransactionByRef(_ context.Context, transactionRef string) (*model.TransactionRecord, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	transaction, ok := s.transactions[transactionRef]
+	if !ok {
+		return nil, service.ErrTransactionNotFound
+	}
+
+	record := s.record(transaction)
+	return &record, nil
+}
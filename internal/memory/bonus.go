@@ -0,0 +1,122 @@
+package memory
+
+import (
+	"context"
+	"seamless-api-wrapper/internal/model"
+	"seamless-api-wrapper/internal/service"
+	"sort"
+	"time"
+)
+
+// SetSpendOrder selects which funds bets against a bonus use first, cash by
+// default.
+func (s *SeamlessService) SetSpendOrder(order service.SpendOrder) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.spendOrder = order
+}
+
+func (s *SeamlessService) GrantBonus(ctx context.Context, playerName, currencyCode string, bonus *model.Bonus) error {
+	if err := service.CheckBonus(bonus); err != nil {
+		return err
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	balance, err := s.balance(playerName, currencyCode)
+	if err != nil {
+		return err
+	}
+
+	for _, granted := range s.bonuses {
+		if granted.BalanceID == balance.ID && granted.BonusID == bonus.BonusID {
+			return service.ErrBonusExists
+		}
+	}
+
+	now := time.Now()
+
+	s.nextBonusID++
+	bonus.ID = s.nextBonusID
+	bonus.BalanceID = balance.ID
+	bonus.PlayerName = playerName
+	bonus.Amount = bonus.Granted
+	bonus.Wagered = 0
+	bonus.Status = model.BonusActive
+	bonus.CreatedAt = now
+	bonus.UpdatedAt = now
+	bonus.ConvertedAt = nil
+	bonus.Currency = balance.Currency
+
+	s.post(ctx, model.CauseGrantBonus, service.GrantEntries(balance, bonus), now)
+
+	stored := *bonus
+	s.bonuses[stored.ID] = &stored
+
+	return nil
+}
+
+func (s *SeamlessService) Bonuses(_ context.Context, playerName string) ([]model.Bonus, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	var bonuses []model.Bonus
+	for _, bonus := range s.bonuses {
+		if bonus.PlayerName == playerName {
+			bonuses = append(bonuses, *bonus)
+		}
+	}
+	sort.Slice(bonuses, func(i, j int) bool { return bonuses[i].ID < bonuses[j].ID })
+
+	return bonuses, nil
+}
+
+// activeBonus copies the active bonus a transaction is wagered against, nil
+// when it carries no bonusId or the bonus is not active.
+func (s *SeamlessService) activeBonus(balanceID int, transaction *model.Transaction) *model.Bonus {
+	if transaction.BonusId == nil {
+		return nil
+	}
+
+	for _, bonus := range s.bonuses {
+		if bonus.BalanceID == balanceID && bonus.BonusID == *transaction.BonusId && bonus.Status == model.BonusActive {
+			result := *bonus
+			return &result
+		}
+	}
+	return nil
+}
+
+// wageredBonus copies the bonus a committed transaction was wagered against
+// into bonuses, so several transactions are reversed against one copy. It
+// returns nil when the bonus was converted since.
+func (s *SeamlessService) wageredBonus(transaction *model.Transaction, bonuses map[int]*model.Bonus) *model.Bonus {
+	if transaction.WageredBonusID == nil {
+		return nil
+	}
+
+	id := *transaction.WageredBonusID
+	if bonus, ok := bonuses[id]; ok {
+		return bonus
+	}
+
+	stored, ok := s.bonuses[id]
+	if !ok || stored.Status != model.BonusActive {
+		return nil
+	}
+
+	bonus := *stored
+	bonuses[id] = &bonus
+	return &bonus
+}
+
+// saveBonuses stores bonus copies changed by a transaction or a rollback.
+func (s *SeamlessService) saveBonuses(bonuses map[int]*model.Bonus) {
+	now := time.Now()
+	for id, bonus := range bonuses {
+		bonus.UpdatedAt = now
+		*s.bonuses[id] = *bonus
+	}
+}
@@ -0,0 +1,198 @@
+package memory
+
+import (
+	"context"
+	"seamless-api-wrapper/internal/model"
+	"seamless-api-wrapper/internal/service"
+	"sort"
+	"time"
+)
+
+func (s *SeamlessService) GrantFreeRounds(_ context.Context, playerName, currencyCode string, freeRound *model.FreeRound) error {
+	if err := service.CheckGrant(freeRound); err != nil {
+		return err
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	balance, err := s.balance(playerName, currencyCode)
+	if err != nil {
+		return err
+	}
+
+	if s.freeRound(balance.ID, freeRound.BonusID) != nil {
+		return service.ErrFreeRoundsExists
+	}
+
+	now := time.Now()
+
+	s.nextFreeRoundID++
+	freeRound.ID = s.nextFreeRoundID
+	freeRound.BalanceID = balance.ID
+	freeRound.PlayerName = playerName
+	freeRound.GameIDs = append([]string(nil), freeRound.GameIDs...)
+	freeRound.RoundsLeft = freeRound.Rounds
+	freeRound.Status = model.FreeRoundActive
+	freeRound.CreatedAt = now
+	freeRound.UpdatedAt = now
+	freeRound.Currency = balance.Currency
+
+	stored := *freeRound
+	s.freeRounds[stored.ID] = &stored
+
+	return nil
+}
+
+func (s *SeamlessService) CancelFreeRounds(_ context.Context, playerName, currencyCode, bonusID string) (*model.FreeRound, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	for _, balance := range s.balances[playerName] {
+		if balance.Currency.Code != currencyCode {
+			continue
+		}
+
+		freeRound := s.freeRound(balance.ID, bonusID)
+		if freeRound == nil {
+			break
+		}
+
+		expire(freeRound, time.Now())
+		if freeRound.Status == model.FreeRoundActive {
+			freeRound.Status = model.FreeRoundCancelled
+			freeRound.UpdatedAt = time.Now()
+		}
+
+		result := *freeRound
+		return &result, nil
+	}
+
+	return nil, service.ErrFreeRoundsNotFound
+}
+
+func (s *SeamlessService) FreeRounds(_ context.Context, playerName string) ([]model.FreeRound, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	now := time.Now()
+
+	var freeRounds []model.FreeRound
+	for _, freeRound := range s.freeRounds {
+		if freeRound.PlayerName == playerName {
+			expire(freeRound, now)
+			freeRounds = append(freeRounds, *freeRound)
+		}
+	}
+	sort.Slice(freeRounds, func(i, j int) bool { return freeRounds[i].ID < freeRounds[j].ID })
+
+	return freeRounds, nil
+}
+
+func (s *SeamlessService) EditFreeRounds(_ context.Context, playerName, currencyCode, bonusID string, edit *model.FreeRoundEdit) (*model.FreeRound, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	for _, balance := range s.balances[playerName] {
+		if balance.Currency.Code != currencyCode {
+			continue
+		}
+
+		freeRound := s.freeRound(balance.ID, bonusID)
+		if freeRound == nil {
+			break
+		}
+
+		now := time.Now()
+		expire(freeRound, now)
+
+		edited := *freeRound
+		if err := service.EditFreeRound(&edited, edit, now); err != nil {
+			return nil, err
+		}
+		*freeRound = edited
+
+		return &edited, nil
+	}
+
+	return nil, service.ErrFreeRoundsNotFound
+}
+
+// freeRound finds the campaign granted to the wallet under bonusID.
+func (s *SeamlessService) freeRound(balanceID int, bonusID string) *model.FreeRound {
+	for _, freeRound := range s.freeRounds {
+		if freeRound.BalanceID == balanceID && freeRound.BonusID == bonusID {
+			return freeRound
+		}
+	}
+	return nil
+}
+
+// chargeFreeRounds checks the free rounds a transaction charges, they are
+// taken off once the transaction is stored.
+func (s *SeamlessService) chargeFreeRounds(balanceID int, transaction *model.Transaction, now time.Time) (*model.FreeRound, error) {
+	if transaction.BonusId == nil {
+		return nil, service.ErrFreeRoundsNotFound
+	}
+
+	freeRound := s.freeRound(balanceID, *transaction.BonusId)
+	if freeRound == nil {
+		return nil, service.ErrFreeRoundsNotFound
+	}
+
+	charged := *freeRound
+	if err := service.ChargeFreeRounds(&charged, transaction, now); err != nil {
+		return nil, err
+	}
+
+	return &charged, nil
+}
+
+// refundFreeRounds gives the rounds a rolled back transaction charged back to
+// its campaign.
+func (s *SeamlessService) refundFreeRounds(transaction *model.Transaction) {
+	if transaction.ChargeFreeRounds == nil || transaction.BonusId == nil {
+		return
+	}
+
+	if freeRound := s.freeRound(transaction.BalanceID, *transaction.BonusId); freeRound != nil {
+		service.RefundFreeRounds(freeRound, transaction, time.Now())
+	}
+}
+
+// result copies the wallet with the money left in its active bonuses and the
+// rounds left in its active campaigns.
+func (s *SeamlessService) result(balance *model.Balance) *model.Balance {
+	now := time.Now()
+
+	result := *balance
+	result.BonusAmount = 0
+	for _, bonus := range s.bonuses {
+		if bonus.BalanceID == balance.ID && bonus.Status == model.BonusActive {
+			result.BonusAmount += bonus.Amount
+		}
+	}
+
+	result.FreeRoundLeft = nil
+	for _, freeRound := range s.freeRounds {
+		if freeRound.BalanceID != balance.ID || freeRound.Status != model.FreeRoundActive || freeRound.Expired(now) {
+			continue
+		}
+
+		freeRoundLeft := freeRound.RoundsLeft
+		if result.FreeRoundLeft != nil {
+			freeRoundLeft += *result.FreeRoundLeft
+		}
+		result.FreeRoundLeft = &freeRoundLeft
+	}
+
+	return &result
+}
+
+// expire marks an active campaign past its expiry as expired.
+func expire(freeRound *model.FreeRound, now time.Time) {
+	if freeRound.Status == model.FreeRoundActive && freeRound.Expired(now) {
+		freeRound.Status = model.FreeRoundExpired
+		freeRound.UpdatedAt = now
+	}
+}
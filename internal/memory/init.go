@@ -0,0 +1,21 @@
+package memory
+
+import (
+	"seamless-api-wrapper/internal/config"
+)
+
+// Init creates the in-memory storage with the wallets listed in conf.
+func Init(conf *config.Memory) *SeamlessService {
+	s := NewSeamlessService()
+
+	for _, balance := range conf.Balances {
+		var gameID *string
+		if balance.GameID != "" {
+			gameID = &balance.GameID
+		}
+
+		s.AddBalance(balance.PlayerName, balance.Currency, balance.Amount, gameID)
+	}
+
+	return s
+}
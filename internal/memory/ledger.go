@@ -0,0 +1,127 @@
+package memory
+
+import (
+	"context"
+	"seamless-api-wrapper/internal/model"
+	"seamless-api-wrapper/internal/service"
+	"time"
+)
+
+func (s *SeamlessService) LedgerEntries(_ context.Context, playerName, currencyCode string) ([]model.LedgerEntry, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	var entries []model.LedgerEntry
+	for _, entry := range s.ledger {
+		balance := s.wallets[entry.BalanceID]
+		if balance.PlayerName == playerName && balance.Currency.Code == currencyCode {
+			entries = append(entries, entry)
+		}
+	}
+
+	return entries, nil
+}
+
+func (s *SeamlessService) RebuildBalances(ctx context.Context) (int, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	cash := make(map[int]int64)
+	bonusAmounts := make(map[int]int64)
+	for _, entry := range s.ledger {
+		switch entry.Account {
+		case model.AccountCash:
+			cash[entry.BalanceID] += entry.Amount
+		case model.AccountBonus:
+			bonusAmounts[*entry.BonusID] += entry.Amount
+		}
+	}
+
+	now := time.Now()
+
+	corrected := 0
+	for id, balance := range s.wallets {
+		if balance.Amount != cash[id] {
+			s.recordChange(model.BalanceChange{
+				BalanceID:    id,
+				Cause:        model.CauseRebuild,
+				Actor:        service.Actor(ctx),
+				AmountBefore: balance.Amount,
+				AmountAfter:  cash[id],
+				Delta:        cash[id] - balance.Amount,
+				CreatedAt:    now,
+			})
+			balance.Amount = cash[id]
+			corrected++
+		}
+	}
+
+	for id, bonus := range s.bonuses {
+		if bonus.Amount != bonusAmounts[id] {
+			bonus.Amount = bonusAmounts[id]
+			corrected++
+		}
+	}
+
+	return corrected, nil
+}
+
+// post appends a posting built by the service package, those always
+// balance, moves the cash of its wallet and records the change.
+func (s *SeamlessService) post(ctx context.Context, cause model.Cause, entries []model.LedgerEntry, now time.Time) {
+	if len(entries) == 0 {
+		return
+	}
+
+	balance := s.wallets[entries[0].BalanceID]
+	delta := service.CashDelta(entries)
+
+	s.recordChange(model.BalanceChange{
+		BalanceID:     balance.ID,
+		TransactionID: entries[0].TransactionID,
+		Cause:         cause,
+		Actor:         service.Actor(ctx),
+		AmountBefore:  balance.Amount,
+		AmountAfter:   balance.Amount + delta,
+		Delta:         delta,
+		BonusDelta:    service.BonusDelta(entries),
+		CreatedAt:     now,
+	})
+	balance.Amount += delta
+
+	s.nextPostingID++
+	for _, entry := range entries {
+		s.nextEntryID++
+		entry.ID = s.nextEntryID
+		entry.PostingID = s.nextPostingID
+		entry.CreatedAt = now
+		s.ledger = append(s.ledger, entry)
+	}
+}
+
+func (s *SeamlessService) BalanceHistory(_ context.Context, playerName, currencyCode string, from, to *time.Time) ([]model.BalanceChange, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	var history []model.BalanceChange
+	for _, change := range s.history {
+		balance := s.wallets[change.BalanceID]
+		if balance.PlayerName != playerName || balance.Currency.Code != currencyCode {
+			continue
+		}
+
+		if (from != nil && change.CreatedAt.Before(*from)) || (to != nil && !change.CreatedAt.Before(*to)) {
+			continue
+		}
+
+		history = append(history, change)
+	}
+
+	return history, nil
+}
+
+func (s *SeamlessService) recordChange(change model.BalanceChange) {
+	s.nextChangeID++
+	change.ID = s.nextChangeID
+	s.history = append(s.history, change)
+}
@@ -0,0 +1,158 @@
+package memory
+
+import (
+	"context"
+	"seamless-api-wrapper/internal/model"
+	"seamless-api-wrapper/internal/service"
+	"sort"
+	"time"
+)
+
+type limitKey struct {
+	balanceID int
+	limitType model.LimitType
+	window    model.LimitWindow
+}
+
+func (s *SeamlessService) SetLimit(_ context.Context, playerName, currencyCode string, limit *model.Limit) error {
+	if err := service.CheckLimit(limit); err != nil {
+		return err
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	balance, err := s.balance(playerName, currencyCode)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+
+	key := limitKey{balance.ID, limit.Type, limit.Window}
+	if existing, ok := s.limits[key]; ok {
+		limit.ID = existing.ID
+		limit.CreatedAt = existing.CreatedAt
+	} else {
+		s.nextLimitID++
+		limit.ID = s.nextLimitID
+		limit.CreatedAt = now
+	}
+
+	limit.BalanceID = balance.ID
+	limit.PlayerName = playerName
+	limit.UpdatedAt = now
+	limit.Currency = balance.Currency
+
+	stored := *limit
+	s.limits[key] = &stored
+
+	return nil
+}
+
+func (s *SeamlessService) RemoveLimit(_ context.Context, playerName, currencyCode string, limitType model.LimitType, window model.LimitWindow) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	for _, balance := range s.balances[playerName] {
+		if balance.Currency.Code == currencyCode {
+			delete(s.limits, limitKey{balance.ID, limitType, window})
+		}
+	}
+
+	return nil
+}
+
+func (s *SeamlessService) Limits(_ context.Context, playerName string) ([]model.Limit, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	var limits []model.Limit
+	for _, limit := range s.limits {
+		if limit.PlayerName == playerName {
+			limits = append(limits, *limit)
+		}
+	}
+	sort.Slice(limits, func(i, j int) bool { return limits[i].ID < limits[j].ID })
+
+	return limits, nil
+}
+
+// checkLimits reports the first limit of the wallet the transaction would
+// exceed.
+func (s *SeamlessService) checkLimits(balance *model.Balance, transaction *model.Transaction, now time.Time) error {
+	var limits []*model.Limit
+	for key, limit := range s.limits {
+		if key.balanceID == balance.ID {
+			limits = append(limits, limit)
+		}
+	}
+	sort.Slice(limits, func(i, j int) bool { return limits[i].ID < limits[j].ID })
+
+	for _, limit := range limits {
+		if err := service.CheckLimitUsage(limit, s.usage(balance.ID, limit, transaction, now), transaction, now); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *SeamlessService) usage(balanceID int, limit *model.Limit, transaction *model.Transaction, now time.Time) service.Usage {
+	var usage service.Usage
+
+	start, _ := service.Window(limit.Window, now)
+	for _, stored := range s.transactions {
+		if stored.BalanceID != balanceID || stored.Status != model.StatusCommitted {
+			continue
+		}
+
+		if limit.Type == model.LimitSession {
+			if transaction.SessionId == nil || stored.SessionId == nil || *stored.SessionId != *transaction.SessionId {
+				continue
+			}
+
+			if usage.SessionStart == nil || stored.CreatedAt.Before(*usage.SessionStart) {
+				createdAt := stored.CreatedAt
+				usage.SessionStart = &createdAt
+			}
+			continue
+		}
+
+		if !stored.CreatedAt.Before(start) {
+			usage.Withdraw += stored.Withdraw
+			usage.Deposit += stored.Deposit
+		}
+	}
+
+	return usage
+}
+
+// checkDepositLimits reports the first deposit limit of the wallet a manual
+// deposit of amount would exceed.
+func (s *SeamlessService) checkDepositLimits(balance *model.Balance, amount int64, now time.Time) error {
+	var limits []*model.Limit
+	for key, limit := range s.limits {
+		if key.balanceID == balance.ID && key.limitType == model.LimitDeposit {
+			limits = append(limits, limit)
+		}
+	}
+	sort.Slice(limits, func(i, j int) bool { return limits[i].ID < limits[j].ID })
+
+	for _, limit := range limits {
+		start, _ := service.Window(limit.Window, now)
+
+		var used int64
+		for _, adjustment := range s.adjustments {
+			if adjustment.BalanceID == balance.ID && adjustment.Reason == model.AdjustmentDeposit && adjustment.Amount > 0 && !adjustment.CreatedAt.Before(start) {
+				used += adjustment.Amount
+			}
+		}
+
+		if err := service.CheckDeposit(limit, used, amount, now); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,81 @@
+package memory
+
+import (
+	"context"
+	"seamless-api-wrapper/internal/model"
+	"seamless-api-wrapper/internal/service"
+	"time"
+)
+
+func (s *SeamlessService) SetPlayerStatus(ctx context.Context, player *model.Player) error {
+	now := time.Now()
+
+	if err := service.CheckPlayer(player, now); err != nil {
+		return err
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if len(s.balances[player.PlayerName]) == 0 {
+		return service.ErrPlayerNotFound
+	}
+
+	current := s.player(player.PlayerName)
+	if err := service.CheckStatusChange(current, player, now); err != nil {
+		return err
+	}
+
+	player.UpdatedAt = now
+
+	stored := *player
+	s.players[player.PlayerName] = &stored
+
+	s.nextStatusChangeID++
+	s.statusHistory = append(s.statusHistory, model.PlayerStatusChange{
+		ID:         s.nextStatusChangeID,
+		PlayerName: player.PlayerName,
+		FromStatus: current.Current(now),
+		ToStatus:   player.Status,
+		Reason:     player.Reason,
+		Until:      player.Until,
+		Actor:      service.Actor(ctx),
+		CreatedAt:  now,
+	})
+
+	return nil
+}
+
+func (s *SeamlessService) Player(_ context.Context, playerName string) (*model.Player, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if len(s.balances[playerName]) == 0 {
+		return nil, service.ErrPlayerNotFound
+	}
+
+	player := *s.player(playerName)
+	return &player, nil
+}
+
+func (s *SeamlessService) PlayerStatusHistory(_ context.Context, playerName string) ([]model.PlayerStatusChange, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	var history []model.PlayerStatusChange
+	for _, change := range s.statusHistory {
+		if change.PlayerName == playerName {
+			history = append(history, change)
+		}
+	}
+
+	return history, nil
+}
+
+// player returns the stored status of a player, active when there is none.
+func (s *SeamlessService) player(playerName string) *model.Player {
+	if player, ok := s.players[playerName]; ok {
+		return player
+	}
+	return &model.Player{PlayerName: playerName, Status: model.PlayerActive}
+}
@@ -0,0 +1,123 @@
+package memory
+
+import (
+	"context"
+	"seamless-api-wrapper/internal/model"
+	"seamless-api-wrapper/internal/service"
+	"sort"
+	"time"
+)
+
+type roundKey struct {
+	balanceID    int
+	gameRoundRef string
+}
+
+func (s *SeamlessService) Round(_ context.Context, playerName, currencyCode, gameRoundRef string) (*model.Round, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	for _, balance := range s.balances[playerName] {
+		if balance.Currency.Code != currencyCode {
+			continue
+		}
+
+		if round, ok := s.rounds[roundKey{balance.ID, gameRoundRef}]; ok {
+			result := *round
+			return &result, nil
+		}
+	}
+
+	return nil, service.ErrRoundNotFound
+}
+
+func (s *SeamlessService) OpenRounds(_ context.Context, playerName string) ([]model.Round, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	var rounds []model.Round
+	for _, round := range s.rounds {
+		if round.PlayerName == playerName && round.Status == model.RoundOpen {
+			rounds = append(rounds, *round)
+		}
+	}
+	sort.Slice(rounds, func(i, j int) bool { return rounds[i].ID < rounds[j].ID })
+
+	return rounds, nil
+}
+
+func (s *SeamlessService) CloseInactiveRounds(_ context.Context, before time.Time) (int, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	closed := 0
+	for _, round := range s.rounds {
+		if round.Status == model.RoundOpen && round.UpdatedAt.Before(before) {
+			closeRound(round, model.RoundClosed, "inactivity")
+			closed++
+		}
+	}
+
+	return closed, nil
+}
+
+// recordRound adds a committed transaction to its round, opening the round
+// on its first transaction.
+func (s *SeamlessService) recordRound(balance *model.Balance, transaction *model.Transaction) {
+	if transaction.GameRoundRef == nil {
+		return
+	}
+
+	now := time.Now()
+
+	key := roundKey{balance.ID, *transaction.GameRoundRef}
+	round, ok := s.rounds[key]
+	if !ok {
+		s.nextRoundID++
+		round = &model.Round{
+			ID:           s.nextRoundID,
+			BalanceID:    balance.ID,
+			PlayerName:   balance.PlayerName,
+			GameRoundRef: *transaction.GameRoundRef,
+			GameID:       transaction.GameID,
+			Status:       model.RoundOpen,
+			OpenedAt:     now,
+			Currency:     balance.Currency,
+		}
+		s.rounds[key] = round
+	}
+
+	round.TotalBet += transaction.Withdraw
+	round.TotalWin += transaction.Deposit
+	round.Transactions++
+	round.UpdatedAt = now
+
+	if transaction.CloseRound && round.Status == model.RoundOpen {
+		closeRound(round, model.RoundClosed, "withdrawAndDeposit")
+	}
+}
+
+// unrecordRound takes a rolled back transaction out of its round totals.
+func (s *SeamlessService) unrecordRound(transaction *model.Transaction) {
+	if transaction.GameRoundRef == nil {
+		return
+	}
+
+	round, ok := s.rounds[roundKey{transaction.BalanceID, *transaction.GameRoundRef}]
+	if !ok {
+		return
+	}
+
+	round.TotalBet -= transaction.Withdraw
+	round.TotalWin -= transaction.Deposit
+	round.Transactions--
+	round.UpdatedAt = time.Now()
+}
+
+func closeRound(round *model.Round, status model.RoundStatus, reason string) {
+	now := time.Now()
+
+	round.Status = status
+	round.CloseReason = &reason
+	round.ClosedAt = &now
+}
@@ -0,0 +1,482 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"seamless-api-wrapper/internal/model"
+	"seamless-api-wrapper/internal/service"
+	"sort"
+	"sync"
+	"time"
+)
+
+const defaultExponent = 2
+
+// SeamlessService keeps wallets in memory with the same rules as
+// postgres.SeamlessService. It is meant for tests and local development.
+type SeamlessService struct {
+	lock         sync.Mutex
+	currencies   map[string]*model.Currency
+	balances     map[string][]*model.Balance
+	wallets      map[int]*model.Balance
+	transactions map[string]*model.Transaction
+	transitions  map[int][]model.Transition
+	rounds       map[roundKey]*model.Round
+	freeRounds   map[int]*model.FreeRound
+	bonuses      map[int]*model.Bonus
+	limits       map[limitKey]*model.Limit
+	players      map[string]*model.Player
+	ledger       []model.LedgerEntry
+	history      []model.BalanceChange
+	spendOrder   service.SpendOrder
+	nextID       int
+	nextTxID     int
+
+	nextTransitionID int
+	nextRoundID      int
+	nextFreeRoundID  int
+	nextBonusID      int
+	nextLimitID      int
+
+	statusHistory      []model.PlayerStatusChange
+	nextStatusChangeID int
+	nextPostingID      int
+	nextEntryID        int
+	nextChangeID       int
+
+	adjustments      []model.Adjustment
+	nextAdjustmentID int
+}
+
+func NewSeamlessService() *SeamlessService {
+	return &SeamlessService{
+		currencies:   make(map[string]*model.Currency),
+		balances:     make(map[string][]*model.Balance),
+		wallets:      make(map[int]*model.Balance),
+		transactions: make(map[string]*model.Transaction),
+		transitions:  make(map[int][]model.Transition),
+		rounds:       make(map[roundKey]*model.Round),
+		freeRounds:   make(map[int]*model.FreeRound),
+		bonuses:      make(map[int]*model.Bonus),
+		limits:       make(map[limitKey]*model.Limit),
+		players:      make(map[string]*model.Player),
+		spendOrder:   service.CashFirst,
+	}
+}
+
+func (s *SeamlessService) CreateCurrency(_ context.Context, code string, exponent int) (*model.Currency, error) {
+	if code == "" || exponent < 0 || exponent > service.MaxExponent {
+		return nil, service.ErrIllegalCurrencyCode
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	currency := *s.currency(code, exponent)
+	return &currency, nil
+}
+
+func (s *SeamlessService) Currencies(_ context.Context) ([]model.Currency, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	currencies := make([]model.Currency, 0, len(s.currencies))
+	for _, currency := range s.currencies {
+		currencies = append(currencies, *currency)
+	}
+	sort.Slice(currencies, func(i, j int) bool { return currencies[i].Code < currencies[j].Code })
+
+	return currencies, nil
+}
+
+// AddBalance creates a wallet, registering its currency with two decimal
+// digits when it is new.
+func (s *SeamlessService) AddBalance(playerName, currencyCode string, amount int64, gameID *string) *model.Balance {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	balance := s.addBalance(playerName, s.currency(currencyCode, defaultExponent))
+	balance.GameID = gameID
+	s.post(context.Background(), model.CauseOpening, service.OpeningEntries(balance, amount), time.Now())
+
+	return s.result(balance)
+}
+
+func (s *SeamlessService) Balance(_ context.Context, playerName, currencyCode string) (*model.Balance, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	balance, err := s.balance(playerName, currencyCode)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.result(balance), nil
+}
+
+func (s *SeamlessService) Transaction(ctx context.Context, playerName, currencyCode string, transaction *model.Transaction) (*model.Balance, error) {
+	if transaction.Deposit < 0 {
+		return nil, service.ErrNegativeDepositCode
+	}
+
+	if transaction.Withdraw < 0 {
+		return nil, service.ErrNegativeWithdrawalCode
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	existing, ok := s.transactions[transaction.TransactionRef]
+	if ok && existing.Status != model.StatusFailed {
+		return s.replay(existing, playerName, currencyCode, transaction)
+	}
+
+	balance, err := s.openBalance(playerName, currencyCode)
+	if err != nil {
+		return nil, err
+	}
+
+	status, reason := model.StatusCommitted, "withdrawAndDeposit"
+
+	now := time.Now()
+
+	result := *balance
+	bonus := s.activeBonus(balance.ID, transaction)
+
+	err = service.CheckPlayerBet(s.player(playerName), transaction, now)
+	if err == nil {
+		err = s.checkLimits(balance, transaction, now)
+	}
+	if err == nil {
+		err = service.Apply(&result, bonus, transaction, s.spendOrder, now)
+	}
+
+	var freeRound *model.FreeRound
+	if err == nil && transaction.ChargeFreeRounds != nil {
+		freeRound, err = s.chargeFreeRounds(balance.ID, transaction, now)
+	}
+
+	failure := err
+	if failure != nil {
+		status, reason = model.StatusFailed, failure.Error()
+	}
+
+	transaction.BalanceAfter = nil
+	transaction.BonusAmountAfter = nil
+	transaction.FreeRoundLeftAfter = nil
+
+	transaction.BalanceID = balance.ID
+	transaction.CreatedAt = now
+	transaction.UpdatedAt = now
+
+	if ok {
+		transaction.ID = existing.ID
+		transaction.CreatedAt = existing.CreatedAt
+		transaction.Status = existing.Status
+	} else {
+		s.nextTxID++
+		transaction.ID = s.nextTxID
+	}
+
+	if err := s.setStatus(transaction, status, reason); err != nil {
+		return nil, err
+	}
+
+	if failure != nil {
+		transaction.SpinDetails = nil
+
+		stored := *transaction
+		s.transactions[transaction.TransactionRef] = &stored
+		return nil, failure
+	}
+
+	if transaction.SpinDetails != nil {
+		transaction.SpinDetails.TransactionID = transaction.ID
+	}
+
+	s.post(ctx, model.CauseWithdrawAndDeposit, service.TransactionEntries(balance, transaction), now)
+
+	if bonus != nil {
+		*s.bonuses[bonus.ID] = *bonus
+	}
+	if freeRound != nil {
+		*s.freeRounds[freeRound.ID] = *freeRound
+	}
+
+	after := s.result(balance)
+
+	balanceAfter, bonusAmountAfter := after.Amount, after.BonusAmount
+	transaction.BalanceAfter = &balanceAfter
+	transaction.BonusAmountAfter = &bonusAmountAfter
+	transaction.FreeRoundLeftAfter = after.FreeRoundLeft
+
+	stored := *transaction
+	s.transactions[transaction.TransactionRef] = &stored
+
+	s.recordRound(balance, transaction)
+
+	return after, nil
+}
+
+// replay answers a repeated transactionRef from the stored transaction.
+func (s *SeamlessService) replay(existing *model.Transaction, playerName, currencyCode string, transaction *model.Transaction) (*model.Balance, error) {
+	if existing.Status.IsRollback() {
+		*transaction = *existing
+		return nil, service.ErrTransactionRollback
+	}
+
+	wallet := s.wallets[existing.BalanceID]
+	if wallet.PlayerName != playerName || wallet.Currency.Code != currencyCode || !service.SameTransaction(existing, transaction) {
+		return nil, service.ErrTransactionConflict
+	}
+
+	*transaction = *existing
+
+	if existing.BalanceAfter == nil {
+		return nil, fmt.Errorf("%w: transaction %s", service.ErrSnapshotMissing, transaction.TransactionRef)
+	}
+
+	result := *wallet
+	result.Amount = *existing.BalanceAfter
+	result.BonusAmount = 0
+	if existing.BonusAmountAfter != nil {
+		result.BonusAmount = *existing.BonusAmountAfter
+	}
+	result.FreeRoundLeft = existing.FreeRoundLeftAfter
+	return &result, nil
+}
+
+// Rollback reverses the transaction on its own wallet. A rollback that
+// arrives first is stored as a tombstone against the player's oldest wallet.
+func (s *SeamlessService) Rollback(ctx context.Context, playerName string, transaction *model.Transaction) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	existing, ok := s.transactions[transaction.TransactionRef]
+	if !ok {
+		balances := s.balances[playerName]
+		if len(balances) == 0 {
+			return service.ErrPlayerNotFound
+		}
+
+		s.nextTxID++
+		transaction.ID = s.nextTxID
+		transaction.BalanceID = balances[0].ID
+		transaction.Withdraw = 0
+		transaction.Deposit = 0
+		transaction.ChargeFreeRounds = nil
+		transaction.Status = ""
+
+		if err := s.setStatus(transaction, model.StatusTombstone, "rollbackTransaction before withdrawAndDeposit"); err != nil {
+			return err
+		}
+
+		stored := *transaction
+		s.transactions[transaction.TransactionRef] = &stored
+		return nil
+	}
+
+	balance := s.wallets[existing.BalanceID]
+	if balance.PlayerName != playerName {
+		return fmt.Errorf("%w: transaction %s belongs to another player", service.ErrForeignTransaction, transaction.TransactionRef)
+	}
+
+	switch existing.Status {
+	case model.StatusFailed:
+		if err := s.setStatus(existing, model.StatusTombstone, "rollbackTransaction"); err != nil {
+			return err
+		}
+	case model.StatusCommitted:
+		result := *balance
+		bonuses := make(map[int]*model.Bonus)
+		bonus := s.wageredBonus(existing, bonuses)
+		if err := service.Reverse(&result, bonus, existing); err != nil {
+			return err
+		}
+
+		if err := s.setStatus(existing, model.StatusRolledBack, "rollbackTransaction"); err != nil {
+			return err
+		}
+
+		s.post(ctx, model.CauseRollbackTransaction, service.RollbackEntries(balance, bonus, existing), time.Now())
+
+		*balance = result
+		s.saveBonuses(bonuses)
+		s.refundFreeRounds(existing)
+		s.unrecordRound(existing)
+	}
+
+	*transaction = *existing
+
+	return nil
+}
+
+func (s *SeamlessService) RollbackRound(ctx context.Context, playerName, currencyCode, gameRoundRef string) (*model.Balance, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	balance, err := s.balance(playerName, currencyCode)
+	if err != nil {
+		return nil, err
+	}
+
+	var transactions []*model.Transaction
+	for _, transaction := range s.transactions {
+		if transaction.BalanceID != balance.ID || transaction.GameRoundRef == nil || *transaction.GameRoundRef != gameRoundRef {
+			continue
+		}
+
+		if transaction.Status == model.StatusCommitted || transaction.Status == model.StatusFailed {
+			transactions = append(transactions, transaction)
+		}
+	}
+	sort.Slice(transactions, func(i, j int) bool { return transactions[i].ID < transactions[j].ID })
+
+	// Changes are applied to a copy first, so a failing leg leaves the
+	// wallet untouched.
+	result := *balance
+	bonuses := make(map[int]*model.Bonus)
+	var postings [][]model.LedgerEntry
+	for _, transaction := range transactions {
+		if transaction.Status == model.StatusCommitted {
+			bonus := s.wageredBonus(transaction, bonuses)
+			if err := service.Reverse(&result, bonus, transaction); err != nil {
+				return nil, err
+			}
+
+			postings = append(postings, service.RollbackEntries(balance, bonus, transaction))
+		}
+	}
+
+	for _, transaction := range transactions {
+		status := model.StatusRolledBack
+		if transaction.Status == model.StatusFailed {
+			status = model.StatusTombstone
+		}
+
+		if err := s.setStatus(transaction, status, "rollbackRound"); err != nil {
+			return nil, err
+		}
+
+		if status == model.StatusRolledBack {
+			s.refundFreeRounds(transaction)
+		}
+	}
+
+	now := time.Now()
+	for _, entries := range postings {
+		s.post(ctx, model.CauseRollbackRound, entries, now)
+	}
+
+	*balance = result
+	s.saveBonuses(bonuses)
+
+	if round, ok := s.rounds[roundKey{balance.ID, gameRoundRef}]; ok && len(transactions) > 0 {
+		round.TotalBet = 0
+		round.TotalWin = 0
+		round.Transactions = 0
+		round.UpdatedAt = time.Now()
+		closeRound(round, model.RoundRolledBack, "rollbackRound")
+	}
+
+	return s.result(balance), nil
+}
+
+func (s *SeamlessService) Transitions(_ context.Context, transactionRef string) ([]model.Transition, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	transaction, ok := s.transactions[transactionRef]
+	if !ok {
+		return nil, nil
+	}
+
+	return append([]model.Transition(nil), s.transitions[transaction.ID]...), nil
+}
+
+// setStatus moves the transaction to status if the move is allowed and
+// records the transition.
+func (s *SeamlessService) setStatus(transaction *model.Transaction, status model.TransactionStatus, reason string) error {
+	if !transaction.Status.CanTransition(status) {
+		return fmt.Errorf("%w: transaction %s from %s to %s",
+			service.ErrInvalidTransition, transaction.TransactionRef, transaction.Status, status)
+	}
+
+	now := time.Now()
+
+	s.nextTransitionID++
+	s.transitions[transaction.ID] = append(s.transitions[transaction.ID], model.Transition{
+		ID:            s.nextTransitionID,
+		TransactionID: transaction.ID,
+		FromStatus:    transaction.Status,
+		ToStatus:      status,
+		Reason:        reason,
+		CreatedAt:     now,
+	})
+
+	transaction.Status = status
+	transaction.UpdatedAt = now
+
+	return nil
+}
+
+func (s *SeamlessService) currency(code string, exponent int) *model.Currency {
+	currency, ok := s.currencies[code]
+	if !ok {
+		currency = &model.Currency{ID: len(s.currencies) + 1, Code: code, Exponent: exponent}
+		s.currencies[code] = currency
+	}
+	return currency
+}
+
+// addBalance opens an empty wallet, its money is posted to the ledger.
+func (s *SeamlessService) addBalance(playerName string, currency *model.Currency) *model.Balance {
+	now := time.Now()
+
+	s.nextID++
+	balance := &model.Balance{
+		ID:         s.nextID,
+		PlayerName: playerName,
+		CurrencyID: currency.ID,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+		Currency:   *currency,
+	}
+	s.balances[playerName] = append(s.balances[playerName], balance)
+	s.wallets[balance.ID] = balance
+
+	return balance
+}
+
+// balance finds the wallet in currencyCode.
+func (s *SeamlessService) balance(playerName, currencyCode string) (*model.Balance, error) {
+	currency, ok := s.currencies[currencyCode]
+	if !ok {
+		return nil, service.ErrIllegalCurrencyCode
+	}
+
+	balances := s.balances[playerName]
+	if len(balances) == 0 {
+		return nil, service.ErrNotEnoughMoneyCode
+	}
+
+	for _, balance := range balances {
+		if balance.CurrencyID == currency.ID {
+			return balance, nil
+		}
+	}
+
+	return nil, service.ErrWalletNotFound
+}
+
+// openBalance is balance that opens an empty wallet in a registered currency
+// for a known player.
+func (s *SeamlessService) openBalance(playerName, currencyCode string) (*model.Balance, error) {
+	balance, err := s.balance(playerName, currencyCode)
+	if errors.Is(err, service.ErrWalletNotFound) {
+		return s.addBalance(playerName, s.currencies[currencyCode]), nil
+	}
+
+	return balance, err
+}
@@ -0,0 +1,27 @@
+package memory
+
+import (
+	"seamless-api-wrapper/internal/service/servicetest"
+	"testing"
+)
+
+func TestSeamlessServiceContract(t *testing.T) {
+	servicetest.Run(t, func(t *testing.T) *servicetest.Backend {
+		s := NewSeamlessService()
+		return &servicetest.Backend{
+			Service:       s,
+			Rounds:        s,
+			FreeRounds:    s,
+			Bonuses:       s,
+			Limits:        s,
+			Players:       s,
+			Ledger:        s,
+			Admin:         s,
+			SetSpendOrder: s.SetSpendOrder,
+			AddBalance: func(playerName, currency string, amount int64) error {
+				s.AddBalance(playerName, currency, amount, nil)
+				return nil
+			},
+		}
+	})
+}
@@ -0,0 +1,74 @@
+package memory
+
+import (
+	"context"
+	"seamless-api-wrapper/internal/model"
+	"sort"
+)
+
+func (s *SeamlessService) TransactionHistory(_ context.Context, filter *model.TransactionFilter) ([]model.TransactionRecord, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	var records []model.TransactionRecord
+	for _, transaction := range s.transactions {
+		balance := s.wallets[transaction.BalanceID]
+		if balance.PlayerName != filter.PlayerName || !matches(transaction, balance, filter) {
+			continue
+		}
+
+		records = append(records, s.record(transaction))
+	}
+
+	sort.Slice(records, func(i, j int) bool { return records[i].ID > records[j].ID })
+
+	if filter.Limit > 0 && len(records) > filter.Limit {
+		records = records[:filter.Limit]
+	}
+
+	return records, nil
+}
+
+func matches(transaction *model.Transaction, balance *model.Balance, filter *model.TransactionFilter) bool {
+	switch {
+	case filter.Currency != nil && balance.Currency.Code != *filter.Currency:
+		return false
+	case filter.From != nil && transaction.CreatedAt.Before(*filter.From):
+		return false
+	case filter.To != nil && !transaction.CreatedAt.Before(*filter.To):
+		return false
+	case filter.GameID != nil && !equal(transaction.GameID, *filter.GameID):
+		return false
+	case filter.GameRoundRef != nil && !equal(transaction.GameRoundRef, *filter.GameRoundRef):
+		return false
+	case filter.BonusID != nil && !equal(transaction.BonusId, *filter.BonusID):
+		return false
+	case filter.Status != nil && transaction.Status != *filter.Status:
+		return false
+	case filter.BeforeID > 0 && transaction.ID >= filter.BeforeID:
+		return false
+	}
+
+	return true
+}
+
+func equal(value *string, expected string) bool {
+	return value != nil && *value == expected
+}
+
+// record copies a stored transaction with its wallet's owner and currency.
+func (s *SeamlessService) record(transaction *model.Transaction) model.TransactionRecord {
+	balance := s.wallets[transaction.BalanceID]
+
+	record := model.TransactionRecord{
+		Transaction: *transaction,
+		PlayerName:  balance.PlayerName,
+		Currency:    balance.Currency.Code,
+	}
+	if transaction.SpinDetails != nil {
+		spinDetails := *transaction.SpinDetails
+		record.SpinDetails = &spinDetails
+	}
+
+	return record
+}
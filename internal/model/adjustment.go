@@ -0,0 +1,37 @@
+package model
+
+import "time"
+
+// AdjustmentReason says why back-office staff changed a wallet by hand.
+type AdjustmentReason string
+
+const (
+	AdjustmentCorrection AdjustmentReason = "correction"
+	AdjustmentGoodwill   AdjustmentReason = "goodwill"
+	AdjustmentChargeback AdjustmentReason = "chargeback"
+	AdjustmentDeposit    AdjustmentReason = "manual_deposit"
+	AdjustmentWithdrawal AdjustmentReason = "manual_withdrawal"
+)
+
+// Known reports whether r is one of the reasons above.
+func (r AdjustmentReason) Known() bool {
+	switch r {
+	case AdjustmentCorrection, AdjustmentGoodwill, AdjustmentChargeback, AdjustmentDeposit, AdjustmentWithdrawal:
+		return true
+	}
+	return false
+}
+
+// Adjustment is a manual credit, a positive Amount, or debit of a wallet's
+// cash. Actor is who made it.
+type Adjustment struct {
+	ID         int
+	BalanceID  int              `db:"balance_id"`
+	PlayerName string           `db:"player_name"`
+	Amount     int64            `db:"amount"`
+	Reason     AdjustmentReason `db:"reason_code"`
+	Comment    string           `db:"comment"`
+	Actor      string           `db:"actor"`
+	CreatedAt  time.Time        `db:"created_at"`
+	Currency   Currency         `db:"currency"`
+}
@@ -2,21 +2,27 @@ package model
 
 import "time"
 
+// Balance is a player's wallet in one currency. Amount is the cash,
+// BonusAmount the money left in its active bonuses.
 type Balance struct {
 	ID                       int
 	PlayerName               string `db:"player_name"`
 	CurrencyID               int    `db:"currency_id"`
-	Amount                   int
+	Amount                   int64
 	GameID                   *string   `db:"game_id"`
 	LastSessionID            *string   `db:"last_session_id"`
 	LastSessionAlternativeID *string   `db:"last_session_alternative_id"`
 	FreeRoundLeft            *int      `db:"free_round_left"`
+	BonusAmount              int64     `db:"bonus_amount"`
 	CreatedAt                time.Time `db:"created_at"`
 	UpdatedAt                time.Time `db:"updated_at"`
 	Currency                 Currency  `db:"currency"`
 }
 
+// Currency amounts are stored in minor units, Exponent is the number of
+// decimal digits in the major unit (2 for EUR, 0 for JPY).
 type Currency struct {
-	ID   int
-	Code string
+	ID       int
+	Code     string
+	Exponent int
 }
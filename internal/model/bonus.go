@@ -0,0 +1,29 @@
+package model
+
+import "time"
+
+type BonusStatus string
+
+const (
+	BonusActive    BonusStatus = "active"
+	BonusConverted BonusStatus = "converted"
+)
+
+// Bonus is bonus money granted to one wallet under BonusID. Bets carrying the
+// BonusID count towards Wagering, once Wagered reaches it the bonus Amount is
+// converted to cash. Amounts are in minor units of the wallet currency.
+type Bonus struct {
+	ID          int
+	BalanceID   int         `db:"balance_id"`
+	PlayerName  string      `db:"player_name"`
+	BonusID     string      `db:"bonus_id"`
+	Granted     int64       `db:"granted"`
+	Amount      int64       `db:"amount"`
+	Wagering    int64       `db:"wagering"`
+	Wagered     int64       `db:"wagered"`
+	Status      BonusStatus `db:"status"`
+	CreatedAt   time.Time   `db:"created_at"`
+	UpdatedAt   time.Time   `db:"updated_at"`
+	ConvertedAt *time.Time  `db:"converted_at"`
+	Currency    Currency    `db:"currency"`
+}
@@ -0,0 +1,28 @@
+package model
+
+type Check string
+
+const (
+	// CheckCash compares balances.amount with the cash account.
+	CheckCash Check = "cash"
+	// CheckBonus compares bonuses.amount with the bonus account.
+	CheckBonus Check = "bonus"
+	// CheckTransactions compares the money the committed transactions of a
+	// wallet moved with their ledger postings.
+	CheckTransactions Check = "transactions"
+	// CheckFreeRounds compares the rounds left in a campaign with the rounds
+	// the committed transactions charged.
+	CheckFreeRounds Check = "free_rounds"
+)
+
+// Discrepancy is a stored value that differs from what it is recomputed
+// from. Ref is the bonusId of bonus and free round checks.
+type Discrepancy struct {
+	Check      Check  `db:"check_name"`
+	BalanceID  int    `db:"balance_id"`
+	PlayerName string `db:"player_name"`
+	Currency   string `db:"currency"`
+	Ref        string `db:"ref"`
+	Stored     int64  `db:"stored"`
+	Expected   int64  `db:"expected"`
+}
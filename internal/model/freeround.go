@@ -0,0 +1,60 @@
+package model
+
+import "time"
+
+type FreeRoundStatus string
+
+const (
+	FreeRoundActive    FreeRoundStatus = "active"
+	FreeRoundUsed      FreeRoundStatus = "used"
+	FreeRoundExpired   FreeRoundStatus = "expired"
+	FreeRoundCancelled FreeRoundStatus = "cancelled"
+)
+
+// FreeRound is a free round campaign granted to one wallet under BonusID.
+// GameIDs limits the games its rounds may be played in, empty allows every
+// game. BetValue is the bet of one round in minor units of the currency.
+type FreeRound struct {
+	ID         int
+	BalanceID  int             `db:"balance_id"`
+	PlayerName string          `db:"player_name"`
+	BonusID    string          `db:"bonus_id"`
+	GameIDs    []string        `db:"-"`
+	BetValue   int64           `db:"bet_value"`
+	Rounds     int             `db:"rounds"`
+	RoundsLeft int             `db:"rounds_left"`
+	Status     FreeRoundStatus `db:"status"`
+	ExpiresAt  *time.Time      `db:"expires_at"`
+	CreatedAt  time.Time       `db:"created_at"`
+	UpdatedAt  time.Time       `db:"updated_at"`
+	Currency   Currency        `db:"currency"`
+}
+
+// FreeRoundEdit changes a campaign, a nil field is kept.
+type FreeRoundEdit struct {
+	RoundsLeft *int
+	ExpiresAt  *time.Time
+}
+
+// Expired reports whether the campaign can no longer be played at now.
+func (f *FreeRound) Expired(now time.Time) bool {
+	return f.ExpiresAt != nil && !now.Before(*f.ExpiresAt)
+}
+
+// AllowsGame reports whether the rounds may be played in gameID.
+func (f *FreeRound) AllowsGame(gameID *string) bool {
+	if len(f.GameIDs) == 0 {
+		return true
+	}
+
+	if gameID == nil {
+		return false
+	}
+
+	for _, id := range f.GameIDs {
+		if id == *gameID {
+			return true
+		}
+	}
+	return false
+}
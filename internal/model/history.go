@@ -0,0 +1,34 @@
+package model
+
+import "time"
+
+// Cause is what changed a wallet, the API method or the tool that moved its
+// money.
+type Cause string
+
+const (
+	CauseWithdrawAndDeposit  Cause = "withdrawAndDeposit"
+	CauseRollbackTransaction Cause = "rollbackTransaction"
+	CauseRollbackRound       Cause = "rollbackRound"
+	CauseGrantBonus          Cause = "grantBonus"
+	CauseOpening             Cause = "opening"
+	CauseAdjustment          Cause = "adjustment"
+	CauseManualAdjustment    Cause = "manualAdjustment"
+	CauseRebuild             Cause = "rebuild"
+)
+
+// BalanceChange is one change of a wallet's cash. BonusDelta is how much the
+// same change moved the wallet's bonus money, TransactionID is set when a
+// transaction caused it.
+type BalanceChange struct {
+	ID            int
+	BalanceID     int       `db:"balance_id"`
+	TransactionID *int      `db:"transaction_id"`
+	Cause         Cause     `db:"cause"`
+	Actor         string    `db:"actor"`
+	AmountBefore  int64     `db:"amount_before"`
+	AmountAfter   int64     `db:"amount_after"`
+	Delta         int64     `db:"delta"`
+	BonusDelta    int64     `db:"bonus_delta"`
+	CreatedAt     time.Time `db:"created_at"`
+}
@@ -0,0 +1,43 @@
+package model
+
+import "time"
+
+// Account is a ledger account. Cash and bonus accounts belong to a wallet,
+// the house and cashier accounts exist once per currency.
+type Account string
+
+const (
+	AccountCash    Account = "cash"
+	AccountBonus   Account = "bonus"
+	AccountHouse   Account = "house"
+	AccountCashier Account = "cashier"
+)
+
+type EntryKind string
+
+const (
+	EntryBet             EntryKind = "bet"
+	EntryWin             EntryKind = "win"
+	EntryBonusConversion EntryKind = "bonus_conversion"
+	EntryRollback        EntryKind = "rollback"
+	EntryBonusGrant      EntryKind = "bonus_grant"
+	EntryOpening         EntryKind = "opening"
+	EntryAdjustment      EntryKind = "adjustment"
+	EntryManual          EntryKind = "manual"
+)
+
+// LedgerEntry is one leg of a posting, the entries of a posting sum to zero.
+// BalanceID is the wallet the posting moves money of, also on the house and
+// cashier legs. BonusID is set on bonus account entries.
+type LedgerEntry struct {
+	ID            int
+	PostingID     int       `db:"posting_id"`
+	BalanceID     int       `db:"balance_id"`
+	CurrencyID    int       `db:"currency_id"`
+	TransactionID *int      `db:"transaction_id"`
+	BonusID       *int      `db:"bonus_id"`
+	Account       Account   `db:"account"`
+	Kind          EntryKind `db:"kind"`
+	Amount        int64     `db:"amount"`
+	CreatedAt     time.Time `db:"created_at"`
+}
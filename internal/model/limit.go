@@ -0,0 +1,37 @@
+package model
+
+import "time"
+
+type LimitType string
+
+const (
+	LimitLoss    LimitType = "loss"
+	LimitWager   LimitType = "wager"
+	LimitDeposit LimitType = "deposit"
+	LimitSession LimitType = "session"
+)
+
+type LimitWindow string
+
+const (
+	WindowDaily   LimitWindow = "daily"
+	WindowWeekly  LimitWindow = "weekly"
+	WindowMonthly LimitWindow = "monthly"
+	WindowSession LimitWindow = "session"
+)
+
+// Limit is a responsible gambling limit of a player's wallet. Loss, wager
+// and deposit limits cap an amount in minor units over a daily, weekly or
+// monthly window. A session limit caps the length of a session in seconds
+// and uses the session window.
+type Limit struct {
+	ID         int
+	BalanceID  int         `db:"balance_id"`
+	PlayerName string      `db:"player_name"`
+	Type       LimitType   `db:"limit_type"`
+	Window     LimitWindow `db:"limit_window"`
+	Amount     int64       `db:"amount"`
+	CreatedAt  time.Time   `db:"created_at"`
+	UpdatedAt  time.Time   `db:"updated_at"`
+	Currency   Currency    `db:"currency"`
+}
@@ -0,0 +1,48 @@
+package model
+
+import "time"
+
+type PlayerStatus string
+
+const (
+	PlayerActive       PlayerStatus = "active"
+	PlayerBlocked      PlayerStatus = "blocked"
+	PlayerSelfExcluded PlayerStatus = "self_excluded"
+	PlayerCoolOff      PlayerStatus = "cool_off"
+)
+
+// Player is the account status of a player. A player without a stored
+// status is active. Self-exclusion and cool-off end at Until.
+type Player struct {
+	PlayerName string       `db:"player_name"`
+	Status     PlayerStatus `db:"status"`
+	Reason     string       `db:"reason"`
+	Until      *time.Time   `db:"until"`
+	UpdatedAt  time.Time    `db:"updated_at"`
+}
+
+// Current returns the status in force at now, a self-exclusion or cool-off
+// that ended is active again.
+func (p *Player) Current(now time.Time) PlayerStatus {
+	switch p.Status {
+	case PlayerSelfExcluded, PlayerCoolOff:
+		if p.Until != nil && !now.Before(*p.Until) {
+			return PlayerActive
+		}
+	case "":
+		return PlayerActive
+	}
+	return p.Status
+}
+
+// PlayerStatusChange is one entry of the audit history of a player status.
+type PlayerStatusChange struct {
+	ID         int
+	PlayerName string       `db:"player_name"`
+	FromStatus PlayerStatus `db:"from_status"`
+	ToStatus   PlayerStatus `db:"to_status"`
+	Reason     string       `db:"reason"`
+	Until      *time.Time   `db:"until"`
+	Actor      string       `db:"actor"`
+	CreatedAt  time.Time    `db:"created_at"`
+}
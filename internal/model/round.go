@@ -0,0 +1,30 @@
+package model
+
+import "time"
+
+type RoundStatus string
+
+const (
+	RoundOpen       RoundStatus = "open"
+	RoundClosed     RoundStatus = "closed"
+	RoundRolledBack RoundStatus = "rolled_back"
+)
+
+// Round is a game round in one wallet. Totals only count transactions that
+// are committed and not rolled back.
+type Round struct {
+	ID           int
+	BalanceID    int         `db:"balance_id"`
+	PlayerName   string      `db:"player_name"`
+	GameRoundRef string      `db:"game_round_ref"`
+	GameID       *string     `db:"game_id"`
+	Status       RoundStatus `db:"status"`
+	TotalBet     int64       `db:"total_bet"`
+	TotalWin     int64       `db:"total_win"`
+	Transactions int         `db:"transactions"`
+	CloseReason  *string     `db:"close_reason"`
+	OpenedAt     time.Time   `db:"opened_at"`
+	UpdatedAt    time.Time   `db:"updated_at"`
+	ClosedAt     *time.Time  `db:"closed_at"`
+	Currency     Currency    `db:"currency"`
+}
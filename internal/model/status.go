@@ -0,0 +1,46 @@
+package model
+
+import "time"
+
+type TransactionStatus string
+
+const (
+	StatusCommitted  TransactionStatus = "committed"
+	StatusRolledBack TransactionStatus = "rolled_back"
+	StatusTombstone  TransactionStatus = "tombstone_rolled_back"
+	StatusFailed     TransactionStatus = "failed"
+)
+
+// transitions lists the statuses a transaction may move to. The empty status
+// is a transaction that is not stored yet, a failed one may be retried under
+// the same transactionRef.
+var transitions = map[TransactionStatus][]TransactionStatus{
+	"":              {StatusCommitted, StatusFailed, StatusTombstone},
+	StatusFailed:    {StatusCommitted, StatusFailed, StatusTombstone},
+	StatusCommitted: {StatusRolledBack},
+}
+
+func (s TransactionStatus) CanTransition(to TransactionStatus) bool {
+	for _, status := range transitions[s] {
+		if status == to {
+			return true
+		}
+	}
+	return false
+}
+
+// IsRollback reports whether the transaction was rolled back, before or after
+// it was committed.
+func (s TransactionStatus) IsRollback() bool {
+	return s == StatusRolledBack || s == StatusTombstone
+}
+
+// Transition is one status change of a transaction.
+type Transition struct {
+	ID            int
+	TransactionID int               `db:"transaction_id"`
+	FromStatus    TransactionStatus `db:"from_status"`
+	ToStatus      TransactionStatus `db:"to_status"`
+	Reason        string
+	CreatedAt     time.Time `db:"created_at"`
+}
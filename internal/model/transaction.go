@@ -2,26 +2,63 @@ package model
 
 import "time"
 
+// Transaction moves money in a wallet. When it was wagered against a bonus,
+// BonusWithdraw and BonusDeposit are the parts of Withdraw and Deposit that
+// were taken from and paid to the bonus instead of the cash, BonusConverted
+// the bonus money it converted to cash.
 type Transaction struct {
 	ID                   int
 	BalanceID            int `db:"balance_id"`
-	Withdraw             int
-	Deposit              int
+	Withdraw             int64
+	Deposit              int64
 	TransactionRef       string  `db:"transaction_ref"`
 	GameID               *string `db:"game_id"`
 	GameRoundRef         *string `db:"game_round_ref"`
 	Source               *string
 	Reason               *string
-	SessionId            *string      `db:"session_id"`
-	SessionAlternativeId *string      `db:"session_alternative_id"`
-	BonusId              *string      `db:"bonus_id"`
-	ChargeFreeRounds     *int         `db:"charge_free_rounds"`
-	IsRollback           bool         `db:"is_rollback"`
+	SessionId            *string `db:"session_id"`
+	SessionAlternativeId *string `db:"session_alternative_id"`
+	BonusId              *string `db:"bonus_id"`
+	ChargeFreeRounds     *int    `db:"charge_free_rounds"`
+	Status               TransactionStatus
+	WageredBonusID       *int         `db:"wagered_bonus_id"`
+	BonusWithdraw        int64        `db:"bonus_withdraw"`
+	BonusDeposit         int64        `db:"bonus_deposit"`
+	BonusConverted       int64        `db:"bonus_converted"`
+	BalanceAfter         *int64       `db:"balance_after"`
+	BonusAmountAfter     *int64       `db:"bonus_amount_after"`
+	FreeRoundLeftAfter   *int         `db:"free_round_left_after"`
+	CloseRound           bool         `db:"close_round"`
 	CreatedAt            time.Time    `db:"created_at"`
 	UpdatedAt            time.Time    `db:"updated_at"`
 	SpinDetails          *SpinDetails `db:"spin_details"`
 }
 
+// TransactionRecord is a transaction with the owner and currency of its
+// wallet.
+type TransactionRecord struct {
+	Transaction
+	PlayerName string `db:"player_name"`
+	Currency   string `db:"currency"`
+}
+
+// TransactionFilter selects the transactions of a player, newest first. A
+// nil field does not filter, From and To bound CreatedAt to [From, To).
+// BeforeID continues after the last ID of a previous page, Limit caps the
+// number of transactions returned.
+type TransactionFilter struct {
+	PlayerName   string
+	Currency     *string
+	From         *time.Time
+	To           *time.Time
+	GameID       *string
+	GameRoundRef *string
+	BonusID      *string
+	Status       *TransactionStatus
+	BeforeID     int
+	Limit        int
+}
+
 type SpinDetails struct {
 	ID            int
 	TransactionID int    `db:"transaction_id"`
@@ -0,0 +1,121 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"seamless-api-wrapper/internal/model"
+	"seamless-api-wrapper/internal/service"
+	"time"
+)
+
+const selectAdjustmentQuery = `SELECT 
+		adjustments.*,
+		balances.player_name,
+		currencies.id "currency.id",
+		currencies.code "currency.code",
+		currencies.exponent "currency.exponent"
+	FROM adjustments 
+	JOIN balances ON adjustments.balance_id = balances.id 
+	JOIN currencies ON balances.currency_id = currencies.id `
+
+func (s *SeamlessService) CreateWallet(ctx context.Context, playerName, currencyCode string, gameID *string) (*model.Balance, error) {
+	var currencyID int
+	err := s.db.GetContext(ctx, &currencyID, "SELECT id FROM currencies WHERE code = $1", currencyCode)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, service.ErrIllegalCurrencyCode
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	var balanceID int
+	err = s.db.GetContext(ctx, &balanceID, `INSERT INTO balances(player_name, currency_id, amount, game_id, created_at, updated_at) 
+	VALUES ($1, $2, 0, $3, NOW(), NOW()) 
+	ON CONFLICT (player_name, currency_id) DO NOTHING 
+	RETURNING id`, playerName, currencyID, gameID)
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, service.ErrWalletExists
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return s.Balance(ctx, playerName, currencyCode)
+}
+
+func (s *SeamlessService) Adjust(ctx context.Context, playerName, currencyCode string, adjustment *model.Adjustment) (*model.Balance, error) {
+	if err := service.CheckAdjustment(adjustment); err != nil {
+		return nil, err
+	}
+
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var balance model.Balance
+	err = tx.Get(&balance, selectBalanceQuery+` FOR UPDATE OF balances`, playerName, currencyCode)
+
+	if errors.Is(err, sql.ErrNoRows) {
+		err = missingBalance(ctx, tx, playerName, currencyCode)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	amount, err := service.Adjusted(&balance, adjustment)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+
+	if adjustment.Reason == model.AdjustmentDeposit {
+		if err := checkDepositLimits(tx, &balance, adjustment.Amount, now); err != nil {
+			return nil, err
+		}
+	}
+
+	adjustment.BalanceID = balance.ID
+	adjustment.PlayerName = playerName
+	adjustment.Actor = service.Actor(ctx)
+	adjustment.CreatedAt = now
+	adjustment.Currency = balance.Currency
+
+	query, args, err := tx.BindNamed(`INSERT INTO adjustments(
+		balance_id, amount, reason_code, comment, actor, created_at
+	) VALUES (
+		:balance_id, :amount, :reason_code, :comment, :actor, :created_at
+	) RETURNING id`, adjustment)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Get(&adjustment.ID, query, args...); err != nil {
+		return nil, err
+	}
+
+	if err := post(ctx, tx, balance.ID, model.CauseManualAdjustment, service.ManualEntries(&balance, adjustment.Amount)); err != nil {
+		return nil, err
+	}
+
+	balance.Amount = amount
+	return &balance, tx.Commit()
+}
+
+func (s *SeamlessService) Adjustments(ctx context.Context, playerName string) ([]model.Adjustment, error) {
+	var adjustments []model.Adjustment
+	err := s.db.SelectContext(ctx, &adjustments, selectAdjustmentQuery+
+		`WHERE balances.player_name = $1 ORDER BY adjustments.id`, playerName)
+	if err != nil {
+		return nil, err
+	}
+
+	return adjustments, nil
+}
@@ -0,0 +1,152 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/jmoiron/sqlx"
+	"seamless-api-wrapper/internal/model"
+	"seamless-api-wrapper/internal/service"
+	"time"
+)
+
+const selectBonusQuery = `SELECT 
+		bonuses.*,
+		balances.player_name,
+		currencies.id "currency.id",
+		currencies.code "currency.code",
+		currencies.exponent "currency.exponent"
+	FROM bonuses 
+	JOIN balances ON bonuses.balance_id = balances.id 
+	JOIN currencies ON balances.currency_id = currencies.id `
+
+// SetSpendOrder selects which funds bets against a bonus use first, cash by
+// default. It is meant to be called before the service is used.
+func (s *SeamlessService) SetSpendOrder(order service.SpendOrder) {
+	s.spendOrder = order
+}
+
+func (s *SeamlessService) GrantBonus(ctx context.Context, playerName, currencyCode string, bonus *model.Bonus) error {
+	if err := service.CheckBonus(bonus); err != nil {
+		return err
+	}
+
+	balance, err := s.Balance(ctx, playerName, currencyCode)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+
+	bonus.BalanceID = balance.ID
+	bonus.PlayerName = playerName
+	bonus.Amount = bonus.Granted
+	bonus.Wagered = 0
+	bonus.Status = model.BonusActive
+	bonus.CreatedAt = now
+	bonus.UpdatedAt = now
+	bonus.ConvertedAt = nil
+	bonus.Currency = balance.Currency
+
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query, args, err := tx.BindNamed(`INSERT INTO bonuses(
+		balance_id, bonus_id, granted, amount, wagering, wagered, status, created_at, updated_at
+	) VALUES (
+		:balance_id, :bonus_id, :granted, :amount, :wagering, :wagered, :status, :created_at, :updated_at
+	) 
+	ON CONFLICT (balance_id, bonus_id) DO NOTHING 
+	RETURNING id`, bonus)
+	if err != nil {
+		return err
+	}
+
+	err = tx.Get(&bonus.ID, query, args...)
+	if errors.Is(err, sql.ErrNoRows) {
+		return service.ErrBonusExists
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if err := post(ctx, tx, balance.ID, model.CauseGrantBonus, service.GrantEntries(balance, bonus)); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (s *SeamlessService) Bonuses(ctx context.Context, playerName string) ([]model.Bonus, error) {
+	var bonuses []model.Bonus
+	err := s.db.SelectContext(ctx, &bonuses, selectBonusQuery+
+		`WHERE balances.player_name = $1 ORDER BY bonuses.id`, playerName)
+	if err != nil {
+		return nil, err
+	}
+
+	return bonuses, nil
+}
+
+// activeBonus locks the active bonus a transaction is wagered against, nil
+// when it carries no bonusId or the bonus is not active.
+func activeBonus(tx *sqlx.Tx, balanceID int, transaction *model.Transaction) (*model.Bonus, error) {
+	if transaction.BonusId == nil {
+		return nil, nil
+	}
+
+	var bonus model.Bonus
+	err := tx.Get(&bonus, selectBonusQuery+
+		`WHERE bonuses.balance_id = $1 AND bonuses.bonus_id = $2 AND bonuses.status = $3 FOR UPDATE OF bonuses`,
+		balanceID, *transaction.BonusId, model.BonusActive)
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &bonus, nil
+}
+
+// wageredBonus locks the bonus a committed transaction was wagered against
+// and keeps it in bonuses, so several transactions are reversed against one
+// copy. It returns nil when the bonus was converted since.
+func wageredBonus(tx *sqlx.Tx, transaction *model.Transaction, bonuses map[int]*model.Bonus) (*model.Bonus, error) {
+	if transaction.WageredBonusID == nil {
+		return nil, nil
+	}
+
+	id := *transaction.WageredBonusID
+	if bonus, ok := bonuses[id]; ok {
+		return bonus, nil
+	}
+
+	var bonus model.Bonus
+	err := tx.Get(&bonus, selectBonusQuery+
+		`WHERE bonuses.id = $1 AND bonuses.status = $2 FOR UPDATE OF bonuses`, id, model.BonusActive)
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	bonuses[id] = &bonus
+	return &bonus, nil
+}
+
+func saveBonus(tx *sqlx.Tx, bonus *model.Bonus) error {
+	_, err := tx.Exec(`UPDATE bonuses 
+	SET amount = $1, wagered = $2, status = $3, converted_at = $4, updated_at = NOW() 
+	WHERE id = $5`, bonus.Amount, bonus.Wagered, bonus.Status, bonus.ConvertedAt, bonus.ID)
+	return err
+}
@@ -0,0 +1,235 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"seamless-api-wrapper/internal/model"
+	"seamless-api-wrapper/internal/service"
+	"time"
+)
+
+const selectFreeRoundQuery = `SELECT 
+		free_rounds.*,
+		balances.player_name,
+		currencies.id "currency.id",
+		currencies.code "currency.code",
+		currencies.exponent "currency.exponent"
+	FROM free_rounds 
+	JOIN balances ON free_rounds.balance_id = balances.id 
+	JOIN currencies ON balances.currency_id = currencies.id `
+
+// freeRoundRow scans the game_ids array of a campaign.
+type freeRoundRow struct {
+	model.FreeRound
+	GameIDs pq.StringArray `db:"game_ids"`
+}
+
+func (r *freeRoundRow) freeRound() model.FreeRound {
+	freeRound := r.FreeRound
+	freeRound.GameIDs = r.GameIDs
+	return freeRound
+}
+
+func (s *SeamlessService) GrantFreeRounds(ctx context.Context, playerName, currencyCode string, freeRound *model.FreeRound) error {
+	if err := service.CheckGrant(freeRound); err != nil {
+		return err
+	}
+
+	balance, err := s.Balance(ctx, playerName, currencyCode)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+
+	freeRound.BalanceID = balance.ID
+	freeRound.PlayerName = playerName
+	freeRound.RoundsLeft = freeRound.Rounds
+	freeRound.Status = model.FreeRoundActive
+	freeRound.CreatedAt = now
+	freeRound.UpdatedAt = now
+	freeRound.Currency = balance.Currency
+
+	err = s.db.GetContext(ctx, &freeRound.ID, `INSERT INTO free_rounds(
+		balance_id, bonus_id, game_ids, bet_value, rounds, rounds_left, status, expires_at, created_at, updated_at
+	) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10) 
+	ON CONFLICT (balance_id, bonus_id) DO NOTHING 
+	RETURNING id`,
+		freeRound.BalanceID, freeRound.BonusID, pq.StringArray(append([]string{}, freeRound.GameIDs...)), freeRound.BetValue,
+		freeRound.Rounds, freeRound.RoundsLeft, freeRound.Status, freeRound.ExpiresAt, freeRound.CreatedAt, freeRound.UpdatedAt)
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return service.ErrFreeRoundsExists
+	}
+
+	return err
+}
+
+func (s *SeamlessService) CancelFreeRounds(ctx context.Context, playerName, currencyCode, bonusID string) (*model.FreeRound, error) {
+	if err := expireFreeRounds(ctx, s.db, playerName); err != nil {
+		return nil, err
+	}
+
+	_, err := s.db.ExecContext(ctx, `UPDATE free_rounds 
+	SET status = $1, updated_at = NOW() 
+	FROM balances, currencies 
+	WHERE free_rounds.balance_id = balances.id AND balances.currency_id = currencies.id 
+		AND balances.player_name = $2 AND currencies.code = $3 AND free_rounds.bonus_id = $4 
+		AND free_rounds.status = $5`,
+		model.FreeRoundCancelled, playerName, currencyCode, bonusID, model.FreeRoundActive)
+	if err != nil {
+		return nil, err
+	}
+
+	var row freeRoundRow
+	err = s.db.GetContext(ctx, &row, selectFreeRoundQuery+
+		`WHERE balances.player_name = $1 AND currencies.code = $2 AND free_rounds.bonus_id = $3`,
+		playerName, currencyCode, bonusID)
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, service.ErrFreeRoundsNotFound
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	freeRound := row.freeRound()
+	return &freeRound, nil
+}
+
+func (s *SeamlessService) FreeRounds(ctx context.Context, playerName string) ([]model.FreeRound, error) {
+	if err := expireFreeRounds(ctx, s.db, playerName); err != nil {
+		return nil, err
+	}
+
+	var rows []freeRoundRow
+	err := s.db.SelectContext(ctx, &rows, selectFreeRoundQuery+
+		`WHERE balances.player_name = $1 ORDER BY free_rounds.id`, playerName)
+	if err != nil {
+		return nil, err
+	}
+
+	freeRounds := make([]model.FreeRound, 0, len(rows))
+	for i := range rows {
+		freeRounds = append(freeRounds, rows[i].freeRound())
+	}
+
+	return freeRounds, nil
+}
+
+func (s *SeamlessService) EditFreeRounds(ctx context.Context, playerName, currencyCode, bonusID string, edit *model.FreeRoundEdit) (*model.FreeRound, error) {
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if err := expireFreeRounds(ctx, tx, playerName); err != nil {
+		return nil, err
+	}
+
+	var row freeRoundRow
+	err = tx.Get(&row, selectFreeRoundQuery+
+		`WHERE balances.player_name = $1 AND currencies.code = $2 AND free_rounds.bonus_id = $3 FOR UPDATE OF free_rounds`,
+		playerName, currencyCode, bonusID)
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, service.ErrFreeRoundsNotFound
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	freeRound := row.freeRound()
+	if err := service.EditFreeRound(&freeRound, edit, time.Now()); err != nil {
+		return nil, err
+	}
+
+	_, err = tx.Exec(`UPDATE free_rounds 
+	SET rounds = $1, rounds_left = $2, status = $3, expires_at = $4, updated_at = $5 
+	WHERE id = $6`,
+		freeRound.Rounds, freeRound.RoundsLeft, freeRound.Status, freeRound.ExpiresAt, freeRound.UpdatedAt, freeRound.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &freeRound, tx.Commit()
+}
+
+// chargeFreeRounds locks the campaign a transaction charges and checks the
+// charge, the rounds are taken off with updateFreeRound once the transaction
+// is stored.
+func chargeFreeRounds(tx *sqlx.Tx, balanceID int, transaction *model.Transaction, now time.Time) (*model.FreeRound, error) {
+	if transaction.BonusId == nil {
+		return nil, service.ErrFreeRoundsNotFound
+	}
+
+	var row freeRoundRow
+	err := tx.Get(&row, selectFreeRoundQuery+
+		`WHERE free_rounds.balance_id = $1 AND free_rounds.bonus_id = $2 FOR UPDATE OF free_rounds`,
+		balanceID, *transaction.BonusId)
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, service.ErrFreeRoundsNotFound
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	freeRound := row.freeRound()
+	if err := service.ChargeFreeRounds(&freeRound, transaction, now); err != nil {
+		return nil, err
+	}
+
+	return &freeRound, nil
+}
+
+// refundFreeRounds gives the rounds a rolled back transaction charged back to
+// its campaign.
+func refundFreeRounds(tx *sqlx.Tx, transaction *model.Transaction) error {
+	if transaction.ChargeFreeRounds == nil || transaction.BonusId == nil {
+		return nil
+	}
+
+	var row freeRoundRow
+	err := tx.Get(&row, selectFreeRoundQuery+
+		`WHERE free_rounds.balance_id = $1 AND free_rounds.bonus_id = $2 FOR UPDATE OF free_rounds`,
+		transaction.BalanceID, *transaction.BonusId)
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+
+	if err != nil {
+		return err
+	}
+
+	freeRound := row.freeRound()
+	service.RefundFreeRounds(&freeRound, transaction, time.Now())
+
+	return updateFreeRound(tx, &freeRound)
+}
+
+func updateFreeRound(tx *sqlx.Tx, freeRound *model.FreeRound) error {
+	_, err := tx.Exec("UPDATE free_rounds SET rounds_left = $1, status = $2, updated_at = $3 WHERE id = $4",
+		freeRound.RoundsLeft, freeRound.Status, freeRound.UpdatedAt, freeRound.ID)
+	return err
+}
+
+// expireFreeRounds marks the player's active campaigns past their expiry as
+// expired.
+func expireFreeRounds(ctx context.Context, db sqlx.ExecerContext, playerName string) error {
+	_, err := db.ExecContext(ctx, `UPDATE free_rounds 
+	SET status = $1, updated_at = NOW() 
+	FROM balances 
+	WHERE free_rounds.balance_id = balances.id AND balances.player_name = $2 
+		AND free_rounds.status = $3 AND free_rounds.expires_at <= NOW()`,
+		model.FreeRoundExpired, playerName, model.FreeRoundActive)
+	return err
+}
@@ -0,0 +1,156 @@
+package postgres
+
+import (
+	"context"
+	"github.com/jmoiron/sqlx"
+	"seamless-api-wrapper/internal/model"
+	"seamless-api-wrapper/internal/service"
+	"time"
+)
+
+func (s *SeamlessService) LedgerEntries(ctx context.Context, playerName, currencyCode string) ([]model.LedgerEntry, error) {
+	var entries []model.LedgerEntry
+	err := s.db.SelectContext(ctx, &entries, `SELECT ledger_entries.* FROM ledger_entries 
+	JOIN balances ON ledger_entries.balance_id = balances.id 
+	JOIN currencies ON balances.currency_id = currencies.id 
+	WHERE balances.player_name = $1 AND currencies.code = $2 
+	ORDER BY ledger_entries.id`, playerName, currencyCode)
+	if err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+func (s *SeamlessService) RebuildBalances(ctx context.Context) (int, error) {
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	corrected, err := rebuild(ctx, tx)
+	if err != nil {
+		return 0, err
+	}
+
+	return corrected, tx.Commit()
+}
+
+// rebuild sets the cash of the wallets and the money of the bonuses to their
+// ledger accounts, every corrected wallet gets a rebuild change.
+func rebuild(ctx context.Context, tx *sqlx.Tx) (int, error) {
+	balances, err := tx.Exec(`WITH corrected AS (
+		UPDATE balances SET amount = ledger.amount 
+		FROM (
+			SELECT balances.id, balances.amount amount_before, coalesce(sum(ledger_entries.amount), 0) amount 
+			FROM balances 
+			LEFT JOIN ledger_entries ON ledger_entries.balance_id = balances.id AND ledger_entries.account = $1 
+			GROUP BY balances.id
+		) ledger 
+		WHERE balances.id = ledger.id AND balances.amount <> ledger.amount 
+		RETURNING balances.id, ledger.amount_before, ledger.amount
+	) 
+	INSERT INTO balance_history(balance_id, cause, actor, amount_before, amount_after, delta, bonus_delta, created_at) 
+	SELECT id, $2, $3, amount_before, amount, amount - amount_before, 0, NOW() FROM corrected`, model.AccountCash, model.CauseRebuild, service.Actor(ctx))
+	if err != nil {
+		return 0, err
+	}
+
+	bonuses, err := tx.Exec(`UPDATE bonuses SET amount = ledger.amount, updated_at = NOW() 
+	FROM (
+		SELECT bonuses.id, coalesce(sum(ledger_entries.amount), 0) amount 
+		FROM bonuses 
+		LEFT JOIN ledger_entries ON ledger_entries.bonus_id = bonuses.id AND ledger_entries.account = $1 
+		GROUP BY bonuses.id
+	) ledger 
+	WHERE bonuses.id = ledger.id AND bonuses.amount <> ledger.amount`, model.AccountBonus)
+	if err != nil {
+		return 0, err
+	}
+
+	balancesCorrected, err := balances.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	bonusesCorrected, err := bonuses.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	return int(balancesCorrected + bonusesCorrected), nil
+}
+
+// post writes a posting, moves the cached cash of its wallet by the
+// posting's cash entries and records the change in the balance history,
+// balances.amount is not written anywhere else.
+func post(ctx context.Context, tx *sqlx.Tx, balanceID int, cause model.Cause, entries []model.LedgerEntry) error {
+	if err := service.CheckPosting(entries); err != nil {
+		return err
+	}
+
+	if len(entries) == 0 {
+		return nil
+	}
+
+	var postingID int
+	if err := tx.Get(&postingID, `SELECT nextval('ledger_posting_seq')`); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	for i := range entries {
+		entries[i].PostingID = postingID
+		entries[i].CreatedAt = now
+	}
+
+	_, err := tx.NamedExec(`INSERT INTO ledger_entries(
+		posting_id, balance_id, currency_id, transaction_id, bonus_id, account, kind, amount, created_at
+	) VALUES (
+		:posting_id, :balance_id, :currency_id, :transaction_id, :bonus_id, :account, :kind, :amount, :created_at
+	)`, entries)
+	if err != nil {
+		return err
+	}
+
+	change := model.BalanceChange{
+		BalanceID:     balanceID,
+		TransactionID: entries[0].TransactionID,
+		Cause:         cause,
+		Actor:         service.Actor(ctx),
+		Delta:         service.CashDelta(entries),
+		BonusDelta:    service.BonusDelta(entries),
+		CreatedAt:     now,
+	}
+
+	err = tx.Get(&change.AmountAfter, `UPDATE balances SET amount = amount + $1 WHERE id = $2 RETURNING amount`, change.Delta, balanceID)
+	if err != nil {
+		return err
+	}
+	change.AmountBefore = change.AmountAfter - change.Delta
+
+	_, err = tx.NamedExec(`INSERT INTO balance_history(
+		balance_id, transaction_id, cause, actor, amount_before, amount_after, delta, bonus_delta, created_at
+	) VALUES (
+		:balance_id, :transaction_id, :cause, :actor, :amount_before, :amount_after, :delta, :bonus_delta, :created_at
+	)`, change)
+
+	return err
+}
+
+func (s *SeamlessService) BalanceHistory(ctx context.Context, playerName, currencyCode string, from, to *time.Time) ([]model.BalanceChange, error) {
+	var history []model.BalanceChange
+	err := s.db.SelectContext(ctx, &history, `SELECT balance_history.* FROM balance_history 
+	JOIN balances ON balance_history.balance_id = balances.id 
+	JOIN currencies ON balances.currency_id = currencies.id 
+	WHERE balances.player_name = $1 AND currencies.code = $2 
+	AND ($3::timestamp IS NULL OR balance_history.created_at >= $3) 
+	AND ($4::timestamp IS NULL OR balance_history.created_at < $4) 
+	ORDER BY balance_history.id`, playerName, currencyCode, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	return history, nil
+}
@@ -0,0 +1,129 @@
+package postgres
+
+import (
+	"context"
+	"github.com/jmoiron/sqlx"
+	"seamless-api-wrapper/internal/model"
+	"seamless-api-wrapper/internal/service"
+	"time"
+)
+
+const selectLimitQuery = `SELECT 
+		limits.*,
+		balances.player_name,
+		currencies.id "currency.id",
+		currencies.code "currency.code",
+		currencies.exponent "currency.exponent"
+	FROM limits 
+	JOIN balances ON limits.balance_id = balances.id 
+	JOIN currencies ON balances.currency_id = currencies.id `
+
+func (s *SeamlessService) SetLimit(ctx context.Context, playerName, currencyCode string, limit *model.Limit) error {
+	if err := service.CheckLimit(limit); err != nil {
+		return err
+	}
+
+	balance, err := s.Balance(ctx, playerName, currencyCode)
+	if err != nil {
+		return err
+	}
+
+	limit.BalanceID = balance.ID
+	limit.PlayerName = playerName
+	limit.Currency = balance.Currency
+
+	return s.db.GetContext(ctx, limit, `INSERT INTO limits(balance_id, limit_type, limit_window, amount, created_at, updated_at) 
+	VALUES ($1, $2, $3, $4, NOW(), NOW()) 
+	ON CONFLICT (balance_id, limit_type, limit_window) DO UPDATE SET 
+		amount = EXCLUDED.amount, 
+		updated_at = EXCLUDED.updated_at 
+	RETURNING id, created_at, updated_at`, limit.BalanceID, limit.Type, limit.Window, limit.Amount)
+}
+
+func (s *SeamlessService) RemoveLimit(ctx context.Context, playerName, currencyCode string, limitType model.LimitType, window model.LimitWindow) error {
+	_, err := s.db.ExecContext(ctx, `DELETE FROM limits 
+	USING balances, currencies 
+	WHERE limits.balance_id = balances.id AND balances.currency_id = currencies.id 
+		AND balances.player_name = $1 AND currencies.code = $2 
+		AND limits.limit_type = $3 AND limits.limit_window = $4`,
+		playerName, currencyCode, limitType, window)
+	return err
+}
+
+func (s *SeamlessService) Limits(ctx context.Context, playerName string) ([]model.Limit, error) {
+	var limits []model.Limit
+	err := s.db.SelectContext(ctx, &limits, selectLimitQuery+
+		`WHERE balances.player_name = $1 ORDER BY limits.id`, playerName)
+	if err != nil {
+		return nil, err
+	}
+
+	return limits, nil
+}
+
+// checkLimits reports the first limit of the locked wallet the transaction
+// would exceed.
+func checkLimits(tx *sqlx.Tx, balance *model.Balance, transaction *model.Transaction, now time.Time) error {
+	var limits []model.Limit
+	err := tx.Select(&limits, selectLimitQuery+`WHERE limits.balance_id = $1 ORDER BY limits.id`, balance.ID)
+	if err != nil {
+		return err
+	}
+
+	for i := range limits {
+		limit := &limits[i]
+
+		var usage service.Usage
+		if limit.Type == model.LimitSession {
+			if transaction.SessionId != nil {
+				err = tx.Get(&usage.SessionStart, `SELECT min(created_at) FROM transactions 
+				WHERE balance_id = $1 AND session_id = $2 AND status = $3`,
+					balance.ID, *transaction.SessionId, model.StatusCommitted)
+			}
+		} else {
+			start, _ := service.Window(limit.Window, now)
+			err = tx.QueryRowx(`SELECT coalesce(sum(withdraw), 0), coalesce(sum(deposit), 0) FROM transactions 
+			WHERE balance_id = $1 AND status = $2 AND created_at >= $3`,
+				balance.ID, model.StatusCommitted, start.In(now.Location())).Scan(&usage.Withdraw, &usage.Deposit)
+		}
+
+		if err != nil {
+			return err
+		}
+
+		if err := service.CheckLimitUsage(limit, usage, transaction, now); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// checkDepositLimits reports the first deposit limit of the locked wallet a
+// manual deposit of amount would exceed.
+func checkDepositLimits(tx *sqlx.Tx, balance *model.Balance, amount int64, now time.Time) error {
+	var limits []model.Limit
+	err := tx.Select(&limits, selectLimitQuery+`WHERE limits.balance_id = $1 AND limits.limit_type = $2 ORDER BY limits.id`,
+		balance.ID, model.LimitDeposit)
+	if err != nil {
+		return err
+	}
+
+	for i := range limits {
+		start, _ := service.Window(limits[i].Window, now)
+
+		var used int64
+		err = tx.Get(&used, `SELECT coalesce(sum(amount), 0) FROM adjustments 
+		WHERE balance_id = $1 AND reason_code = $2 AND amount > 0 AND created_at >= $3`,
+			balance.ID, model.AdjustmentDeposit, start.In(now.Location()))
+		if err != nil {
+			return err
+		}
+
+		if err := service.CheckDeposit(&limits[i], used, amount, now); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,108 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/jmoiron/sqlx"
+	"seamless-api-wrapper/internal/model"
+	"seamless-api-wrapper/internal/service"
+	"time"
+)
+
+func (s *SeamlessService) SetPlayerStatus(ctx context.Context, player *model.Player) error {
+	now := time.Now()
+
+	if err := service.CheckPlayer(player, now); err != nil {
+		return err
+	}
+
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// Locking the wallets orders the change with the player's transactions.
+	var balanceIDs []int
+	err = tx.Select(&balanceIDs, `SELECT id FROM balances WHERE player_name = $1 ORDER BY id FOR UPDATE`, player.PlayerName)
+	if err != nil {
+		return err
+	}
+
+	if len(balanceIDs) == 0 {
+		return service.ErrPlayerNotFound
+	}
+
+	current, err := playerStatus(tx, player.PlayerName)
+	if err != nil {
+		return err
+	}
+
+	if err := service.CheckStatusChange(current, player, now); err != nil {
+		return err
+	}
+
+	player.UpdatedAt = now
+
+	_, err = tx.NamedExec(`INSERT INTO players(player_name, status, reason, until, updated_at) 
+	VALUES (:player_name, :status, :reason, :until, :updated_at) 
+	ON CONFLICT (player_name) DO UPDATE SET 
+		status = EXCLUDED.status, 
+		reason = EXCLUDED.reason, 
+		until = EXCLUDED.until, 
+		updated_at = EXCLUDED.updated_at`, player)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`INSERT INTO player_status_history(player_name, from_status, to_status, reason, until, actor, created_at) 
+	VALUES ($1, $2, $3, $4, $5, $6, $7)`,
+		player.PlayerName, current.Current(now), player.Status, player.Reason, player.Until, service.Actor(ctx), now)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (s *SeamlessService) Player(ctx context.Context, playerName string) (*model.Player, error) {
+	var exists bool
+	err := s.db.GetContext(ctx, &exists, `SELECT EXISTS(SELECT 1 FROM balances WHERE player_name = $1)`, playerName)
+	if err != nil {
+		return nil, err
+	}
+
+	if !exists {
+		return nil, service.ErrPlayerNotFound
+	}
+
+	return playerStatus(s.db, playerName)
+}
+
+func (s *SeamlessService) PlayerStatusHistory(ctx context.Context, playerName string) ([]model.PlayerStatusChange, error) {
+	var history []model.PlayerStatusChange
+	err := s.db.SelectContext(ctx, &history, `SELECT * FROM player_status_history 
+	WHERE player_name = $1 ORDER BY id`, playerName)
+	if err != nil {
+		return nil, err
+	}
+
+	return history, nil
+}
+
+// playerStatus reads the stored status of a player, active when there is
+// none.
+func playerStatus(q sqlx.Queryer, playerName string) (*model.Player, error) {
+	var player model.Player
+	err := sqlx.Get(q, &player, `SELECT * FROM players WHERE player_name = $1`, playerName)
+	if errors.Is(err, sql.ErrNoRows) {
+		return &model.Player{PlayerName: playerName, Status: model.PlayerActive}, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &player, nil
+}
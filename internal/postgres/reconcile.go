@@ -0,0 +1,111 @@
+package postgres
+
+import (
+	"context"
+	"seamless-api-wrapper/internal/model"
+	"seamless-api-wrapper/internal/service"
+)
+
+const reconcileQuery = `SELECT 
+		'cash' check_name, balances.id balance_id, balances.player_name, currencies.code currency, '' ref, 
+		balances.amount stored, coalesce(ledger.amount, 0) expected 
+	FROM balances 
+	JOIN currencies ON balances.currency_id = currencies.id 
+	LEFT JOIN (
+		SELECT balance_id, sum(amount) amount FROM ledger_entries WHERE account = 'cash' GROUP BY balance_id
+	) ledger ON ledger.balance_id = balances.id 
+	WHERE balances.amount <> coalesce(ledger.amount, 0) 
+UNION ALL 
+	SELECT 
+		'bonus', balances.id, balances.player_name, currencies.code, bonuses.bonus_id, 
+		bonuses.amount, coalesce(ledger.amount, 0) 
+	FROM bonuses 
+	JOIN balances ON bonuses.balance_id = balances.id 
+	JOIN currencies ON balances.currency_id = currencies.id 
+	LEFT JOIN (
+		SELECT bonus_id, sum(amount) amount FROM ledger_entries WHERE account = 'bonus' GROUP BY bonus_id
+	) ledger ON ledger.bonus_id = bonuses.id 
+	WHERE bonuses.amount <> coalesce(ledger.amount, 0) 
+UNION ALL 
+	SELECT 
+		'transactions', balances.id, balances.player_name, currencies.code, '', 
+		coalesce(ledger.amount, 0), coalesce(moved.amount, 0) 
+	FROM balances 
+	JOIN currencies ON balances.currency_id = currencies.id 
+	LEFT JOIN (
+		SELECT balance_id, sum(deposit - withdraw) amount FROM transactions WHERE status = 'committed' GROUP BY balance_id
+	) moved ON moved.balance_id = balances.id 
+	LEFT JOIN (
+		SELECT balance_id, sum(amount) amount FROM ledger_entries 
+		WHERE account IN ('cash', 'bonus') AND kind IN ('bet', 'win', 'bonus_conversion', 'rollback', 'adjustment') 
+		GROUP BY balance_id
+	) ledger ON ledger.balance_id = balances.id 
+	WHERE coalesce(ledger.amount, 0) <> coalesce(moved.amount, 0) 
+UNION ALL 
+	SELECT 
+		'free_rounds', balances.id, balances.player_name, currencies.code, free_rounds.bonus_id, 
+		free_rounds.rounds_left, free_rounds.rounds - coalesce(charged.rounds, 0) 
+	FROM free_rounds 
+	JOIN balances ON free_rounds.balance_id = balances.id 
+	JOIN currencies ON balances.currency_id = currencies.id 
+	LEFT JOIN (
+		SELECT balance_id, bonus_id, sum(charge_free_rounds) rounds FROM transactions 
+		WHERE status = 'committed' AND charge_free_rounds IS NOT NULL 
+		GROUP BY balance_id, bonus_id
+	) charged ON charged.balance_id = free_rounds.balance_id AND charged.bonus_id = free_rounds.bonus_id 
+	WHERE free_rounds.rounds_left <> free_rounds.rounds - coalesce(charged.rounds, 0) 
+ORDER BY balance_id, check_name, ref`
+
+// Reconcile recomputes the cached amounts from the ledger, the ledger from
+// the committed transactions and the free rounds left from the charges, and
+// returns every mismatch.
+func (s *SeamlessService) Reconcile(ctx context.Context) ([]model.Discrepancy, error) {
+	var discrepancies []model.Discrepancy
+	if err := s.db.SelectContext(ctx, &discrepancies, reconcileQuery); err != nil {
+		return nil, err
+	}
+
+	return discrepancies, nil
+}
+
+// Repair corrects what Reconcile found: a ledger that disagrees with the
+// transactions gets an adjustment of the wallet's cash, a campaign gets the
+// rounds its charges left, then the cash and bonus caches are rebuilt from
+// the ledger. It is meant to run while the wallets are not in use.
+func (s *SeamlessService) Repair(ctx context.Context, discrepancies []model.Discrepancy) error {
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, discrepancy := range discrepancies {
+		switch discrepancy.Check {
+		case model.CheckTransactions:
+			var balance model.Balance
+			err = tx.Get(&balance, "SELECT * FROM balances WHERE id = $1 FOR UPDATE", discrepancy.BalanceID)
+			if err == nil {
+				err = post(ctx, tx, balance.ID, model.CauseAdjustment, service.AdjustmentEntries(&balance, discrepancy.Expected-discrepancy.Stored))
+			}
+		case model.CheckFreeRounds:
+			_, err = tx.Exec(`UPDATE free_rounds SET 
+				rounds_left = GREATEST($1, 0), 
+				status = CASE 
+					WHEN $1 <= 0 AND status = 'active' THEN 'used' 
+					WHEN $1 > 0 AND status = 'used' THEN 'active' 
+					ELSE status END, 
+				updated_at = NOW() 
+			WHERE balance_id = $2 AND bonus_id = $3`, discrepancy.Expected, discrepancy.BalanceID, discrepancy.Ref)
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+
+	if _, err := rebuild(ctx, tx); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
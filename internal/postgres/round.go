@@ -0,0 +1,104 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/jmoiron/sqlx"
+	"seamless-api-wrapper/internal/model"
+	"seamless-api-wrapper/internal/service"
+	"time"
+)
+
+const selectRoundQuery = `SELECT 
+		game_rounds.*,
+		balances.player_name,
+		currencies.id "currency.id",
+		currencies.code "currency.code",
+		currencies.exponent "currency.exponent"
+	FROM game_rounds 
+	JOIN balances ON game_rounds.balance_id = balances.id 
+	JOIN currencies ON balances.currency_id = currencies.id `
+
+func (s *SeamlessService) Round(ctx context.Context, playerName, currencyCode, gameRoundRef string) (*model.Round, error) {
+	var round model.Round
+	err := s.db.GetContext(ctx, &round, selectRoundQuery+
+		`WHERE balances.player_name = $1 AND currencies.code = $2 AND game_rounds.game_round_ref = $3`,
+		playerName, currencyCode, gameRoundRef)
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, service.ErrRoundNotFound
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &round, nil
+}
+
+func (s *SeamlessService) OpenRounds(ctx context.Context, playerName string) ([]model.Round, error) {
+	var rounds []model.Round
+	err := s.db.SelectContext(ctx, &rounds, selectRoundQuery+
+		`WHERE balances.player_name = $1 AND game_rounds.status = $2 ORDER BY game_rounds.id`,
+		playerName, model.RoundOpen)
+	if err != nil {
+		return nil, err
+	}
+
+	return rounds, nil
+}
+
+func (s *SeamlessService) CloseInactiveRounds(ctx context.Context, before time.Time) (int, error) {
+	result, err := s.db.ExecContext(ctx, `UPDATE game_rounds 
+	SET status = $1, close_reason = 'inactivity', closed_at = NOW() 
+	WHERE status = $2 AND updated_at < $3`, model.RoundClosed, model.RoundOpen, before)
+	if err != nil {
+		return 0, err
+	}
+
+	closed, err := result.RowsAffected()
+	return int(closed), err
+}
+
+// recordRound adds a committed transaction to its round, opening the round
+// on its first transaction.
+func recordRound(tx *sqlx.Tx, transaction *model.Transaction) error {
+	if transaction.GameRoundRef == nil {
+		return nil
+	}
+
+	_, err := tx.Exec(`INSERT INTO game_rounds(
+		balance_id, game_round_ref, game_id, status, total_bet, total_win, transactions, 
+		close_reason, opened_at, updated_at, closed_at
+	) VALUES (
+		$1, $2, $3, CASE WHEN $6 THEN $7 ELSE $8 END, $4, $5, 1, 
+		CASE WHEN $6 THEN 'withdrawAndDeposit' END, NOW(), NOW(), CASE WHEN $6 THEN NOW() END
+	) 
+	ON CONFLICT (balance_id, game_round_ref) DO UPDATE SET 
+		total_bet = game_rounds.total_bet + EXCLUDED.total_bet, 
+		total_win = game_rounds.total_win + EXCLUDED.total_win, 
+		transactions = game_rounds.transactions + 1, 
+		updated_at = EXCLUDED.updated_at, 
+		status = CASE WHEN $6 AND game_rounds.status = $8 THEN $7 ELSE game_rounds.status END, 
+		close_reason = CASE WHEN $6 AND game_rounds.status = $8 THEN EXCLUDED.close_reason ELSE game_rounds.close_reason END, 
+		closed_at = CASE WHEN $6 AND game_rounds.status = $8 THEN EXCLUDED.closed_at ELSE game_rounds.closed_at END`,
+		transaction.BalanceID, *transaction.GameRoundRef, transaction.GameID,
+		transaction.Withdraw, transaction.Deposit, transaction.CloseRound, model.RoundClosed, model.RoundOpen)
+
+	return err
+}
+
+// unrecordRound takes a rolled back transaction out of its round totals.
+func unrecordRound(tx *sqlx.Tx, transaction *model.Transaction) error {
+	if transaction.GameRoundRef == nil {
+		return nil
+	}
+
+	_, err := tx.Exec(`UPDATE game_rounds 
+	SET total_bet = total_bet - $1, total_win = total_win - $2, transactions = transactions - 1, updated_at = NOW() 
+	WHERE balance_id = $3 AND game_round_ref = $4`,
+		transaction.Withdraw, transaction.Deposit, transaction.BalanceID, *transaction.GameRoundRef)
+
+	return err
+}
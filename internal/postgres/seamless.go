@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"fmt"
 	"github.com/jmoiron/sqlx"
 	"github.com/lib/pq"
 	"seamless-api-wrapper/internal/model"
@@ -12,33 +13,68 @@ import (
 )
 
 type SeamlessService struct {
-	db *sqlx.DB
+	db         *sqlx.DB
+	spendOrder service.SpendOrder
 }
 
 func NewSeamlessService(db *sqlx.DB) *SeamlessService {
-	return &SeamlessService{db: db}
+	return &SeamlessService{db: db, spendOrder: service.CashFirst}
 }
 
-func (s *SeamlessService) Balance(ctx context.Context, playerName, currencyCode string) (*model.Balance, error) {
-	var balance model.Balance
-	err := s.db.GetContext(ctx, &balance, `SELECT 
+// selectBalanceQuery counts the money left in the wallet's active bonuses as
+// bonus_amount and the rounds left in its active campaigns as free_round_left.
+const selectBalanceQuery = `SELECT 
 		balances.*,
+		(SELECT coalesce(sum(bonuses.amount), 0) 
+			FROM bonuses 
+			WHERE bonuses.balance_id = balances.id AND bonuses.status = 'active') bonus_amount,
+		(SELECT sum(free_rounds.rounds_left) 
+			FROM free_rounds 
+			WHERE free_rounds.balance_id = balances.id AND free_rounds.status = 'active' 
+				AND (free_rounds.expires_at IS NULL OR free_rounds.expires_at > NOW())) free_round_left,
 		currencies.id "currency.id",
-		currencies.code "currency.code"
+		currencies.code "currency.code",
+		currencies.exponent "currency.exponent"
 	FROM balances 
-	LEFT JOIN currencies ON balances.currency_id = currencies.id 
-	WHERE balances.player_name = $1 AND currencies.code = $2 LIMIT 1`, playerName, currencyCode)
+	JOIN currencies ON balances.currency_id = currencies.id 
+	WHERE balances.player_name = $1 AND currencies.code = $2 LIMIT 1`
 
-	if errors.Is(err, sql.ErrNoRows) {
-		return nil, service.ErrNotEnoughMoneyCode
+func (s *SeamlessService) CreateCurrency(ctx context.Context, code string, exponent int) (*model.Currency, error) {
+	if code == "" || exponent < 0 || exponent > service.MaxExponent {
+		return nil, service.ErrIllegalCurrencyCode
 	}
 
+	var currency model.Currency
+	err := s.db.GetContext(ctx, &currency, `INSERT INTO currencies(code, exponent) VALUES ($1, $2) 
+	ON CONFLICT (code) DO UPDATE SET code = EXCLUDED.code 
+	RETURNING id, code, exponent`, code, exponent)
 	if err != nil {
 		return nil, err
 	}
 
-	if balance.Currency.Code == "" {
-		return nil, service.ErrIllegalCurrencyCode
+	return &currency, nil
+}
+
+func (s *SeamlessService) Currencies(ctx context.Context) ([]model.Currency, error) {
+	var currencies []model.Currency
+	err := s.db.SelectContext(ctx, &currencies, "SELECT id, code, exponent FROM currencies ORDER BY code")
+	if err != nil {
+		return nil, err
+	}
+
+	return currencies, nil
+}
+
+func (s *SeamlessService) Balance(ctx context.Context, playerName, currencyCode string) (*model.Balance, error) {
+	var balance model.Balance
+	err := s.db.GetContext(ctx, &balance, selectBalanceQuery, playerName, currencyCode)
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, missingBalance(ctx, s.db, playerName, currencyCode)
+	}
+
+	if err != nil {
+		return nil, err
 	}
 
 	return &balance, nil
@@ -53,24 +89,26 @@ func (s *SeamlessService) Transaction(ctx context.Context, playerName, currencyC
 		return nil, service.ErrNegativeWithdrawalCode
 	}
 
+	// Retries are answered from the stored transaction without locking the
+	// wallet, its snapshot never changes once committed.
+	if balance, ok, err := s.replay(ctx, playerName, currencyCode, transaction); ok || err != nil {
+		return balance, err
+	}
+
 	tx, err := s.db.BeginTxx(ctx, nil)
 	if err != nil {
 		return nil, err
 	}
 
 	var balance model.Balance
-	err = tx.Get(&balance, `SELECT 
-		balances.*,
-		currencies.id "currency.id",
-		currencies.code "currency.code"
-	FROM balances 
-	LEFT JOIN currencies ON balances.currency_id = currencies.id 
-	WHERE balances.player_name = $1 AND currencies.code = $2 LIMIT 1 
-	FOR UPDATE OF balances`, playerName, currencyCode)
+	err = tx.Get(&balance, selectBalanceQuery+` FOR UPDATE OF balances`, playerName, currencyCode)
 
 	if errors.Is(err, sql.ErrNoRows) {
-		tx.Rollback()
-		return nil, service.ErrIllegalCurrencyCode
+		if err := createDefaultBalance(ctx, tx, playerName, currencyCode); err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+		err = tx.Get(&balance, selectBalanceQuery+` FOR UPDATE OF balances`, playerName, currencyCode)
 	}
 
 	if err != nil {
@@ -78,8 +116,17 @@ func (s *SeamlessService) Transaction(ctx context.Context, playerName, currencyC
 		return nil, err
 	}
 
-	if balance.Currency.Code == "" {
-		return nil, service.ErrIllegalCurrencyCode
+	var existing model.Transaction
+	err = tx.Get(&existing, "SELECT * FROM transactions WHERE transaction_ref = $1 FOR UPDATE", transaction.TransactionRef)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+	case err != nil:
+		tx.Rollback()
+		return nil, err
+	case existing.Status != model.StatusFailed:
+		// Stored after replay looked, answer from the stored row.
+		tx.Rollback()
+		return s.replayStored(ctx, playerName, currencyCode, transaction)
 	}
 
 	transaction.BalanceID = balance.ID
@@ -89,25 +136,101 @@ func (s *SeamlessService) Transaction(ctx context.Context, playerName, currencyC
 	transaction.CreatedAt = now
 	transaction.UpdatedAt = now
 
-	insertTransactionQuery := `INSERT INTO transactions(
-		balance_id,  withdraw, deposit, game_id, transaction_ref,
-		source, reason, session_id, session_alternative_id,
-		bonus_id, charge_free_rounds, created_at, updated_at
-	) VALUES (
-		:balance_id, :withdraw, :deposit, :game_id, :transaction_ref,
-		:source, :reason, :session_id, :session_alternative_id,
-		:bonus_id, :charge_free_rounds, :created_at, :updated_at
-	) RETURNING id`
+	// A transaction that can not be applied is still stored as failed, so
+	// its transactionRef has a history.
+	status, reason := model.StatusCommitted, "withdrawAndDeposit"
 
-	query, args, err := tx.BindNamed(insertTransactionQuery, transaction)
+	bonus, err := activeBonus(tx, balance.ID, transaction)
 	if err != nil {
+		tx.Rollback()
 		return nil, err
 	}
 
-	err = tx.Get(&transaction.ID, query, args...)
-	if err, ok := err.(*pq.Error); ok && err.Code == "23505" {
+	result := balance
+
+	player, err := playerStatus(tx, playerName)
+	if err != nil {
 		tx.Rollback()
-		return &balance, s.checkTransaction(ctx, transaction)
+		return nil, err
+	}
+
+	err = service.CheckPlayerBet(player, transaction, now)
+	if err == nil {
+		err = checkLimits(tx, &balance, transaction, now)
+		var limitErr *service.LimitError
+		if err != nil && !errors.As(err, &limitErr) {
+			tx.Rollback()
+			return nil, err
+		}
+	}
+
+	if err == nil {
+		err = service.Apply(&result, bonus, transaction, s.spendOrder, now)
+	}
+
+	var freeRound *model.FreeRound
+	if err == nil && transaction.ChargeFreeRounds != nil {
+		freeRound, err = chargeFreeRounds(tx, balance.ID, transaction, now)
+		if err != nil && !errors.Is(err, service.ErrFreeRoundsNotFound) && !errors.Is(err, service.ErrNotEnoughFreeRounds) {
+			tx.Rollback()
+			return nil, err
+		}
+	}
+
+	failure := err
+	if failure != nil {
+		status, reason = model.StatusFailed, failure.Error()
+		transaction.BalanceAfter = nil
+		transaction.BonusAmountAfter = nil
+		transaction.FreeRoundLeftAfter = nil
+	} else {
+		balance = result
+
+		if freeRound != nil && balance.FreeRoundLeft != nil {
+			// The charged campaign is active and counted in free_round_left.
+			freeRoundLeft := *balance.FreeRoundLeft - *transaction.ChargeFreeRounds
+			balance.FreeRoundLeft = &freeRoundLeft
+			if freeRoundLeft == 0 {
+				balance.FreeRoundLeft = nil
+			}
+		}
+
+		transaction.BalanceAfter = &balance.Amount
+		transaction.BonusAmountAfter = &balance.BonusAmount
+		transaction.FreeRoundLeftAfter = balance.FreeRoundLeft
+	}
+
+	if existing.ID != 0 {
+		transaction.ID = existing.ID
+		transaction.CreatedAt = existing.CreatedAt
+		transaction.Status = existing.Status
+
+		_, err = tx.NamedExec(`UPDATE transactions SET 
+			balance_id = :balance_id, withdraw = :withdraw, deposit = :deposit, game_id = :game_id, 
+			game_round_ref = :game_round_ref, source = :source, reason = :reason, session_id = :session_id, 
+			session_alternative_id = :session_alternative_id, bonus_id = :bonus_id, 
+			charge_free_rounds = :charge_free_rounds, wagered_bonus_id = :wagered_bonus_id, 
+			bonus_withdraw = :bonus_withdraw, bonus_deposit = :bonus_deposit, bonus_converted = :bonus_converted, balance_after = :balance_after, 
+			bonus_amount_after = :bonus_amount_after, free_round_left_after = :free_round_left_after, close_round = :close_round, updated_at = :updated_at 
+		WHERE id = :id`, transaction)
+		if err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+
+		err = setStatus(tx, transaction, status, reason)
+	} else {
+		transaction.Status = status
+		err = insertTransaction(tx, transaction)
+		if err, ok := err.(*pq.Error); ok && err.Code == "23505" {
+			// Stored concurrently through another wallet of the player.
+			tx.Rollback()
+			return s.replayStored(ctx, playerName, currencyCode, transaction)
+		}
+
+		if err == nil {
+			err = insertTransition(tx, transaction.ID, "", status, reason)
+		}
 	}
 
 	if err != nil {
@@ -115,6 +238,13 @@ func (s *SeamlessService) Transaction(ctx context.Context, playerName, currencyC
 		return nil, err
 	}
 
+	if failure != nil {
+		if err := tx.Commit(); err != nil {
+			return nil, err
+		}
+		return nil, failure
+	}
+
 	if transaction.SpinDetails != nil {
 		transaction.SpinDetails.TransactionID = transaction.ID
 		_, err := tx.NamedExec("INSERT INTO spin_details(transaction_id, bet_type, win_type) VALUES (:transaction_id, :bet_type, :win_type)", transaction.SpinDetails)
@@ -124,26 +254,276 @@ func (s *SeamlessService) Transaction(ctx context.Context, playerName, currencyC
 		}
 	}
 
-	balance.Amount -= transaction.Withdraw
-	if balance.Amount < 0 {
+	if bonus != nil {
+		if err := saveBonus(tx, bonus); err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+	}
+
+	if freeRound != nil {
+		if err := updateFreeRound(tx, freeRound); err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+	}
+
+	if err := recordRound(tx, transaction); err != nil {
 		tx.Rollback()
-		return nil, service.ErrSpendingBudgetExceeded
+		return nil, err
+	}
+
+	if err := post(ctx, tx, balance.ID, model.CauseWithdrawAndDeposit, service.TransactionEntries(&balance, transaction)); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	return &balance, tx.Commit()
+}
+
+// replayStored is replay for a transactionRef that is known to be stored.
+func (s *SeamlessService) replayStored(ctx context.Context, playerName, currencyCode string, transaction *model.Transaction) (*model.Balance, error) {
+	balance, ok, err := s.replay(ctx, playerName, currencyCode, transaction)
+	if err == nil && !ok {
+		err = fmt.Errorf("%w: transaction %s is being retried concurrently", service.ErrTransactionBusy, transaction.TransactionRef)
+	}
+	return balance, err
+}
+
+// replay answers a repeated transactionRef. It reports false when there is
+// nothing to replay: the ref is new or its last attempt failed.
+func (s *SeamlessService) replay(ctx context.Context, playerName, currencyCode string, transaction *model.Transaction) (*model.Balance, bool, error) {
+	var stored struct {
+		model.Transaction
+		PlayerName   string `db:"player_name"`
+		CurrencyID   int    `db:"currency_id"`
+		CurrencyCode string `db:"currency_code"`
+		Exponent     int    `db:"exponent"`
+	}
+	err := s.db.GetContext(ctx, &stored, `SELECT 
+		transactions.*, 
+		balances.player_name, 
+		balances.currency_id, 
+		currencies.code currency_code, 
+		currencies.exponent 
+	FROM transactions 
+	JOIN balances ON transactions.balance_id = balances.id 
+	JOIN currencies ON balances.currency_id = currencies.id 
+	WHERE transactions.transaction_ref = $1`, transaction.TransactionRef)
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, false, nil
+	}
+
+	if err != nil {
+		return nil, false, err
+	}
+
+	if stored.Status == model.StatusFailed {
+		return nil, false, nil
+	}
+
+	if stored.Status.IsRollback() {
+		*transaction = stored.Transaction
+		return nil, true, service.ErrTransactionRollback
+	}
+
+	if stored.PlayerName != playerName || stored.CurrencyCode != currencyCode || !service.SameTransaction(&stored.Transaction, transaction) {
+		return nil, true, service.ErrTransactionConflict
 	}
 
-	balance.Amount += transaction.Deposit
+	*transaction = stored.Transaction
 
-	if transaction.ChargeFreeRounds != nil {
-		freeRoundLeft := 0
-		if balance.FreeRoundLeft != nil {
-			freeRoundLeft = *balance.FreeRoundLeft
+	// Only transactions stored before the snapshot existed lack it, the
+	// current balance would not be the answer they got.
+	if stored.BalanceAfter == nil {
+		return nil, true, fmt.Errorf("%w: transaction %s", service.ErrSnapshotMissing, transaction.TransactionRef)
+	}
+
+	result := &model.Balance{
+		ID:            stored.BalanceID,
+		PlayerName:    stored.PlayerName,
+		CurrencyID:    stored.CurrencyID,
+		Amount:        *stored.BalanceAfter,
+		FreeRoundLeft: stored.FreeRoundLeftAfter,
+		Currency:      model.Currency{ID: stored.CurrencyID, Code: stored.CurrencyCode, Exponent: stored.Exponent},
+	}
+	if stored.BonusAmountAfter != nil {
+		result.BonusAmount = *stored.BonusAmountAfter
+	}
+
+	return result, true, nil
+}
+
+// Rollback reverses a committed transaction on its own wallet. A rollback
+// that arrives first is stored as a tombstone against the player's oldest
+// wallet, so the transaction is refused when it comes later.
+func (s *SeamlessService) Rollback(ctx context.Context, playerName string, transaction *model.Transaction) error {
+	retry, err := s.rollback(ctx, playerName, transaction)
+	if err != nil || !retry {
+		return err
+	}
+
+	// The transaction was stored while the tombstone was inserted, it is
+	// committed by now and is rolled back like any other.
+	retry, err = s.rollback(ctx, playerName, transaction)
+	if err == nil && retry {
+		err = fmt.Errorf("%w: transaction %s", service.ErrTransactionBusy, transaction.TransactionRef)
+	}
+	return err
+}
+
+// rollback is one attempt of Rollback. It reports true, without an error,
+// when a tombstone lost the race to a concurrently stored transaction.
+func (s *SeamlessService) rollback(ctx context.Context, playerName string, transaction *model.Transaction) (bool, error) {
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	// The wallet is locked before the transaction, in the same order as
+	// Transaction does.
+	var owner string
+	err = tx.Get(&owner, `SELECT balances.player_name 
+	FROM balances 
+	JOIN transactions ON transactions.balance_id = balances.id 
+	WHERE transactions.transaction_ref = $1 
+	FOR UPDATE OF balances`, transaction.TransactionRef)
+
+	if errors.Is(err, sql.ErrNoRows) {
+		inserted, err := s.insertTombstone(tx, playerName, transaction)
+		if err != nil {
+			return false, err
+		}
+
+		if !inserted {
+			return true, nil
+		}
+
+		return false, tx.Commit()
+	}
+
+	if err == nil && owner != playerName {
+		err = fmt.Errorf("%w: transaction %s belongs to another player", service.ErrForeignTransaction, transaction.TransactionRef)
+	}
+
+	if err != nil {
+		return false, err
+	}
+
+	err = tx.Get(transaction, "SELECT * FROM transactions WHERE transaction_ref = $1 FOR UPDATE", transaction.TransactionRef)
+	if err != nil {
+		return false, err
+	}
+
+	switch transaction.Status {
+	case model.StatusFailed:
+		err = setStatus(tx, transaction, model.StatusTombstone, "rollbackTransaction")
+	case model.StatusCommitted:
+		err = reverse(ctx, tx, transaction)
+
+		if err == nil {
+			err = setStatus(tx, transaction, model.StatusRolledBack, "rollbackTransaction")
+		}
+
+		if err == nil {
+			err = refundFreeRounds(tx, transaction)
+		}
+
+		if err == nil {
+			err = unrecordRound(tx, transaction)
+		}
+	}
+
+	if err != nil {
+		return false, err
+	}
+
+	return false, tx.Commit()
+}
+
+func (s *SeamlessService) RollbackRound(ctx context.Context, playerName, currencyCode, gameRoundRef string) (*model.Balance, error) {
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var balance model.Balance
+	err = tx.Get(&balance, selectBalanceQuery+` FOR UPDATE OF balances`, playerName, currencyCode)
+
+	if errors.Is(err, sql.ErrNoRows) {
+		err = missingBalance(ctx, tx, playerName, currencyCode)
+	}
+
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	var transactions []model.Transaction
+	err = tx.Select(&transactions, `SELECT * FROM transactions 
+	WHERE balance_id = $1 AND game_round_ref = $2 AND status IN ($3, $4) 
+	ORDER BY id 
+	FOR UPDATE`, balance.ID, gameRoundRef, model.StatusCommitted, model.StatusFailed)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if len(transactions) == 0 {
+		tx.Rollback()
+		return &balance, nil
+	}
+
+	bonuses := make(map[int]*model.Bonus)
+	for i := range transactions {
+		transaction := &transactions[i]
+
+		if transaction.Status == model.StatusFailed {
+			err = setStatus(tx, transaction, model.StatusTombstone, "rollbackRound")
+		} else {
+			var bonus *model.Bonus
+			bonus, err = wageredBonus(tx, transaction, bonuses)
+			if err == nil {
+				err = service.Reverse(&balance, bonus, transaction)
+			}
+			if err == nil {
+				err = post(ctx, tx, balance.ID, model.CauseRollbackRound, service.RollbackEntries(&balance, bonus, transaction))
+			}
+			if err == nil {
+				err = setStatus(tx, transaction, model.StatusRolledBack, "rollbackRound")
+			}
+			if err == nil {
+				err = refundFreeRounds(tx, transaction)
+			}
 		}
 
-		freeRoundLeft -= *transaction.ChargeFreeRounds
+		if err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+	}
+
+	for _, bonus := range bonuses {
+		if err := saveBonus(tx, bonus); err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+	}
 
-		balance.FreeRoundLeft = &freeRoundLeft
+	_, err = tx.Exec(`UPDATE game_rounds 
+	SET total_bet = 0, total_win = 0, transactions = 0, status = $1, 
+		close_reason = 'rollbackRound', closed_at = NOW(), updated_at = NOW() 
+	WHERE balance_id = $2 AND game_round_ref = $3`, model.RoundRolledBack, balance.ID, gameRoundRef)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
 	}
 
-	_, err = tx.NamedExec("UPDATE balances SET amount = :amount, free_round_left = :free_round_left WHERE id = :id", &balance)
+	// Reread the money left in the active bonuses and the rounds left in the
+	// active campaigns.
+	err = tx.Get(&balance, selectBalanceQuery, playerName, currencyCode)
 	if err != nil {
 		tx.Rollback()
 		return nil, err
@@ -152,77 +532,173 @@ func (s *SeamlessService) Transaction(ctx context.Context, playerName, currencyC
 	return &balance, tx.Commit()
 }
 
-func (s *SeamlessService) Rollback(ctx context.Context, playerName string, transaction *model.Transaction) error {
-	tx, err := s.db.BeginTxx(ctx, nil)
+func (s *SeamlessService) Transitions(ctx context.Context, transactionRef string) ([]model.Transition, error) {
+	var transitions []model.Transition
+	err := s.db.SelectContext(ctx, &transitions, `SELECT 
+		transaction_transitions.id, 
+		transaction_transitions.transaction_id, 
+		coalesce(transaction_transitions.from_status, '') from_status, 
+		transaction_transitions.to_status, 
+		transaction_transitions.reason, 
+		transaction_transitions.created_at 
+	FROM transaction_transitions 
+	JOIN transactions ON transaction_transitions.transaction_id = transactions.id 
+	WHERE transactions.transaction_ref = $1 
+	ORDER BY transaction_transitions.id`, transactionRef)
 	if err != nil {
-		return err
+		return nil, err
+	}
+
+	return transitions, nil
+}
+
+// insertTombstone stores a rollback for a transaction that was never seen. It
+// reports false when a transaction with the same ref was stored concurrently,
+// a player without a wallet gives ErrPlayerNotFound.
+func (s *SeamlessService) insertTombstone(tx *sqlx.Tx, playerName string, transaction *model.Transaction) (bool, error) {
+	err := tx.Get(&transaction.BalanceID, "SELECT id FROM balances WHERE player_name = $1 ORDER BY id LIMIT 1", playerName)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, service.ErrPlayerNotFound
 	}
 
-	err = tx.Get(&transaction.BalanceID, "SELECT id FROM balances WHERE player_name = $1 LIMIT 1", playerName)
 	if err != nil {
-		tx.Rollback()
-		return err
+		return false, err
 	}
 
+	transaction.Withdraw = 0
+	transaction.Deposit = 0
+	transaction.ChargeFreeRounds = nil
+	transaction.Status = model.StatusTombstone
+
 	query, args, err := tx.BindNamed(`INSERT INTO transactions(
-		balance_id, withdraw, deposit, game_id, transaction_ref, 
-		session_id, session_alternative_id, is_rollback, created_at, updated_at) 
+		balance_id, withdraw, deposit, game_id, transaction_ref, game_round_ref, 
+		session_id, session_alternative_id, status, created_at, updated_at) 
 	VALUES (
-		:balance_id, :withdraw, :deposit, :game_id, :transaction_ref,
-		:session_id, :session_alternative_id, :is_rollback, :created_at, :updated_at
+		:balance_id, :withdraw, :deposit, :game_id, :transaction_ref, :game_round_ref, 
+		:session_id, :session_alternative_id, :status, :created_at, :updated_at
 	) 
-	ON CONFLICT(transaction_ref) 
-	DO UPDATE SET game_id=EXCLUDED.game_id  
+	ON CONFLICT (transaction_ref) DO NOTHING 
 	RETURNING id`, transaction)
 	if err != nil {
-		tx.Rollback()
-		return err
+		return false, err
 	}
 
-	if err := tx.Get(&transaction.ID, query, args...); err != nil {
-		tx.Rollback()
+	err = tx.Get(&transaction.ID, query, args...)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+
+	if err != nil {
+		return false, err
+	}
+
+	return true, insertTransition(tx, transaction.ID, "", model.StatusTombstone, "rollbackTransaction before withdrawAndDeposit")
+}
+
+// reverse takes a committed transaction back out of its locked wallet and
+// the bonus it was wagered against.
+func reverse(ctx context.Context, tx *sqlx.Tx, transaction *model.Transaction) error {
+	var balance model.Balance
+	err := tx.Get(&balance, "SELECT * FROM balances WHERE id = $1", transaction.BalanceID)
+	if err != nil {
 		return err
 	}
 
-	err = tx.Get(transaction, "SELECT withdraw, deposit, is_rollback, charge_free_rounds FROM transactions WHERE id = $1", transaction.ID)
+	bonuses := make(map[int]*model.Bonus)
+	bonus, err := wageredBonus(tx, transaction, bonuses)
 	if err != nil {
-		tx.Rollback()
 		return err
 	}
 
-	if transaction.IsRollback {
-		return tx.Commit()
+	if err := service.Reverse(&balance, bonus, transaction); err != nil {
+		return err
 	}
 
-	_, err = tx.Exec(`UPDATE balances 
-			SET amount = amount + $1 - $2, 
-				free_round_left = coalesce(free_round_left, 0) + coalesce($3, 0) 
-			WHERE player_name = $4`,
-		transaction.Withdraw, transaction.Deposit, transaction.ChargeFreeRounds, playerName)
-	if err != nil {
-		tx.Rollback()
+	if err := post(ctx, tx, balance.ID, model.CauseRollbackTransaction, service.RollbackEntries(&balance, bonus, transaction)); err != nil {
 		return err
 	}
 
-	_, err = tx.Exec("UPDATE transactions SET is_rollback = $1 WHERE id = $2", true, transaction.ID)
+	if bonus != nil {
+		return saveBonus(tx, bonus)
+	}
+
+	return nil
+}
+
+func insertTransaction(tx *sqlx.Tx, transaction *model.Transaction) error {
+	query, args, err := tx.BindNamed(`INSERT INTO transactions(
+		balance_id,  withdraw, deposit, game_id, transaction_ref, game_round_ref,
+		source, reason, session_id, session_alternative_id,
+		bonus_id, charge_free_rounds, status, wagered_bonus_id, bonus_withdraw, bonus_deposit, bonus_converted,
+		balance_after, bonus_amount_after, free_round_left_after, close_round, created_at, updated_at
+	) VALUES (
+		:balance_id, :withdraw, :deposit, :game_id, :transaction_ref, :game_round_ref,
+		:source, :reason, :session_id, :session_alternative_id,
+		:bonus_id, :charge_free_rounds, :status, :wagered_bonus_id, :bonus_withdraw, :bonus_deposit, :bonus_converted,
+		:balance_after, :bonus_amount_after, :free_round_left_after, :close_round, :created_at, :updated_at
+	) RETURNING id`, transaction)
 	if err != nil {
-		tx.Rollback()
 		return err
 	}
 
-	return tx.Commit()
+	return tx.Get(&transaction.ID, query, args...)
 }
 
-func (s *SeamlessService) checkTransaction(ctx context.Context, transaction *model.Transaction) error {
-	err := s.db.GetContext(ctx, transaction, "SELECT * FROM transactions WHERE transaction_ref = $1 LIMIT 1",
-		transaction.TransactionRef)
+// setStatus moves a stored transaction to status if the move is allowed and
+// records the transition.
+func setStatus(tx *sqlx.Tx, transaction *model.Transaction, status model.TransactionStatus, reason string) error {
+	if !transaction.Status.CanTransition(status) {
+		return fmt.Errorf("%w: transaction %s from %s to %s",
+			service.ErrInvalidTransition, transaction.TransactionRef, transaction.Status, status)
+	}
+
+	_, err := tx.Exec("UPDATE transactions SET status = $1, updated_at = NOW() WHERE id = $2", status, transaction.ID)
 	if err != nil {
 		return err
 	}
 
-	if transaction.IsRollback {
-		return service.ErrTransactionRollback
+	from := transaction.Status
+	transaction.Status = status
+
+	return insertTransition(tx, transaction.ID, from, status, reason)
+}
+
+func insertTransition(tx *sqlx.Tx, transactionID int, from, to model.TransactionStatus, reason string) error {
+	_, err := tx.Exec(`INSERT INTO transaction_transitions(transaction_id, from_status, to_status, reason, created_at) 
+	VALUES ($1, NULLIF($2, ''), $3, $4, NOW())`, transactionID, from, to, reason)
+	return err
+}
+
+// createDefaultBalance opens an empty wallet in currencyCode for a player who
+// already has a wallet in another currency.
+func createDefaultBalance(ctx context.Context, db sqlx.ExtContext, playerName, currencyCode string) error {
+	err := missingBalance(ctx, db, playerName, currencyCode)
+	if !errors.Is(err, service.ErrWalletNotFound) {
+		return err
 	}
 
-	return nil
+	_, err = db.ExecContext(ctx, `INSERT INTO balances(player_name, currency_id, amount, created_at, updated_at) 
+	SELECT $1, id, 0, NOW(), NOW() FROM currencies WHERE code = $2 
+	ON CONFLICT (player_name, currency_id) DO NOTHING`, playerName, currencyCode)
+
+	return err
+}
+
+// missingBalance tells why playerName has no wallet in currencyCode: the
+// currency is unknown, the player is unknown or only the wallet is missing.
+func missingBalance(ctx context.Context, db sqlx.ExtContext, playerName, currencyCode string) error {
+	var currencyExists, playerExists bool
+	err := db.QueryRowxContext(ctx, `SELECT 
+		EXISTS (SELECT 1 FROM currencies WHERE code = $1), 
+		EXISTS (SELECT 1 FROM balances WHERE player_name = $2)`, currencyCode, playerName).Scan(&currencyExists, &playerExists)
+	switch {
+	case err != nil:
+		return err
+	case !currencyExists:
+		return service.ErrIllegalCurrencyCode
+	case !playerExists:
+		return service.ErrNotEnoughMoneyCode
+	}
+
+	return service.ErrWalletNotFound
 }
@@ -0,0 +1,108 @@
+package postgres
+
+import (
+	"context"
+	"github.com/lib/pq"
+	"seamless-api-wrapper/internal/model"
+	"seamless-api-wrapper/internal/service"
+)
+
+const selectTransactionQuery = `SELECT 
+		transactions.*,
+		balances.player_name,
+		currencies.code currency
+	FROM transactions 
+	JOIN balances ON transactions.balance_id = balances.id 
+	JOIN currencies ON balances.currency_id = currencies.id `
+
+func (s *SeamlessService) TransactionHistory(ctx context.Context, filter *model.TransactionFilter) ([]model.TransactionRecord, error) {
+	var limit *int
+	if filter.Limit > 0 {
+		limit = &filter.Limit
+	}
+
+	// The wallets are resolved first, so a page is selected by balance_id and
+	// id, the columns of transactions_balance_idx.
+	var balanceIDs []int64
+	err := s.db.SelectContext(ctx, &balanceIDs, `SELECT balances.id 
+	FROM balances 
+	JOIN currencies ON balances.currency_id = currencies.id 
+	WHERE balances.player_name = $1 AND ($2::varchar IS NULL OR currencies.code = $2)`,
+		filter.PlayerName, filter.Currency)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(balanceIDs) == 0 {
+		return nil, nil
+	}
+
+	var records []model.TransactionRecord
+	err = s.db.SelectContext(ctx, &records, selectTransactionQuery+
+		`WHERE transactions.balance_id = ANY($1) 
+	AND ($2 = 0 OR transactions.id < $2) 
+	AND ($3::timestamp IS NULL OR transactions.created_at >= $3) 
+	AND ($4::timestamp IS NULL OR transactions.created_at < $4) 
+	AND ($5::varchar IS NULL OR transactions.game_id = $5) 
+	AND ($6::varchar IS NULL OR transactions.game_round_ref = $6) 
+	AND ($7::varchar IS NULL OR transactions.bonus_id = $7) 
+	AND ($8::varchar IS NULL OR transactions.status = $8) 
+	ORDER BY transactions.id DESC 
+	LIMIT $9`,
+		pq.Array(balanceIDs), filter.BeforeID, filter.From, filter.To, filter.GameID,
+		filter.GameRoundRef, filter.BonusID, filter.Status, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.loadSpinDetails(ctx, records); err != nil {
+		return nil, err
+	}
+
+	return records, nil
+}
+
+func (s *SeamlessService) TransactionByRef(ctx context.Context, transactionRef string) (*model.TransactionRecord, error) {
+	var records []model.TransactionRecord
+	err := s.db.SelectContext(ctx, &records, selectTransactionQuery+`WHERE transactions.transaction_ref = $1`, transactionRef)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(records) == 0 {
+		return nil, service.ErrTransactionNotFound
+	}
+
+	if err := s.loadSpinDetails(ctx, records); err != nil {
+		return nil, err
+	}
+
+	return &records[0], nil
+}
+
+// loadSpinDetails attaches their spin details to the records.
+func (s *SeamlessService) loadSpinDetails(ctx context.Context, records []model.TransactionRecord) error {
+	if len(records) == 0 {
+		return nil
+	}
+
+	ids := make([]int64, len(records))
+	byID := make(map[int]*model.TransactionRecord, len(records))
+	for i := range records {
+		ids[i] = int64(records[i].ID)
+		byID[records[i].ID] = &records[i]
+	}
+
+	var spinDetails []model.SpinDetails
+	err := s.db.SelectContext(ctx, &spinDetails, `SELECT id, transaction_id, coalesce(bet_type, '') bet_type, coalesce(win_type, '') win_type 
+	FROM spin_details WHERE transaction_id = ANY($1)`, pq.Array(ids))
+	if err != nil {
+		return err
+	}
+
+	for i := range spinDetails {
+		byID[spinDetails[i].TransactionID].SpinDetails = &spinDetails[i]
+	}
+
+	return nil
+}
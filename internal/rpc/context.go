@@ -0,0 +1,56 @@
+package rpc
+
+import (
+	"context"
+	"strings"
+)
+
+type allowedMethodsKey struct{}
+
+type peerKey struct{}
+
+// WithAllowedMethods restricts the methods Resolve will dispatch for ctx,
+// any other method is answered as not found.
+func WithAllowedMethods(ctx context.Context, methods []string) context.Context {
+	allowed := make(map[string]struct{}, len(methods))
+	for _, method := range methods {
+		allowed[strings.ToLower(method)] = struct{}{}
+	}
+
+	return context.WithValue(ctx, allowedMethodsKey{}, allowed)
+}
+
+func methodAllowed(ctx context.Context, name []byte) bool {
+	allowed, ok := ctx.Value(allowedMethodsKey{}).(map[string]struct{})
+	if !ok {
+		return true
+	}
+
+	_, ok = allowed[strings.ToLower(string(name))]
+	return ok
+}
+
+// WithPeer stores the transport level address of the caller.
+func WithPeer(ctx context.Context, addr string) context.Context {
+	return context.WithValue(ctx, peerKey{}, addr)
+}
+
+// Peer returns the address stored by WithPeer.
+func Peer(ctx context.Context) string {
+	addr, _ := ctx.Value(peerKey{}).(string)
+	return addr
+}
+
+type userKey struct{}
+
+// WithUser stores the name of the authenticated caller.
+func WithUser(ctx context.Context, name string) context.Context {
+	return context.WithValue(ctx, userKey{}, name)
+}
+
+// User returns the name stored by WithUser, empty when the transport does
+// not authenticate.
+func User(ctx context.Context) string {
+	name, _ := ctx.Value(userKey{}).(string)
+	return name
+}
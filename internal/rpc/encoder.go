@@ -0,0 +1,134 @@
+package rpc
+
+import (
+	"encoding/json"
+	"strconv"
+	"sync"
+	"unicode/utf8"
+)
+
+const hex = "0123456789abcdef"
+
+var encoderPool = sync.Pool{
+	New: func() any {
+		return &encoder{buf: make([]byte, 0, 512)}
+	},
+}
+
+// encoder writes response objects straight into a pooled buffer instead of
+// building a BaseResponse and passing it through json.Marshal.
+type encoder struct {
+	buf []byte
+}
+
+func getEncoder() *encoder {
+	e := encoderPool.Get().(*encoder)
+	e.buf = e.buf[:0]
+	return e
+}
+
+func putEncoder(e *encoder) {
+	if cap(e.buf) > 64*1024 {
+		return
+	}
+	encoderPool.Put(e)
+}
+
+func (e *encoder) result(id, result []byte) {
+	e.buf = append(e.buf, `{"jsonrpc":"`+Version+`","result":`...)
+	if len(result) == 0 {
+		e.buf = append(e.buf, "null"...)
+	} else {
+		e.buf = append(e.buf, result...)
+	}
+	e.id(id)
+}
+
+func (e *encoder) error(id []byte, err error) {
+	code, message := InternalErrorCode, ""
+	var data interface{}
+	switch t := err.(type) {
+	case *Error:
+		code, message, data = t.Code, t.Message, t.Data
+	default:
+		message = err.Error()
+	}
+
+	e.buf = append(e.buf, `{"jsonrpc":"`+Version+`","error":{"code":`...)
+	e.buf = strconv.AppendInt(e.buf, int64(code), 10)
+	e.buf = append(e.buf, `,"message":`...)
+	e.buf = appendString(e.buf, message)
+	if data != nil {
+		// Data that can not be encoded is left out rather than failing the
+		// whole response.
+		if raw, err := json.Marshal(data); err == nil {
+			e.buf = append(e.buf, `,"data":`...)
+			e.buf = append(e.buf, raw...)
+		}
+	}
+	e.buf = append(e.buf, '}')
+	e.id(id)
+}
+
+func (e *encoder) id(id []byte) {
+	e.buf = append(e.buf, `,"id":`...)
+	if len(id) == 0 {
+		e.buf = append(e.buf, "null"...)
+	} else {
+		e.buf = append(e.buf, id...)
+	}
+	e.buf = append(e.buf, '}')
+}
+
+// appendString quotes s the same way encoding/json does, including the
+// HTML-safe escapes, so responses stay byte-compatible with json.Marshal.
+func appendString(buf []byte, s string) []byte {
+	buf = append(buf, '"')
+	start := 0
+	for i := 0; i < len(s); {
+		if b := s[i]; b < utf8.RuneSelf {
+			if b >= 0x20 && b != '"' && b != '\\' && b != '<' && b != '>' && b != '&' {
+				i++
+				continue
+			}
+
+			buf = append(buf, s[start:i]...)
+			switch b {
+			case '"', '\\':
+				buf = append(buf, '\\', b)
+			case '\n':
+				buf = append(buf, '\\', 'n')
+			case '\r':
+				buf = append(buf, '\\', 'r')
+			case '\t':
+				buf = append(buf, '\\', 't')
+			default:
+				buf = append(buf, '\\', 'u', '0', '0', hex[b>>4], hex[b&0xF])
+			}
+			i++
+			start = i
+			continue
+		}
+
+		c, size := utf8.DecodeRuneInString(s[i:])
+		if c == utf8.RuneError && size == 1 {
+			buf = append(buf, s[start:i]...)
+			buf = append(buf, "\ufffd"...)
+			i += size
+			start = i
+			continue
+		}
+
+		if c == '\u2028' || c == '\u2029' {
+			buf = append(buf, s[start:i]...)
+			buf = append(buf, '\\', 'u', '2', '0', '2', hex[c&0xF])
+			i += size
+			start = i
+			continue
+		}
+
+		i += size
+	}
+	buf = append(buf, s[start:]...)
+	return append(buf, '"')
+}
@@ -0,0 +1,197 @@
+package rpc
+
+import (
+	"bytes"
+	"encoding/json"
+)
+
+var (
+	keyJsonRPC = []byte("jsonrpc")
+	keyMethod  = []byte("method")
+	keyParams  = []byte("params")
+	keyId      = []byte("id")
+	version    = []byte(`"` + Version + `"`)
+)
+
+// envelope is the scanned form of a request object. Every field is a slice
+// of the request buffer, so it is only valid until Resolve returns.
+type envelope struct {
+	version []byte
+	method  []byte
+	params  []byte
+	id      []byte
+}
+
+// decodeEnvelope scans the top level keys of a request object without
+// unmarshalling it. data must hold syntactically valid JSON, Resolve checks
+// it once for the whole body before any envelope is scanned.
+func decodeEnvelope(data []byte, env *envelope) error {
+	*env = envelope{}
+
+	i := skipSpace(data, 0)
+	if i >= len(data) || data[i] != '{' {
+		return InvalidReqError
+	}
+
+	i = skipSpace(data, i+1)
+	for i < len(data) && data[i] != '}' {
+		keyEnd := skipString(data, i)
+		key := data[i+1 : keyEnd-1]
+
+		i = skipSpace(data, keyEnd)
+		i = skipSpace(data, i+1) // ':'
+
+		valueEnd := skipValue(data, i)
+		value := data[i:valueEnd]
+
+		switch {
+		case matchKey(key, keyJsonRPC):
+			env.version = value
+		case matchKey(key, keyMethod):
+			env.method = value
+		case matchKey(key, keyParams):
+			env.params = value
+		case matchKey(key, keyId):
+			env.id = value
+		}
+
+		i = skipSpace(data, valueEnd)
+		if i < len(data) && data[i] == ',' {
+			i = skipSpace(data, i+1)
+		}
+	}
+
+	return nil
+}
+
+// methodName returns the method as a slice of the request buffer. Escaped
+// names are rare enough to fall back to json.Unmarshal.
+func (env *envelope) methodName() ([]byte, bool) {
+	m := env.method
+	if len(m) < 2 || m[0] != '"' {
+		return nil, false
+	}
+
+	name := m[1 : len(m)-1]
+	if bytes.IndexByte(name, '\\') == -1 {
+		return name, true
+	}
+
+	var s string
+	if err := json.Unmarshal(m, &s); err != nil {
+		return nil, false
+	}
+
+	return []byte(s), true
+}
+
+func (env *envelope) validate() error {
+	if !bytes.Equal(env.version, version) {
+		return InvalidReqError
+	}
+
+	if name, ok := env.methodName(); !ok || len(name) == 0 {
+		return InvalidReqError
+	}
+
+	return nil
+}
+
+// matchKey follows encoding/json and compares keys case-insensitively.
+func matchKey(key, name []byte) bool {
+	if bytes.Equal(key, name) {
+		return true
+	}
+
+	if bytes.IndexByte(key, '\\') != -1 {
+		var s string
+		if err := json.Unmarshal(append(append([]byte{'"'}, key...), '"'), &s); err != nil {
+			return false
+		}
+		key = []byte(s)
+	}
+
+	return bytes.EqualFold(key, name)
+}
+
+// splitBatch appends every element of a valid JSON array to items.
+func splitBatch(data []byte, items [][]byte) [][]byte {
+	i := skipSpace(data, 0)
+	if i >= len(data) || data[i] != '[' {
+		return items
+	}
+
+	i = skipSpace(data, i+1)
+	for i < len(data) && data[i] != ']' {
+		end := skipValue(data, i)
+		items = append(items, data[i:end])
+
+		i = skipSpace(data, end)
+		if i < len(data) && data[i] == ',' {
+			i = skipSpace(data, i+1)
+		}
+	}
+
+	return items
+}
+
+func skipSpace(data []byte, i int) int {
+	for i < len(data) {
+		switch data[i] {
+		case ' ', '\t', '\r', '\n':
+			i++
+		default:
+			return i
+		}
+	}
+	return i
+}
+
+// skipString returns the offset right after the string starting at data[i].
+func skipString(data []byte, i int) int {
+	for i++; i < len(data); i++ {
+		switch data[i] {
+		case '\\':
+			i++
+		case '"':
+			return i + 1
+		}
+	}
+	return i
+}
+
+// skipValue returns the offset right after the value starting at data[i].
+func skipValue(data []byte, i int) int {
+	if i >= len(data) {
+		return i
+	}
+
+	switch data[i] {
+	case '"':
+		return skipString(data, i)
+	case '{', '[':
+		depth := 0
+		for ; i < len(data); i++ {
+			switch data[i] {
+			case '"':
+				i = skipString(data, i) - 1
+			case '{', '[':
+				depth++
+			case '}', ']':
+				depth--
+				if depth == 0 {
+					return i + 1
+				}
+			}
+		}
+		return i
+	default:
+		for ; i < len(data); i++ {
+			switch data[i] {
+			case ',', '}', ']', ' ', '\t', '\r', '\n':
+				return i
+			}
+		}
+		return i
+	}
+}
@@ -19,6 +19,10 @@ type BaseResponse struct {
 type Error struct {
 	Code    ErrorCode `json:"code"`
 	Message string    `json:"message"`
+	// Data is optional detail about the error, encoded with encoding/json.
+	Data interface{} `json:"data,omitempty"`
+	// Status is an optional HTTP status a transport may answer with.
+	Status int `json:"-"`
 }
 
 func (e *Error) Error() string {
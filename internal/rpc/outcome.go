@@ -0,0 +1,61 @@
+package rpc
+
+type OutcomeKind int
+
+const (
+	OutcomeOK OutcomeKind = iota
+	// OutcomeNotification means nothing was written, every call in the
+	// request was a notification.
+	OutcomeNotification
+	OutcomeParseError
+	OutcomeInvalidRequest
+	OutcomeInternalError
+	// OutcomeError is a single call answered with an application error.
+	OutcomeError
+)
+
+func (k OutcomeKind) String() string {
+	switch k {
+	case OutcomeNotification:
+		return "notification"
+	case OutcomeParseError:
+		return "parse-error"
+	case OutcomeInvalidRequest:
+		return "invalid-request"
+	case OutcomeInternalError:
+		return "internal-error"
+	case OutcomeError:
+		return "error"
+	}
+	return "ok"
+}
+
+// Outcome summarises how a request was resolved so that a transport can
+// choose its own status code after the handlers have run.
+type Outcome struct {
+	Kind OutcomeKind
+	// Status is the HTTP status attached to the error of a single call, zero
+	// when the error has none.
+	Status int
+}
+
+func (o Outcome) String() string {
+	return o.Kind.String()
+}
+
+func errorOutcome(err error) Outcome {
+	e, ok := err.(*Error)
+	if !ok {
+		return Outcome{Kind: OutcomeInternalError}
+	}
+
+	switch {
+	case e.Code == ParseErrorCode:
+		return Outcome{Kind: OutcomeParseError, Status: e.Status}
+	case e.Code == InvalidRequestCode:
+		return Outcome{Kind: OutcomeInvalidRequest, Status: e.Status}
+	case e.Code == InternalErrorCode, e.Code <= ServerErrorCode && e.Code > ServerErrorCode-100:
+		return Outcome{Kind: OutcomeInternalError, Status: e.Status}
+	}
+	return Outcome{Kind: OutcomeError, Status: e.Status}
+}
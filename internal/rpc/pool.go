@@ -1,21 +1,28 @@
 package rpc
 
 import (
+	"bytes"
 	"sync"
 )
 
+const maxPooledBuffer = 1 << 20
+
 type reqPool struct {
 	pool sync.Pool
 }
 
-func (p *reqPool) get() []byte {
-	msg := p.pool.Get()
-	if msg == nil {
-		return make([]byte, 4096)
+func (p *reqPool) get() *bytes.Buffer {
+	buf := p.pool.Get()
+	if buf == nil {
+		return bytes.NewBuffer(make([]byte, 0, 4096))
 	}
-	return msg.([]byte)
+	return buf.(*bytes.Buffer)
 }
 
-func (p *reqPool) put(data []byte) {
-	p.pool.Put(data)
+func (p *reqPool) put(buf *bytes.Buffer) {
+	if buf.Cap() > maxPooledBuffer {
+		return
+	}
+	buf.Reset()
+	p.pool.Put(buf)
 }
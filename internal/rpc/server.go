@@ -1,7 +1,6 @@
 package rpc
 
 import (
-	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
@@ -14,10 +13,11 @@ import (
 const Version = "2.0"
 
 type server struct {
-	method    map[string]HandlerFunc
-	lock      sync.RWMutex
-	transport Transport
-	reqPool   *reqPool
+	method     map[string]HandlerFunc
+	lock       sync.RWMutex
+	transport  Transport
+	reqPool    *reqPool
+	middleware []Middleware
 }
 
 func NewServer(transport Transport) *server {
@@ -29,200 +29,197 @@ func NewServer(transport Transport) *server {
 }
 
 func (s *server) Run(ctx context.Context) error {
-	if err := s.transport.Run(ctx, s); err != nil {
+	var resolver Resolver = s
+	for i := len(s.middleware) - 1; i >= 0; i-- {
+		resolver = s.middleware[i](resolver)
+	}
+
+	if err := s.transport.Run(ctx, resolver); err != nil {
 		return err
 	}
 
 	return nil
 }
 
+// Use adds middleware around the resolver handed to the transport, the first
+// one added is the outermost.
+func (s *server) Use(m Middleware) {
+	s.middleware = append(s.middleware, m)
+}
+
+// Register adds a method handler. Names are matched case-insensitively, the
+// handler is stored under both the registered and the lower-cased name so the
+// common case is looked up without allocating.
 func (s *server) Register(name string, f HandlerFunc) {
 	s.lock.Lock()
 	defer s.lock.Unlock()
 
-	if _, ok := s.method[name]; ok {
+	lower := strings.ToLower(name)
+	if _, ok := s.method[lower]; ok {
 		log.Fatal(fmt.Sprintf("method '%s' alredey exists", name))
 	}
 
-	s.method[strings.ToLower(name)] = f
+	s.method[lower] = f
+	s.method[name] = f
 }
 
-func (s *server) Resolve(ctx context.Context, w io.Writer, r io.Reader) {
-	data := s.reqPool.get()
-	defer s.reqPool.put(data)
+// Resolve reads a single request or a batch from r and writes the response
+// to w. Params passed to handlers are slices of a pooled buffer and must not
+// be retained after the handler returns. The returned Outcome describes the
+// single call, or the batch envelope as a whole.
+func (s *server) Resolve(ctx context.Context, w io.Writer, r io.Reader) Outcome {
+	buffer := s.reqPool.get()
+	defer s.reqPool.put(buffer)
 
-	buffer := bytes.NewBuffer(data[:0])
-	_, err := io.Copy(buffer, r)
-	if err != nil {
-		writeError(w, nil, err)
-		return
-	}
-
-	reqData := bytes.TrimLeft(buffer.Bytes(), " \t\r\n")
-	var response []byte
-	switch {
-	case len(reqData) > 0 && reqData[0] == '[':
-		var batch []*BaseRequest
-		if err := json.Unmarshal(reqData, &batch); err != nil {
-			writeError(w, nil, ParseError)
-			return
-		}
+	if _, err := buffer.ReadFrom(r); err != nil {
+		return writeError(w, nil, err)
+	}
 
-		if len(batch) == 0 {
-			writeError(w, nil, InvalidReqError)
-			return
-		}
+	reqData := buffer.Bytes()
+	i := skipSpace(reqData, 0)
+	if i == len(reqData) || (reqData[i] != '[' && reqData[i] != '{') {
+		return writeError(w, nil, InvalidReqError)
+	}
 
-		result, err := s.batchReader(ctx, batch)
-		if err != nil {
-			writeError(w, nil, err)
-			return
-		}
+	if !json.Valid(reqData) {
+		return writeError(w, nil, ParseError)
+	}
 
-		if len(result) == 0 {
-			return
-		}
+	if reqData[i] == '{' {
+		enc := getEncoder()
+		defer putEncoder(enc)
 
-		if response, err = json.Marshal(result); err != nil {
-			writeError(w, nil, err)
-			return
-		}
-	case len(reqData) > 0 && reqData[0] == '{':
-		var req BaseRequest
-		if err := json.Unmarshal(reqData, &req); err != nil {
-			writeError(w, nil, ParseError)
-			return
+		outcome := s.singleReader(ctx, enc, reqData)
+		if outcome.Kind == OutcomeNotification {
+			return outcome
 		}
 
-		result, ok := s.singleReader(ctx, &req)
-		if !ok {
-			return
+		if _, err := w.Write(enc.buf); err != nil {
+			log.Error(err)
 		}
-
-		if response, err = json.Marshal(result); err != nil {
-			writeError(w, nil, err)
-			return
-		}
-	default:
-		writeError(w, nil, InvalidReqError)
+		return outcome
 	}
 
-	if _, err := w.Write(response); err != nil {
-		log.Error(err)
-	}
+	return s.batchReader(ctx, w, reqData)
 }
 
-func (s *server) singleReader(ctx context.Context, req *BaseRequest) (*BaseResponse, bool) {
-	if err := validateRequest(req); err != nil {
-		return errorResponse(req.Id, err), true
+// singleReader handles one request object and encodes its response into enc.
+// It reports OutcomeNotification when there is no response to send.
+func (s *server) singleReader(ctx context.Context, enc *encoder, data []byte) Outcome {
+	var env envelope
+	if err := decodeEnvelope(data, &env); err != nil {
+		enc.error(nil, err)
+		return errorOutcome(err)
+	}
+
+	if err := env.validate(); err != nil {
+		enc.error(env.id, err)
+		return errorOutcome(err)
 	}
 
-	h, err := s.getHandler(req)
+	h, err := s.getHandler(ctx, &env)
 	if err != nil {
-		return errorResponse(req.Id, err), true
+		enc.error(env.id, err)
+		return errorOutcome(err)
 	}
 
-	result, err := h(ctx, req.Params)
+	result, err := h(ctx, env.params)
 	if err != nil {
-		return errorResponse(req.Id, err), true
+		enc.error(env.id, err)
+		return errorOutcome(err)
 	}
 
-	if len(req.Id) == 0 {
-		return nil, false
+	if len(env.id) == 0 {
+		return Outcome{Kind: OutcomeNotification}
 	}
 
-	return successResponse(req.Id, result), true
+	enc.result(env.id, result)
+	return Outcome{Kind: OutcomeOK}
 }
 
-func (s *server) batchReader(ctx context.Context, batch []*BaseRequest) ([]*BaseResponse, error) {
-	var resultLock sync.Mutex
+func (s *server) batchReader(ctx context.Context, w io.Writer, data []byte) Outcome {
+	var items [16][]byte
+	batch := splitBatch(data, items[:0])
+
+	if len(batch) == 0 {
+		return writeError(w, nil, InvalidReqError)
+	}
+
 	var wg sync.WaitGroup
 
-	result := make([]*BaseResponse, 0, len(batch))
+	encoders := make([]*encoder, len(batch))
 	for i := range batch {
-		baseReq := batch[i]
-
 		wg.Add(1)
-		go func(req *BaseRequest) {
+		go func(i int) {
 			defer wg.Done()
 
-			response, ok := s.singleReader(ctx, req)
-			if !ok {
+			enc := getEncoder()
+			if s.singleReader(ctx, enc, batch[i]).Kind == OutcomeNotification {
+				putEncoder(enc)
 				return
 			}
 
-			resultLock.Lock()
-			result = append(result, response)
-			resultLock.Unlock()
-		}(baseReq)
+			encoders[i] = enc
+		}(i)
 	}
 
 	wg.Wait()
 
-	return result, nil
-}
+	out := getEncoder()
+	defer putEncoder(out)
 
-func (s *server) getHandler(r *BaseRequest) (HandlerFunc, error) {
-	s.lock.RLock()
-	h, ok := s.method[strings.ToLower(r.Method)]
-	s.lock.RUnlock()
+	for _, enc := range encoders {
+		if enc == nil {
+			continue
+		}
 
-	if !ok {
-		return nil, MethodNotFoundError
+		if len(out.buf) == 0 {
+			out.buf = append(out.buf, '[')
+		} else {
+			out.buf = append(out.buf, ',')
+		}
+		out.buf = append(out.buf, enc.buf...)
+		putEncoder(enc)
 	}
 
-	return h, nil
-}
-
-func writeError(w io.Writer, id json.RawMessage, err error) {
-	resp := errorResponse(id, err)
-	data, err := json.Marshal(resp)
-	if err != nil {
-		log.Error(err)
+	if len(out.buf) == 0 {
+		return Outcome{Kind: OutcomeNotification}
 	}
+	out.buf = append(out.buf, ']')
 
-	if _, err := w.Write(data); err != nil {
+	if _, err := w.Write(out.buf); err != nil {
 		log.Error(err)
 	}
+	return Outcome{Kind: OutcomeOK}
 }
 
-func errorResponse(id json.RawMessage, err error) *BaseResponse {
-	resp := &BaseResponse{
-		Version: Version,
-		Id:      id,
+func (s *server) getHandler(ctx context.Context, env *envelope) (HandlerFunc, error) {
+	name, _ := env.methodName()
+	if !methodAllowed(ctx, name) {
+		return nil, MethodNotFoundError
 	}
 
-	switch t := err.(type) {
-	case *Error:
-		resp.Error = t
-	default:
-		resp.Error = &Error{
-			Code:    InternalErrorCode,
-			Message: err.Error(),
-		}
+	s.lock.RLock()
+	h, ok := s.method[string(name)]
+	if !ok {
+		h, ok = s.method[strings.ToLower(string(name))]
 	}
+	s.lock.RUnlock()
 
-	return resp
-}
-
-func successResponse(id json.RawMessage, resp json.RawMessage) *BaseResponse {
-	baseResp := &BaseResponse{
-		Version: Version,
-		Id:      id,
-		Result:  resp,
+	if !ok {
+		return nil, MethodNotFoundError
 	}
 
-	return baseResp
+	return h, nil
 }
 
-func validateRequest(req *BaseRequest) error {
-	if req.JsonRPC != Version {
-		return InvalidReqError
-	}
+func writeError(w io.Writer, id json.RawMessage, err error) Outcome {
+	enc := getEncoder()
+	defer putEncoder(enc)
 
-	if len(req.Method) == 0 {
-		return InvalidReqError
+	enc.error(id, err)
+	if _, err := w.Write(enc.buf); err != nil {
+		log.Error(err)
 	}
-
-	return nil
+	return errorOutcome(err)
 }
@@ -4,6 +4,8 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
+	"strings"
 	"testing"
 )
 
@@ -187,3 +189,235 @@ func TestResolveBatch(t *testing.T) {
 		t.Errorf("got %q, expected %q", out.String(), expected)
 	}
 }
+
+func TestResolveEnvelope(t *testing.T) {
+	var srv = NewServer(&TestTransport{})
+	srv.Register("subtract", Handler(subtract))
+
+	cases := []struct {
+		in       string
+		expected string
+	}{
+		{
+			in:       `{"id": "a", "params": [42, 23], "method": "Subtract", "jsonrpc": "2.0"}`,
+			expected: `{"jsonrpc":"2.0","result":19,"id":"a"}`,
+		},
+		{
+			in:       `{"jsonrpc": "2.0", "method": "subtract", "params": [42, 23], "id": {"nested": [1, "]"]}}`,
+			expected: `{"jsonrpc":"2.0","result":19,"id":{"nested": [1, "]"]}}`,
+		},
+		{
+			in:       `{"JSONRPC": "2.0", "Method": "subtract", "params": [42, 23], "ID": 5}`,
+			expected: `{"jsonrpc":"2.0","result":19,"id":5}`,
+		},
+		{
+			in:       `{"jsonrpc": "1.0", "method": "subtract", "params": [42, 23], "id": 6}`,
+			expected: `{"jsonrpc":"2.0","error":{"code":-32600,"message":"invalid request"},"id":6}`,
+		},
+		{
+			in:       `{"jsonrpc": "2.0", "method": 1, "params": [42, 23], "id": 7}`,
+			expected: `{"jsonrpc":"2.0","error":{"code":-32600,"message":"invalid request"},"id":7}`,
+		},
+		{
+			in:       `{"jsonrpc": "2.0", "method": "subtract", "params": {"a": 1}, "id": 8}`,
+			expected: `{"jsonrpc":"2.0","error":{"code":-32602,"message":"invalid method parameters"},"id":8}`,
+		},
+		{
+			in:       `[1, {"jsonrpc": "2.0", "method": "subtract", "params": [2, 1], "id": 9}]`,
+			expected: `[{"jsonrpc":"2.0","error":{"code":-32600,"message":"invalid request"},"id":null},{"jsonrpc":"2.0","result":1,"id":9}]`,
+		},
+		{
+			in:       `"subtract"`,
+			expected: `{"jsonrpc":"2.0","error":{"code":-32600,"message":"invalid request"},"id":null}`,
+		},
+	}
+
+	ctx := context.Background()
+	for _, c := range cases {
+		out := bytes.NewBuffer([]byte{})
+		srv.Resolve(ctx, out, bytes.NewReader([]byte(c.in)))
+
+		if out.String() != c.expected {
+			t.Errorf("got %q, expected %q", out.String(), c.expected)
+		}
+	}
+}
+
+func failing(_ context.Context, _ []int) (int, error) {
+	return 0, errors.New("connection refused")
+}
+
+func TestResolveOutcome(t *testing.T) {
+	var srv = NewServer(&TestTransport{})
+	srv.Register("subtract", Handler(subtract))
+	srv.Register("failing", Handler(failing))
+
+	cases := map[string]OutcomeKind{
+		`{"jsonrpc": "2.0", "method": "subtract", "params": [42, 23], "id": 1}`: OutcomeOK,
+		`{"jsonrpc": "2.0", "method": "subtract", "params": [42, 23]}`:          OutcomeNotification,
+		`{"jsonrpc": "2.0", "method": "sum", "params": [42, 23], "id": 1}`:      OutcomeError,
+		`{"jsonrpc": "2.0", "method": "failing", "params": [], "id": 1}`:        OutcomeInternalError,
+		`{"jsonrpc": "2.0", "method": "subtract", "params": [42, 23], "id": 1`:  OutcomeParseError,
+		`{"jsonrpc": "1.0", "method": "subtract", "params": [42, 23], "id": 1}`: OutcomeInvalidRequest,
+		`[]`: OutcomeInvalidRequest,
+		`[{"jsonrpc": "2.0", "method": "subtract", "params": [1, 2]}]`:                 OutcomeNotification,
+		`[{"jsonrpc": "2.0", "method": "failing", "params": [], "id": 1}, {"foo": 1}]`: OutcomeOK,
+	}
+
+	ctx := context.Background()
+	for in, expected := range cases {
+		out := bytes.NewBuffer([]byte{})
+		if outcome := srv.Resolve(ctx, out, bytes.NewReader([]byte(in))); outcome.Kind != expected {
+			t.Errorf("%s: got %s, expected %s", in, outcome, expected)
+		}
+	}
+}
+
+func TestResolveAllowedMethods(t *testing.T) {
+	var srv = NewServer(&TestTransport{})
+	srv.Register("subtract", Handler(subtract))
+	srv.Register("getBalance", HandlerWithPointer(getBalance))
+
+	ctx := WithAllowedMethods(context.Background(), []string{"getBalance"})
+
+	jsonObj := `{"jsonrpc": "2.0", "method": "subtract", "params": [42, 23], "id": 1}`
+	expected := `{"jsonrpc":"2.0","error":{"code":-32601,"message":"The method does not exist."},"id":1}`
+
+	out := bytes.NewBuffer([]byte{})
+	srv.Resolve(ctx, out, bytes.NewReader([]byte(jsonObj)))
+
+	if out.String() != expected {
+		t.Errorf("got %q, expected %q", out.String(), expected)
+	}
+
+	jsonObj = `{"jsonrpc": "2.0", "method": "GetBalance", "params": {"callerId": 1}, "id": 2}`
+	expected = `{"jsonrpc":"2.0","result":{"balance":10000},"id":2}`
+
+	out.Reset()
+	srv.Resolve(ctx, out, bytes.NewReader([]byte(jsonObj)))
+
+	if out.String() != expected {
+		t.Errorf("got %q, expected %q", out.String(), expected)
+	}
+}
+
+func TestEncoderMatchesMarshal(t *testing.T) {
+	messages := []string{"plain", `quote " and \ slash`, "<tag> & amp", "tab\tline\n", "\x01", "bad \xff utf8", "sep    ", "юникод"}
+
+	for _, message := range messages {
+		enc := getEncoder()
+		enc.error(json.RawMessage("1"), &Error{Code: 1, Message: message})
+
+		expected, err := json.Marshal(&BaseResponse{Version: Version, Error: &Error{Code: 1, Message: message}, Id: json.RawMessage("1")})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if string(enc.buf) != string(expected) {
+			t.Errorf("got %q, expected %q", enc.buf, expected)
+		}
+		putEncoder(enc)
+	}
+
+	withData := &Error{Code: 5, Message: "limit", Data: map[string]interface{}{"limit": "daily", "amount": 100}}
+
+	enc := getEncoder()
+	enc.error(json.RawMessage("1"), withData)
+
+	expected, err := json.Marshal(&BaseResponse{Version: Version, Error: withData, Id: json.RawMessage("1")})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(enc.buf) != string(expected) {
+		t.Errorf("got %q, expected %q", enc.buf, expected)
+	}
+	putEncoder(enc)
+}
+
+type balanceData struct {
+	CallerId   int     `json:"callerId"`
+	PlayerName string  `json:"playerName"`
+	Currency   string  `json:"currency"`
+	GameId     *string `json:"gameId"`
+}
+
+type balanceResult struct {
+	Balance int `json:"balance"`
+}
+
+func getBalance(_ context.Context, _ *balanceData) (*balanceResult, error) {
+	return &balanceResult{Balance: 10000}, nil
+}
+
+const getBalanceReq = `{"jsonrpc":"2.0","method":"getBalance","params":{"callerId":1,"playerName":"player1","currency":"EUR","gameId":"riot"},"id":0}`
+
+func benchmarkResolve(b *testing.B, req []byte) {
+	var srv = NewServer(&TestTransport{})
+	srv.Register("getBalance", HandlerWithPointer(getBalance))
+
+	out := bytes.NewBuffer(make([]byte, 0, 4096))
+	in := bytes.NewReader(req)
+	ctx := context.Background()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		out.Reset()
+		in.Reset(req)
+		srv.Resolve(ctx, out, in)
+	}
+}
+
+func BenchmarkResolveGetBalance(b *testing.B) {
+	benchmarkResolve(b, []byte(getBalanceReq))
+}
+
+func BenchmarkResolveMethodNotFound(b *testing.B) {
+	benchmarkResolve(b, []byte(`{"jsonrpc":"2.0","method":"unknown","params":{},"id":0}`))
+}
+
+func BenchmarkResolveBatch(b *testing.B) {
+	batch := "[" + strings.Repeat(getBalanceReq+",", 9) + getBalanceReq + "]"
+	benchmarkResolve(b, []byte(batch))
+}
+
+func BenchmarkDecodeEnvelope(b *testing.B) {
+	data := []byte(getBalanceReq)
+	var env envelope
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_ = decodeEnvelope(data, &env)
+	}
+}
+
+func BenchmarkUnmarshalBaseRequest(b *testing.B) {
+	data := []byte(getBalanceReq)
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		var req BaseRequest
+		_ = json.Unmarshal(data, &req)
+	}
+}
+
+func BenchmarkEncodeResult(b *testing.B) {
+	id, result := json.RawMessage("0"), json.RawMessage(`{"balance":10000}`)
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		enc := getEncoder()
+		enc.result(id, result)
+		putEncoder(enc)
+	}
+}
+
+func BenchmarkMarshalBaseResponse(b *testing.B) {
+	id, result := json.RawMessage("0"), json.RawMessage(`{"balance":10000}`)
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_, _ = json.Marshal(&BaseResponse{Version: Version, Id: id, Result: result})
+	}
+}
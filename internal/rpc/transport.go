@@ -10,5 +10,16 @@ type Transport interface {
 }
 
 type Resolver interface {
-	Resolve(ctx context.Context, writer io.Writer, reader io.Reader)
+	Resolve(ctx context.Context, writer io.Writer, reader io.Reader) Outcome
+}
+
+// Middleware wraps a Resolver, it sees every request before the server and
+// every response after it.
+type Middleware func(Resolver) Resolver
+
+// ResolverFunc adapts a function to the Resolver interface.
+type ResolverFunc func(ctx context.Context, writer io.Writer, reader io.Reader) Outcome
+
+func (f ResolverFunc) Resolve(ctx context.Context, writer io.Writer, reader io.Reader) Outcome {
+	return f(ctx, writer, reader)
 }
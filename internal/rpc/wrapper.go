@@ -5,12 +5,13 @@ import (
 	"encoding/json"
 )
 
+// HandlerFunc gets the raw params of a call, nil when the call has none.
 type HandlerFunc func(context.Context, json.RawMessage) (json.RawMessage, error)
 
 func HandlerWithPointer[RQ any, RS any](handler func(context.Context, *RQ) (RS, error)) HandlerFunc {
 	return func(ctx context.Context, in json.RawMessage) (json.RawMessage, error) {
 		req := new(RQ)
-		if err := json.Unmarshal(in, req); err != nil {
+		if err := unmarshalParams(in, req); err != nil {
 			return nil, InvalidParamsError
 		}
 
@@ -25,7 +26,7 @@ func HandlerWithPointer[RQ any, RS any](handler func(context.Context, *RQ) (RS,
 func Handler[RQ any, RS any](handler func(context.Context, RQ) (RS, error)) HandlerFunc {
 	return func(ctx context.Context, in json.RawMessage) (json.RawMessage, error) {
 		var req RQ
-		if err := json.Unmarshal(in, &req); err != nil {
+		if err := unmarshalParams(in, &req); err != nil {
 			return nil, InvalidParamsError
 		}
 		resp, err := handler(ctx, req)
@@ -35,3 +36,11 @@ func Handler[RQ any, RS any](handler func(context.Context, RQ) (RS, error)) Hand
 		return json.Marshal(resp)
 	}
 }
+
+// unmarshalParams leaves req at its zero value when params are omitted.
+func unmarshalParams(in json.RawMessage, req any) error {
+	if len(in) == 0 {
+		return nil
+	}
+	return json.Unmarshal(in, req)
+}
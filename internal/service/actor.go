@@ -0,0 +1,17 @@
+package service
+
+import "context"
+
+type actorKey struct{}
+
+// WithActor stores who makes the changes done with ctx, it is written to the
+// balance history.
+func WithActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+// Actor returns the actor stored by WithActor, empty when there is none.
+func Actor(ctx context.Context) string {
+	actor, _ := ctx.Value(actorKey{}).(string)
+	return actor
+}
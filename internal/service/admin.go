@@ -0,0 +1,61 @@
+package service
+
+import (
+	"context"
+	"seamless-api-wrapper/internal/model"
+)
+
+// AdminService holds the back-office operations on wallets.
+//
+// CreateWallet opens an empty wallet in a registered currency, the first one
+// creates the player. A player has one wallet per currency, another one gives
+// ErrWalletExists. The wallet starts at zero, so no balance change is written.
+//
+// Adjust credits or debits the wallet's cash by hand with a reason, the
+// actor of the context is stored with it. A debit below zero gives
+// ErrNotEnoughMoneyCode. A manual_deposit above a deposit limit of the
+// wallet gives a *LimitError. Adjustments returns the player's adjustments
+// oldest first.
+//
+// TransactionByRef finds a transaction in any wallet, ErrTransactionNotFound
+// when there is none.
+type AdminService interface {
+	CreateWallet(ctx context.Context, playerName, currency string, gameID *string) (*model.Balance, error)
+	Adjust(ctx context.Context, playerName, currency string, adjustment *model.Adjustment) (*model.Balance, error)
+	Adjustments(ctx context.Context, playerName string) ([]model.Adjustment, error)
+	TransactionByRef(ctx context.Context, transactionRef string) (*model.TransactionRecord, error)
+}
+
+// CheckAdjustment validates an adjustment before it is applied. A
+// manual_deposit only credits and a manual_withdrawal only debits.
+func CheckAdjustment(adjustment *model.Adjustment) error {
+	if adjustment.Amount == 0 || !adjustment.Reason.Known() {
+		return ErrInvalidAdjustment
+	}
+
+	switch adjustment.Reason {
+	case model.AdjustmentDeposit:
+		if adjustment.Amount < 0 {
+			return ErrInvalidAdjustment
+		}
+	case model.AdjustmentWithdrawal:
+		if adjustment.Amount > 0 {
+			return ErrInvalidAdjustment
+		}
+	}
+	return nil
+}
+
+// Adjusted is the cash of the wallet after the adjustment.
+func Adjusted(balance *model.Balance, adjustment *model.Adjustment) (int64, error) {
+	amount, err := AddAmount(balance.Amount, adjustment.Amount)
+	if err != nil {
+		return 0, err
+	}
+
+	if amount < 0 {
+		return 0, ErrNotEnoughMoneyCode
+	}
+
+	return amount, nil
+}
@@ -0,0 +1,54 @@
+package service
+
+import (
+	"math"
+	"seamless-api-wrapper/internal/model"
+)
+
+// AddAmount adds delta to a balance in minor units and reports
+// ErrAmountOverflow instead of wrapping around.
+func AddAmount(amount, delta int64) (int64, error) {
+	if delta > 0 && amount > math.MaxInt64-delta {
+		return 0, ErrAmountOverflow
+	}
+
+	if delta < 0 && amount < math.MinInt64-delta {
+		return 0, ErrAmountOverflow
+	}
+
+	return amount + delta, nil
+}
+
+// Reverse takes a committed transaction back out of the wallet, free rounds
+// are given back with RefundFreeRounds. bonus is the active bonus the
+// transaction was wagered against, nil when there is none or it was converted
+// since, then its bonus money is taken from and paid to cash.
+func Reverse(balance *model.Balance, bonus *model.Bonus, transaction *model.Transaction) error {
+	if bonus == nil {
+		amount, err := AddAmount(balance.Amount-transaction.Deposit, transaction.Withdraw)
+		if err != nil {
+			return err
+		}
+
+		balance.Amount = amount
+		return nil
+	}
+
+	amount, err := AddAmount(balance.Amount-(transaction.Deposit-transaction.BonusDeposit), transaction.Withdraw-transaction.BonusWithdraw)
+	if err != nil {
+		return err
+	}
+
+	bonusAmount, err := AddAmount(bonus.Amount-transaction.BonusDeposit, transaction.BonusWithdraw)
+	if err != nil {
+		return err
+	}
+
+	balance.Amount = amount
+	balance.BonusAmount += bonusAmount - bonus.Amount
+
+	bonus.Amount = bonusAmount
+	bonus.Wagered -= transaction.Withdraw
+
+	return nil
+}
@@ -0,0 +1,121 @@
+package service
+
+import (
+	"context"
+	"seamless-api-wrapper/internal/model"
+	"time"
+)
+
+// SpendOrder selects which funds a bet carrying the bonusId of an active
+// bonus is paid from first.
+type SpendOrder string
+
+const (
+	CashFirst  SpendOrder = "cash-first"
+	BonusFirst SpendOrder = "bonus-first"
+)
+
+// BonusService manages bonus money. A bonus is granted to one wallet under a
+// bonusId that is unique in the wallet. A transaction carrying the bonusId of
+// an active bonus may spend its money, pays its win to the bonus and counts
+// its bet towards the wagering. Once the wagering is complete the bonus is
+// converted to cash.
+type BonusService interface {
+	GrantBonus(ctx context.Context, playerName, currency string, bonus *model.Bonus) error
+	Bonuses(ctx context.Context, playerName string) ([]model.Bonus, error)
+}
+
+// CheckBonus validates a bonus before it is stored.
+func CheckBonus(bonus *model.Bonus) error {
+	if bonus.BonusID == "" || bonus.Granted < 1 || bonus.Wagering < 1 {
+		return ErrInvalidBonus
+	}
+	return nil
+}
+
+// Apply moves the transaction's money in the wallet. Without a bonus the bet
+// and the win are cash. With an active bonus the bet is paid from cash and
+// bonus money in order, the win goes to the bonus and the whole bet counts
+// towards the wagering.
+func Apply(balance *model.Balance, bonus *model.Bonus, transaction *model.Transaction, order SpendOrder, now time.Time) error {
+	transaction.WageredBonusID = nil
+	transaction.BonusWithdraw = 0
+	transaction.BonusDeposit = 0
+	transaction.BonusConverted = 0
+
+	if bonus == nil {
+		amount := balance.Amount - transaction.Withdraw
+		if amount < 0 {
+			return ErrSpendingBudgetExceeded
+		}
+
+		amount, err := AddAmount(amount, transaction.Deposit)
+		if err != nil {
+			return err
+		}
+
+		balance.Amount = amount
+		return nil
+	}
+
+	fromBonus := transaction.Withdraw - balance.Amount
+	if order == BonusFirst {
+		fromBonus = transaction.Withdraw
+		if fromBonus > bonus.Amount {
+			fromBonus = bonus.Amount
+		}
+	}
+	if fromBonus < 0 {
+		fromBonus = 0
+	}
+
+	fromCash := transaction.Withdraw - fromBonus
+	if fromCash > balance.Amount || fromBonus > bonus.Amount {
+		return ErrSpendingBudgetExceeded
+	}
+
+	bonusAmount, err := AddAmount(bonus.Amount-fromBonus, transaction.Deposit)
+	if err != nil {
+		return err
+	}
+
+	wagered, err := AddAmount(bonus.Wagered, transaction.Withdraw)
+	if err != nil {
+		return err
+	}
+
+	balance.Amount -= fromCash
+	balance.BonusAmount += bonusAmount - bonus.Amount
+
+	bonus.Amount = bonusAmount
+	bonus.Wagered = wagered
+	bonus.UpdatedAt = now
+
+	transaction.WageredBonusID = &bonus.ID
+	transaction.BonusWithdraw = fromBonus
+	transaction.BonusDeposit = transaction.Deposit
+
+	if bonus.Wagered >= bonus.Wagering {
+		return convert(balance, bonus, transaction, now)
+	}
+
+	return nil
+}
+
+// convert moves the money of a bonus with complete wagering to cash.
+func convert(balance *model.Balance, bonus *model.Bonus, transaction *model.Transaction, now time.Time) error {
+	amount, err := AddAmount(balance.Amount, bonus.Amount)
+	if err != nil {
+		return err
+	}
+
+	balance.Amount = amount
+	balance.BonusAmount -= bonus.Amount
+	transaction.BonusConverted = bonus.Amount
+
+	bonus.Amount = 0
+	bonus.Status = model.BonusConverted
+	bonus.ConvertedAt = &now
+
+	return nil
+}
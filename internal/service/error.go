@@ -9,4 +9,28 @@ var (
 	ErrNegativeWithdrawalCode = errors.New("ErrNegativeWithdrawalCode")
 	ErrSpendingBudgetExceeded = errors.New("ErrSpendingBudgetExceeded")
 	ErrTransactionRollback    = errors.New("ErrTransactionRollback")
+	ErrAmountOverflow         = errors.New("ErrAmountOverflow")
+	ErrInvalidTransition      = errors.New("ErrInvalidTransition")
+	ErrTransactionConflict    = errors.New("ErrTransactionConflict")
+	ErrRoundNotFound          = errors.New("ErrRoundNotFound")
+	ErrFreeRoundsNotFound     = errors.New("ErrFreeRoundsNotFound")
+	ErrNotEnoughFreeRounds    = errors.New("ErrNotEnoughFreeRounds")
+	ErrFreeRoundsExists       = errors.New("ErrFreeRoundsExists")
+	ErrInvalidFreeRound       = errors.New("ErrInvalidFreeRound")
+	ErrBonusExists            = errors.New("ErrBonusExists")
+	ErrInvalidBonus           = errors.New("ErrInvalidBonus")
+	ErrInvalidLimit           = errors.New("ErrInvalidLimit")
+	ErrPlayerBlocked          = errors.New("ErrPlayerBlocked")
+	ErrPlayerStatusLocked     = errors.New("ErrPlayerStatusLocked")
+	ErrPlayerNotFound         = errors.New("ErrPlayerNotFound")
+	ErrInvalidPlayerStatus    = errors.New("ErrInvalidPlayerStatus")
+	ErrLedgerUnbalanced       = errors.New("ErrLedgerUnbalanced")
+	ErrLedgerMismatch         = errors.New("ErrLedgerMismatch")
+	ErrWalletExists           = errors.New("ErrWalletExists")
+	ErrTransactionNotFound    = errors.New("ErrTransactionNotFound")
+	ErrInvalidAdjustment      = errors.New("ErrInvalidAdjustment")
+	ErrWalletNotFound         = errors.New("ErrWalletNotFound")
+	ErrForeignTransaction     = errors.New("ErrForeignTransaction")
+	ErrTransactionBusy        = errors.New("ErrTransactionBusy")
+	ErrSnapshotMissing        = errors.New("ErrSnapshotMissing")
 )
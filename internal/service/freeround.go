@@ -0,0 +1,100 @@
+package service
+
+import (
+	"context"
+	"seamless-api-wrapper/internal/model"
+	"time"
+)
+
+// FreeRoundService manages free round campaigns. A campaign is granted to one
+// wallet under a bonusId that is unique in the wallet. A transaction with
+// ChargeFreeRounds spends rounds from the campaign with its BonusId, a
+// rollback gives them back.
+//
+// Balance.FreeRoundLeft is the number of rounds left in the active campaigns
+// of the wallet, nil when it has none.
+type FreeRoundService interface {
+	GrantFreeRounds(ctx context.Context, playerName, currency string, freeRound *model.FreeRound) error
+	CancelFreeRounds(ctx context.Context, playerName, currency, bonusID string) (*model.FreeRound, error)
+	FreeRounds(ctx context.Context, playerName string) ([]model.FreeRound, error)
+	EditFreeRounds(ctx context.Context, playerName, currency, bonusID string, edit *model.FreeRoundEdit) (*model.FreeRound, error)
+}
+
+// CheckGrant validates a campaign before it is stored.
+func CheckGrant(freeRound *model.FreeRound) error {
+	if freeRound.BonusID == "" || freeRound.Rounds < 1 || freeRound.BetValue < 0 {
+		return ErrInvalidFreeRound
+	}
+	return nil
+}
+
+// EditFreeRound applies an edit to an active or used campaign, others give
+// ErrFreeRoundsNotFound. The rounds granted move with the rounds left, so
+// the charges of the campaign still add up.
+func EditFreeRound(freeRound *model.FreeRound, edit *model.FreeRoundEdit, now time.Time) error {
+	if (edit.RoundsLeft == nil && edit.ExpiresAt == nil) || (edit.RoundsLeft != nil && *edit.RoundsLeft < 0) {
+		return ErrInvalidFreeRound
+	}
+
+	switch freeRound.Status {
+	case model.FreeRoundActive, model.FreeRoundUsed:
+	default:
+		return ErrFreeRoundsNotFound
+	}
+
+	if edit.RoundsLeft != nil {
+		freeRound.Rounds += *edit.RoundsLeft - freeRound.RoundsLeft
+		freeRound.RoundsLeft = *edit.RoundsLeft
+
+		freeRound.Status = model.FreeRoundActive
+		if freeRound.RoundsLeft == 0 {
+			freeRound.Status = model.FreeRoundUsed
+		}
+	}
+
+	if edit.ExpiresAt != nil {
+		freeRound.ExpiresAt = edit.ExpiresAt
+	}
+	freeRound.UpdatedAt = now
+
+	return nil
+}
+
+// ChargeFreeRounds takes the transaction's ChargeFreeRounds off freeRound. A
+// campaign that is cancelled, expired or limited to other games gives
+// ErrFreeRoundsNotFound, a charge above the rounds left ErrNotEnoughFreeRounds.
+func ChargeFreeRounds(freeRound *model.FreeRound, transaction *model.Transaction, now time.Time) error {
+	switch freeRound.Status {
+	case model.FreeRoundActive, model.FreeRoundUsed:
+	default:
+		return ErrFreeRoundsNotFound
+	}
+
+	if freeRound.Expired(now) || !freeRound.AllowsGame(transaction.GameID) {
+		return ErrFreeRoundsNotFound
+	}
+
+	charge := *transaction.ChargeFreeRounds
+	if charge < 1 || charge > freeRound.RoundsLeft {
+		return ErrNotEnoughFreeRounds
+	}
+
+	freeRound.RoundsLeft -= charge
+	if freeRound.RoundsLeft == 0 {
+		freeRound.Status = model.FreeRoundUsed
+	}
+	freeRound.UpdatedAt = now
+
+	return nil
+}
+
+// RefundFreeRounds gives the rounds of a rolled back transaction back to
+// freeRound. A used campaign becomes active again, a cancelled or expired one
+// keeps its status.
+func RefundFreeRounds(freeRound *model.FreeRound, transaction *model.Transaction, now time.Time) {
+	freeRound.RoundsLeft += *transaction.ChargeFreeRounds
+	if freeRound.Status == model.FreeRoundUsed {
+		freeRound.Status = model.FreeRoundActive
+	}
+	freeRound.UpdatedAt = now
+}
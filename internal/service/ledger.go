@@ -0,0 +1,167 @@
+package service
+
+import (
+	"context"
+	"seamless-api-wrapper/internal/model"
+	"time"
+)
+
+// LedgerService reads the double-entry ledger. Every money movement of a
+// wallet is posted as entries that sum to zero: the wallet's cash and bonus
+// accounts against the house account of the currency, or the cashier for
+// money that does not come from games. The cash of a wallet and the money of
+// a bonus are projections of their accounts, RebuildBalances recomputes them
+// from the ledger and returns how many were corrected.
+//
+// Every change of a wallet's cash is kept in its balance history with its
+// cause and the actor of the context. BalanceHistory returns the changes
+// made in [from, to), a nil bound is open.
+type LedgerService interface {
+	LedgerEntries(ctx context.Context, playerName, currency string) ([]model.LedgerEntry, error)
+	RebuildBalances(ctx context.Context) (int, error)
+	BalanceHistory(ctx context.Context, playerName, currency string, from, to *time.Time) ([]model.BalanceChange, error)
+}
+
+// posting collects the entries of one money movement of a wallet, zero
+// amounts are left out.
+type posting struct {
+	balance       *model.Balance
+	transactionID *int
+	bonusID       *int
+	entries       []model.LedgerEntry
+}
+
+func (p *posting) add(account model.Account, kind model.EntryKind, amount int64) {
+	if amount == 0 {
+		return
+	}
+
+	entry := model.LedgerEntry{
+		BalanceID:     p.balance.ID,
+		CurrencyID:    p.balance.CurrencyID,
+		TransactionID: p.transactionID,
+		Account:       account,
+		Kind:          kind,
+		Amount:        amount,
+	}
+	if account == model.AccountBonus {
+		entry.BonusID = p.bonusID
+	}
+
+	p.entries = append(p.entries, entry)
+}
+
+// TransactionEntries posts a committed transaction: the bet from cash and
+// bonus to the house, the win from the house to cash and bonus and the bonus
+// the transaction converted to cash.
+func TransactionEntries(balance *model.Balance, transaction *model.Transaction) []model.LedgerEntry {
+	p := posting{balance: balance, transactionID: &transaction.ID, bonusID: transaction.WageredBonusID}
+
+	p.add(model.AccountCash, model.EntryBet, transaction.BonusWithdraw-transaction.Withdraw)
+	p.add(model.AccountBonus, model.EntryBet, -transaction.BonusWithdraw)
+	p.add(model.AccountHouse, model.EntryBet, transaction.Withdraw)
+
+	p.add(model.AccountHouse, model.EntryWin, -transaction.Deposit)
+	p.add(model.AccountCash, model.EntryWin, transaction.Deposit-transaction.BonusDeposit)
+	p.add(model.AccountBonus, model.EntryWin, transaction.BonusDeposit)
+
+	p.add(model.AccountBonus, model.EntryBonusConversion, -transaction.BonusConverted)
+	p.add(model.AccountCash, model.EntryBonusConversion, transaction.BonusConverted)
+
+	return p.entries
+}
+
+// RollbackEntries posts what Reverse does: bonus is the active bonus the
+// transaction was wagered against, nil when its bonus money goes to cash.
+func RollbackEntries(balance *model.Balance, bonus *model.Bonus, transaction *model.Transaction) []model.LedgerEntry {
+	p := posting{balance: balance, transactionID: &transaction.ID}
+
+	net := transaction.Withdraw - transaction.Deposit
+	if bonus == nil {
+		p.add(model.AccountCash, model.EntryRollback, net)
+	} else {
+		p.bonusID = &bonus.ID
+		bonusNet := transaction.BonusWithdraw - transaction.BonusDeposit
+		p.add(model.AccountCash, model.EntryRollback, net-bonusNet)
+		p.add(model.AccountBonus, model.EntryRollback, bonusNet)
+	}
+	p.add(model.AccountHouse, model.EntryRollback, -net)
+
+	return p.entries
+}
+
+// GrantEntries posts the money of a granted bonus from the house.
+func GrantEntries(balance *model.Balance, bonus *model.Bonus) []model.LedgerEntry {
+	p := posting{balance: balance, bonusID: &bonus.ID}
+
+	p.add(model.AccountHouse, model.EntryBonusGrant, -bonus.Granted)
+	p.add(model.AccountBonus, model.EntryBonusGrant, bonus.Granted)
+
+	return p.entries
+}
+
+// OpeningEntries posts the cash a wallet is opened with from the cashier.
+func OpeningEntries(balance *model.Balance, amount int64) []model.LedgerEntry {
+	p := posting{balance: balance}
+
+	p.add(model.AccountCashier, model.EntryOpening, -amount)
+	p.add(model.AccountCash, model.EntryOpening, amount)
+
+	return p.entries
+}
+
+// AdjustmentEntries posts a correction of the wallet's cash against the
+// house.
+func AdjustmentEntries(balance *model.Balance, amount int64) []model.LedgerEntry {
+	p := posting{balance: balance}
+
+	p.add(model.AccountHouse, model.EntryAdjustment, -amount)
+	p.add(model.AccountCash, model.EntryAdjustment, amount)
+
+	return p.entries
+}
+
+// ManualEntries posts a manual credit or debit of the wallet's cash against
+// the cashier, it is money that does not come from games.
+func ManualEntries(balance *model.Balance, amount int64) []model.LedgerEntry {
+	p := posting{balance: balance}
+
+	p.add(model.AccountCashier, model.EntryManual, -amount)
+	p.add(model.AccountCash, model.EntryManual, amount)
+
+	return p.entries
+}
+
+// CheckPosting refuses a posting whose entries do not sum to zero.
+func CheckPosting(entries []model.LedgerEntry) error {
+	var sum int64
+	for _, entry := range entries {
+		sum += entry.Amount
+	}
+
+	if sum != 0 {
+		return ErrLedgerUnbalanced
+	}
+
+	return nil
+}
+
+// CashDelta is how much a posting moves the cash of its wallet.
+func CashDelta(entries []model.LedgerEntry) int64 {
+	return accountDelta(entries, model.AccountCash)
+}
+
+// BonusDelta is how much a posting moves the bonus money of its wallet.
+func BonusDelta(entries []model.LedgerEntry) int64 {
+	return accountDelta(entries, model.AccountBonus)
+}
+
+func accountDelta(entries []model.LedgerEntry, account model.Account) int64 {
+	var delta int64
+	for _, entry := range entries {
+		if entry.Account == account {
+			delta += entry.Amount
+		}
+	}
+	return delta
+}
@@ -0,0 +1,138 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"seamless-api-wrapper/internal/model"
+	"time"
+)
+
+// LimitService sets the responsible gambling limits of a wallet. A wallet has
+// at most one limit of each type and window, SetLimit replaces it.
+//
+// Transaction refuses a bet that would take the wallet's committed bets or
+// losses in the current window above a limit and a bet in a session older
+// than a session limit. Deposit limits count the manual deposits of
+// AdminService.Adjust, never game wins. The error is a *LimitError that
+// matches ErrSpendingBudgetExceeded.
+type LimitService interface {
+	SetLimit(ctx context.Context, playerName, currency string, limit *model.Limit) error
+	RemoveLimit(ctx context.Context, playerName, currency string, limitType model.LimitType, window model.LimitWindow) error
+	Limits(ctx context.Context, playerName string) ([]model.Limit, error)
+}
+
+// LimitError is the limit a transaction would exceed. Used is what the window
+// already holds, ResetsAt is when the window starts over, nil for a session
+// limit.
+type LimitError struct {
+	Limit    model.Limit
+	Used     int64
+	ResetsAt *time.Time
+}
+
+func (e *LimitError) Error() string {
+	return fmt.Sprintf("%s: %s %s limit", ErrSpendingBudgetExceeded, e.Limit.Window, e.Limit.Type)
+}
+
+func (e *LimitError) Unwrap() error {
+	return ErrSpendingBudgetExceeded
+}
+
+// Usage is what a wallet did in the window of a limit: the committed bets
+// and wins, and when the transaction's session started, nil for a new one.
+type Usage struct {
+	Withdraw     int64
+	Deposit      int64
+	SessionStart *time.Time
+}
+
+// CheckLimit validates a limit before it is stored.
+func CheckLimit(limit *model.Limit) error {
+	if limit.Amount < 0 {
+		return ErrInvalidLimit
+	}
+
+	switch limit.Type {
+	case model.LimitLoss, model.LimitWager, model.LimitDeposit:
+		switch limit.Window {
+		case model.WindowDaily, model.WindowWeekly, model.WindowMonthly:
+			return nil
+		}
+	case model.LimitSession:
+		if limit.Window == model.WindowSession {
+			return nil
+		}
+	}
+
+	return ErrInvalidLimit
+}
+
+// Window returns when the current window of a money limit started and when
+// it resets. Windows follow UTC days, weeks start on Monday.
+func Window(window model.LimitWindow, now time.Time) (time.Time, time.Time) {
+	now = now.UTC()
+	day := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+
+	switch window {
+	case model.WindowWeekly:
+		start := day.AddDate(0, 0, -(int(day.Weekday())+6)%7)
+		return start, start.AddDate(0, 0, 7)
+	case model.WindowMonthly:
+		start := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+		return start, start.AddDate(0, 1, 0)
+	}
+
+	return day, day.AddDate(0, 0, 1)
+}
+
+// CheckLimitUsage reports a *LimitError when the transaction would take
+// usage above limit.
+func CheckLimitUsage(limit *model.Limit, usage Usage, transaction *model.Transaction, now time.Time) error {
+	var used, after int64
+
+	switch limit.Type {
+	case model.LimitLoss:
+		used = usage.Withdraw - usage.Deposit
+		after = used + transaction.Withdraw - transaction.Deposit
+		if transaction.Withdraw == 0 {
+			return nil
+		}
+	case model.LimitWager:
+		used = usage.Withdraw
+		after = used + transaction.Withdraw
+		if transaction.Withdraw == 0 {
+			return nil
+		}
+	case model.LimitDeposit:
+		// Deposits come from the cashier, see CheckDeposit.
+		return nil
+	case model.LimitSession:
+		if usage.SessionStart == nil || transaction.Withdraw == 0 {
+			return nil
+		}
+
+		used = int64(now.Sub(*usage.SessionStart) / time.Second)
+		if used >= limit.Amount {
+			return &LimitError{Limit: *limit, Used: used}
+		}
+		return nil
+	}
+
+	if after <= limit.Amount {
+		return nil
+	}
+
+	_, reset := Window(limit.Window, now)
+	return &LimitError{Limit: *limit, Used: used, ResetsAt: &reset}
+}
+
+// CheckDeposit reports a *LimitError when a manual deposit of amount would
+// take the deposits used in the window of a deposit limit above it.
+func CheckDeposit(limit *model.Limit, used, amount int64, now time.Time) error {
+	if limit.Type != model.LimitDeposit || used+amount <= limit.Amount {
+		return nil
+	}
+
+	_, reset := Window(limit.Window, now)
+	return &LimitError{Limit: *limit, Used: used, ResetsAt: &reset}
+}
@@ -0,0 +1,80 @@
+package service
+
+import (
+	"context"
+	"seamless-api-wrapper/internal/model"
+	"time"
+)
+
+// PlayerService keeps the account status of players that have a wallet. Every
+// change is written to the status history with its reason and the actor of
+// the context.
+//
+// Transaction refuses bets and free round charges of a player that is not
+// active with ErrPlayerBlocked, wins and rollbacks still settle.
+type PlayerService interface {
+	SetPlayerStatus(ctx context.Context, player *model.Player) error
+	Player(ctx context.Context, playerName string) (*model.Player, error)
+	PlayerStatusHistory(ctx context.Context, playerName string) ([]model.PlayerStatusChange, error)
+}
+
+// CheckPlayer validates a status before it is stored. Self-exclusion and
+// cool-off need an end in the future, the other statuses have none.
+func CheckPlayer(player *model.Player, now time.Time) error {
+	if player.Reason == "" {
+		return ErrInvalidPlayerStatus
+	}
+
+	switch player.Status {
+	case model.PlayerActive, model.PlayerBlocked:
+		if player.Until == nil {
+			return nil
+		}
+	case model.PlayerSelfExcluded, model.PlayerCoolOff:
+		if player.Until != nil && player.Until.After(now) {
+			return nil
+		}
+	}
+
+	return ErrInvalidPlayerStatus
+}
+
+// CheckStatusChange refuses to shorten a self-exclusion or cool-off in force:
+// until it ends the player can only be blocked or excluded for longer.
+func CheckStatusChange(current, next *model.Player, now time.Time) error {
+	status := current.Current(now)
+	if status != model.PlayerSelfExcluded && status != model.PlayerCoolOff {
+		return nil
+	}
+
+	longer := current.Until != nil && next.Until != nil && !next.Until.Before(*current.Until)
+
+	switch next.Status {
+	case model.PlayerBlocked:
+		return nil
+	case model.PlayerSelfExcluded:
+		if longer {
+			return nil
+		}
+	case model.PlayerCoolOff:
+		if status == model.PlayerCoolOff && longer {
+			return nil
+		}
+	}
+
+	return ErrPlayerStatusLocked
+}
+
+// CheckPlayerBet refuses a bet or a free round charge of a player that is
+// not active at now.
+func CheckPlayerBet(player *model.Player, transaction *model.Transaction, now time.Time) error {
+	if transaction.Withdraw == 0 && transaction.ChargeFreeRounds == nil {
+		return nil
+	}
+
+	if player.Current(now) != model.PlayerActive {
+		return ErrPlayerBlocked
+	}
+
+	return nil
+}
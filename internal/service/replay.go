@@ -0,0 +1,28 @@
+package service
+
+import "seamless-api-wrapper/internal/model"
+
+// SameTransaction reports whether a retry asks for the same money movement as
+// the stored transaction, in the same game round and for the same bonus.
+func SameTransaction(stored, retry *model.Transaction) bool {
+	if stored.Withdraw != retry.Withdraw || stored.Deposit != retry.Deposit {
+		return false
+	}
+
+	if !sameString(stored.BonusId, retry.BonusId) || !sameString(stored.GameRoundRef, retry.GameRoundRef) || !sameString(stored.GameID, retry.GameID) {
+		return false
+	}
+
+	if (stored.ChargeFreeRounds == nil) != (retry.ChargeFreeRounds == nil) {
+		return false
+	}
+
+	return stored.ChargeFreeRounds == nil || *stored.ChargeFreeRounds == *retry.ChargeFreeRounds
+}
+
+func sameString(a, b *string) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
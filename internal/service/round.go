@@ -0,0 +1,16 @@
+package service
+
+import (
+	"context"
+	"seamless-api-wrapper/internal/model"
+	"time"
+)
+
+// RoundService reports game rounds. A round is opened by the first committed
+// transaction with its gameRoundRef and closed by a transaction with
+// CloseRound set, by RollbackRound or by CloseInactiveRounds.
+type RoundService interface {
+	Round(ctx context.Context, playerName, currency, gameRoundRef string) (*model.Round, error)
+	OpenRounds(ctx context.Context, playerName string) ([]model.Round, error)
+	CloseInactiveRounds(ctx context.Context, before time.Time) (int, error)
+}
@@ -5,8 +5,37 @@ import (
 	"seamless-api-wrapper/internal/model"
 )
 
+// MaxExponent is the largest number of decimal digits a currency may have.
+// Amounts stay int64 minor units, a change that would overflow a wallet is
+// refused with ErrAmountOverflow.
+const MaxExponent = 18
+
+// SeamlessService keeps one wallet per player and currency. A player who
+// already has a wallet gets an empty one in any other registered currency
+// on first use. Amounts are in minor units of the wallet currency,
+// CreateCurrency keeps the exponent of a currency that already exists.
+//
+// The registered currencies are the only ones accepted, a code that is not
+// registered gives ErrIllegalCurrencyCode.
+//
+// A repeated transactionRef returns the balance the original call returned,
+// or ErrTransactionConflict when the player, currency or amounts differ.
+//
+// RollbackRound reverses in one step every transaction of a game round in the
+// player's wallet that is not rolled back yet and returns the new balance.
+//
+// Every transactionRef moves through the statuses in model.TransactionStatus,
+// Transitions returns its history oldest first.
+//
+// TransactionHistory returns the transactions of a player that match the
+// filter with their spin details, newest first.
 type SeamlessService interface {
+	CreateCurrency(ctx context.Context, code string, exponent int) (*model.Currency, error)
+	Currencies(ctx context.Context) ([]model.Currency, error)
 	Balance(ctx context.Context, playerName, currency string) (*model.Balance, error)
 	Transaction(ctx context.Context, playerName, currency string, transaction *model.Transaction) (*model.Balance, error)
 	Rollback(ctx context.Context, playerName string, transaction *model.Transaction) error
+	RollbackRound(ctx context.Context, playerName, currency, gameRoundRef string) (*model.Balance, error)
+	Transitions(ctx context.Context, transactionRef string) ([]model.Transition, error)
+	TransactionHistory(ctx context.Context, filter *model.TransactionFilter) ([]model.TransactionRecord, error)
 }
@@ -0,0 +1,1324 @@
+// Package servicetest checks that a service.SeamlessService implementation
+// follows the wallet contract shared by all storage backends.
+package servicetest
+
+import (
+	"context"
+	"fmt"
+	"github.com/stretchr/testify/suite"
+	"math"
+	"seamless-api-wrapper/internal/model"
+	"seamless-api-wrapper/internal/service"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// Backend is the implementation under test. AddBalance creates a wallet for
+// a player that does not exist yet.
+type Backend struct {
+	Service    service.SeamlessService
+	Rounds     service.RoundService
+	FreeRounds service.FreeRoundService
+	Bonuses    service.BonusService
+	Limits     service.LimitService
+	Players    service.PlayerService
+	Ledger     service.LedgerService
+	Admin      service.AdminService
+
+	// SetSpendOrder changes the spend order of the service, tests restore
+	// the cash-first default.
+	SetSpendOrder func(order service.SpendOrder)
+	AddBalance    func(playerName, currency string, amount int64) error
+}
+
+// Run runs the contract suite, newBackend is called before every test.
+func Run(t *testing.T, newBackend func(t *testing.T) *Backend) {
+	suite.Run(t, &seamlessSuite{newBackend: newBackend})
+}
+
+var playerSeq atomic.Int64
+
+type seamlessSuite struct {
+	suite.Suite
+	newBackend func(t *testing.T) *Backend
+	backend    *Backend
+	ctx        context.Context
+	prefix     string
+}
+
+func (s *seamlessSuite) SetupTest() {
+	s.backend = s.newBackend(s.T())
+	s.ctx = context.Background()
+	s.prefix = fmt.Sprintf("contract:%d", time.Now().UnixNano())
+}
+
+// player creates a wallet under a name unique to this run, so backends
+// that keep their data between tests can be checked too.
+func (s *seamlessSuite) player(currency string, amount int64) string {
+	name := fmt.Sprintf("%s:player%d", s.prefix, playerSeq.Add(1))
+	s.Require().NoError(s.backend.AddBalance(name, currency, amount))
+	return name
+}
+
+func (s *seamlessSuite) ref(name string) string {
+	return s.prefix + ":" + name
+}
+
+func (s *seamlessSuite) balance(playerName string) int64 {
+	balance, err := s.backend.Service.Balance(s.ctx, playerName, "EUR")
+	s.Require().NoError(err)
+	return balance.Amount
+}
+
+func (s *seamlessSuite) transaction(playerName, ref string, withdraw, deposit int64) (*model.Transaction, *model.Balance, error) {
+	transaction := &model.Transaction{
+		Withdraw:       withdraw,
+		Deposit:        deposit,
+		TransactionRef: ref,
+	}
+
+	balance, err := s.backend.Service.Transaction(s.ctx, playerName, "EUR", transaction)
+	return transaction, balance, err
+}
+
+func (s *seamlessSuite) rollback(playerName, ref string) error {
+	now := time.Now()
+	return s.backend.Service.Rollback(s.ctx, playerName, &model.Transaction{
+		TransactionRef: ref,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	})
+}
+
+func (s *seamlessSuite) TestBalance() {
+	player := s.player("EUR", 1500)
+
+	balance, err := s.backend.Service.Balance(s.ctx, player, "EUR")
+	s.Require().NoError(err)
+	s.Equal(int64(1500), balance.Amount)
+	s.Equal(player, balance.PlayerName)
+	s.Equal("EUR", balance.Currency.Code)
+
+	_, err = s.backend.Service.Balance(s.ctx, s.prefix+":unknown", "EUR")
+	s.ErrorIs(err, service.ErrNotEnoughMoneyCode)
+}
+
+func (s *seamlessSuite) TestTransaction() {
+	player := s.player("EUR", 1000)
+
+	transaction, balance, err := s.transaction(player, s.ref("bet"), 300, 100)
+	s.Require().NoError(err)
+	s.Equal(int64(800), balance.Amount)
+	s.NotZero(transaction.ID)
+	s.Equal(balance.ID, transaction.BalanceID)
+	s.Equal(int64(800), s.balance(player))
+
+	_, _, err = s.transaction(player, s.ref("currency"), 10, 0)
+	s.Require().NoError(err)
+
+	_, err = s.backend.Service.Transaction(s.ctx, player, "XTS", &model.Transaction{Withdraw: 10, TransactionRef: s.ref("xts")})
+	s.ErrorIs(err, service.ErrIllegalCurrencyCode)
+
+	_, err = s.backend.Service.Balance(s.ctx, player, "XTS")
+	s.ErrorIs(err, service.ErrIllegalCurrencyCode)
+}
+
+func (s *seamlessSuite) TestDefaultWallet() {
+	player := s.player("EUR", 1000)
+
+	currency, err := s.backend.Service.CreateCurrency(s.ctx, "GBP", 2)
+	s.Require().NoError(err)
+	s.Equal("GBP", currency.Code)
+
+	again, err := s.backend.Service.CreateCurrency(s.ctx, "GBP", 2)
+	s.Require().NoError(err)
+	s.Equal(currency.ID, again.ID)
+
+	// Reads never open a wallet.
+	_, err = s.backend.Service.Balance(s.ctx, player, "GBP")
+	s.ErrorIs(err, service.ErrWalletNotFound)
+
+	_, err = s.backend.Service.Transaction(s.ctx, player, "GBP", &model.Transaction{Withdraw: 10, TransactionRef: s.ref("gbp:bet")})
+	s.ErrorIs(err, service.ErrSpendingBudgetExceeded)
+
+	balance, err := s.backend.Service.Transaction(s.ctx, player, "GBP", &model.Transaction{Deposit: 70, TransactionRef: s.ref("gbp:win")})
+	s.Require().NoError(err)
+	s.Equal(int64(70), balance.Amount)
+	s.Equal("GBP", balance.Currency.Code)
+
+	balance, err = s.backend.Service.Balance(s.ctx, player, "GBP")
+	s.Require().NoError(err)
+	s.Equal(int64(70), balance.Amount)
+	s.Equal(int64(1000), s.balance(player))
+
+	_, err = s.backend.Service.Balance(s.ctx, s.prefix+":unknown", "GBP")
+	s.ErrorIs(err, service.ErrNotEnoughMoneyCode)
+}
+
+func (s *seamlessSuite) TestMultiCurrencyRollback() {
+	player := s.player("EUR", 1000)
+
+	_, err := s.backend.Service.CreateCurrency(s.ctx, "USD", 2)
+	s.Require().NoError(err)
+
+	_, err = s.backend.Service.Transaction(s.ctx, player, "USD", &model.Transaction{Deposit: 500, TransactionRef: s.ref("usd:win")})
+	s.Require().NoError(err)
+
+	balance, err := s.backend.Service.Transaction(s.ctx, player, "USD", &model.Transaction{Withdraw: 200, TransactionRef: s.ref("usd:bet")})
+	s.Require().NoError(err)
+	s.Equal(int64(300), balance.Amount)
+
+	s.Require().NoError(s.rollback(player, s.ref("usd:bet")))
+
+	balance, err = s.backend.Service.Balance(s.ctx, player, "USD")
+	s.Require().NoError(err)
+	s.Equal(int64(500), balance.Amount)
+	s.Equal(int64(1000), s.balance(player))
+
+	other := s.player("EUR", 1000)
+	s.ErrorIs(s.rollback(other, s.ref("usd:win")), service.ErrForeignTransaction)
+
+	balance, err = s.backend.Service.Balance(s.ctx, player, "USD")
+	s.Require().NoError(err)
+	s.Equal(int64(500), balance.Amount)
+}
+
+func (s *seamlessSuite) TestDuplicateTransactionRef() {
+	player := s.player("EUR", 1000)
+
+	first, _, err := s.transaction(player, s.ref("dup"), 300, 100)
+	s.Require().NoError(err)
+
+	second, balance, err := s.transaction(player, s.ref("dup"), 300, 100)
+	s.Require().NoError(err)
+	s.Equal(first.ID, second.ID)
+	s.Equal(int64(800), balance.Amount)
+	s.Equal(int64(800), s.balance(player))
+}
+
+func (s *seamlessSuite) TestReplay() {
+	player := s.player("EUR", 1000)
+
+	first, balance, err := s.transaction(player, s.ref("first"), 300, 0)
+	s.Require().NoError(err)
+	s.Equal(int64(700), balance.Amount)
+
+	_, _, err = s.transaction(player, s.ref("second"), 200, 0)
+	s.Require().NoError(err)
+
+	retry, balance, err := s.transaction(player, s.ref("first"), 300, 0)
+	s.Require().NoError(err)
+	s.Equal(first.ID, retry.ID)
+	s.Equal(int64(700), balance.Amount)
+	s.Equal(int64(500), s.balance(player))
+
+	_, _, err = s.transaction(player, s.ref("first"), 300, 1)
+	s.ErrorIs(err, service.ErrTransactionConflict)
+
+	other := s.player("EUR", 1000)
+	_, _, err = s.transaction(other, s.ref("first"), 300, 0)
+	s.ErrorIs(err, service.ErrTransactionConflict)
+	s.Equal(int64(1000), s.balance(other))
+
+	_, err = s.backend.Service.CreateCurrency(s.ctx, "USD", 2)
+	s.Require().NoError(err)
+	_, err = s.backend.Service.Transaction(s.ctx, player, "USD", &model.Transaction{Withdraw: 300, TransactionRef: s.ref("first")})
+	s.ErrorIs(err, service.ErrTransactionConflict)
+
+	s.Equal(int64(500), s.balance(player))
+}
+
+func (s *seamlessSuite) TestReplayConflictingDetails() {
+	player := s.player("EUR", 1000)
+	round, game, bonus := s.ref("round"), "game", "bonus"
+
+	stored := &model.Transaction{Withdraw: 300, TransactionRef: s.ref("bet"), GameRoundRef: &round, GameID: &game, BonusId: &bonus}
+	_, err := s.backend.Service.Transaction(s.ctx, player, "EUR", stored)
+	s.Require().NoError(err)
+
+	otherRound, otherGame, otherBonus := s.ref("other-round"), "other-game", "other-bonus"
+	for name, retry := range map[string]*model.Transaction{
+		"bonusId":      {GameRoundRef: &round, GameID: &game, BonusId: &otherBonus},
+		"no bonusId":   {GameRoundRef: &round, GameID: &game},
+		"gameRoundRef": {GameRoundRef: &otherRound, GameID: &game, BonusId: &bonus},
+		"gameId":       {GameRoundRef: &round, GameID: &otherGame, BonusId: &bonus},
+	} {
+		retry.Withdraw, retry.TransactionRef = 300, s.ref("bet")
+		_, err = s.backend.Service.Transaction(s.ctx, player, "EUR", retry)
+		s.ErrorIs(err, service.ErrTransactionConflict, name)
+	}
+
+	retry := &model.Transaction{Withdraw: 300, TransactionRef: s.ref("bet"), GameRoundRef: &round, GameID: &game, BonusId: &bonus}
+	balance, err := s.backend.Service.Transaction(s.ctx, player, "EUR", retry)
+	s.Require().NoError(err)
+	s.Equal(int64(700), balance.Amount)
+	s.Equal(int64(700), s.balance(player))
+}
+
+func (s *seamlessSuite) TestRollback() {
+	player := s.player("EUR", 1000)
+
+	_, _, err := s.transaction(player, s.ref("bet"), 300, 100)
+	s.Require().NoError(err)
+
+	s.Require().NoError(s.rollback(player, s.ref("bet")))
+	s.Equal(int64(1000), s.balance(player))
+
+	_, _, err = s.transaction(player, s.ref("bet"), 300, 100)
+	s.ErrorIs(err, service.ErrTransactionRollback)
+	s.Equal(int64(1000), s.balance(player))
+}
+
+func (s *seamlessSuite) TestRollbackBeforeTransaction() {
+	player := s.player("EUR", 1000)
+
+	s.Require().NoError(s.rollback(player, s.ref("late")))
+	s.Equal(int64(1000), s.balance(player))
+
+	_, _, err := s.transaction(player, s.ref("late"), 300, 100)
+	s.ErrorIs(err, service.ErrTransactionRollback)
+	s.Equal(int64(1000), s.balance(player))
+
+	s.ErrorIs(s.rollback(s.prefix+":nobody", s.ref("nobody")), service.ErrPlayerNotFound)
+}
+
+func (s *seamlessSuite) TestFailedTransactionSnapshot() {
+	player := s.player("EUR", 1000)
+
+	// A bet that cannot be paid keeps no snapshot, whatever the model held.
+	stale, staleLeft := int64(1000), 5
+	transaction := &model.Transaction{
+		Withdraw:           5000,
+		TransactionRef:     s.ref("too-big"),
+		BalanceAfter:       &stale,
+		BonusAmountAfter:   &stale,
+		FreeRoundLeftAfter: &staleLeft,
+	}
+	_, err := s.backend.Service.Transaction(s.ctx, player, "EUR", transaction)
+	s.ErrorIs(err, service.ErrSpendingBudgetExceeded)
+	s.Equal(model.StatusFailed, transaction.Status)
+	s.Nil(transaction.BalanceAfter)
+	s.Nil(transaction.BonusAmountAfter)
+	s.Nil(transaction.FreeRoundLeftAfter)
+}
+
+func (s *seamlessSuite) TestRollbackAfterRollback() {
+	player := s.player("EUR", 1000)
+
+	_, _, err := s.transaction(player, s.ref("bet"), 300, 0)
+	s.Require().NoError(err)
+
+	s.Require().NoError(s.rollback(player, s.ref("bet")))
+	s.Require().NoError(s.rollback(player, s.ref("bet")))
+	s.Equal(int64(1000), s.balance(player))
+
+	s.Require().NoError(s.rollback(player, s.ref("unknown")))
+	s.Require().NoError(s.rollback(player, s.ref("unknown")))
+	s.Equal(int64(1000), s.balance(player))
+}
+
+func (s *seamlessSuite) statuses(ref string) []string {
+	transitions, err := s.backend.Service.Transitions(s.ctx, ref)
+	s.Require().NoError(err)
+
+	statuses := make([]string, 0, len(transitions))
+	for _, transition := range transitions {
+		statuses = append(statuses, fmt.Sprintf("%s>%s", transition.FromStatus, transition.ToStatus))
+		s.NotEmpty(transition.Reason)
+		s.False(transition.CreatedAt.IsZero())
+	}
+	return statuses
+}
+
+func (s *seamlessSuite) TestTransitions() {
+	player := s.player("EUR", 100)
+
+	_, _, err := s.transaction(player, s.ref("bet"), 50, 0)
+	s.Require().NoError(err)
+	s.Require().NoError(s.rollback(player, s.ref("bet")))
+	s.Require().NoError(s.rollback(player, s.ref("bet")))
+	s.Equal([]string{">committed", "committed>rolled_back"}, s.statuses(s.ref("bet")))
+
+	s.Require().NoError(s.rollback(player, s.ref("late")))
+	_, _, err = s.transaction(player, s.ref("late"), 50, 0)
+	s.ErrorIs(err, service.ErrTransactionRollback)
+	s.Equal([]string{">tombstone_rolled_back"}, s.statuses(s.ref("late")))
+
+	_, _, err = s.transaction(player, s.ref("retry"), 500, 0)
+	s.ErrorIs(err, service.ErrSpendingBudgetExceeded)
+	transaction, _, err := s.transaction(player, s.ref("retry"), 50, 0)
+	s.Require().NoError(err)
+	s.Equal(model.StatusCommitted, transaction.Status)
+	s.Equal([]string{">failed", "failed>committed"}, s.statuses(s.ref("retry")))
+
+	_, _, err = s.transaction(player, s.ref("failed"), 500, 0)
+	s.ErrorIs(err, service.ErrSpendingBudgetExceeded)
+	s.Require().NoError(s.rollback(player, s.ref("failed")))
+	_, _, err = s.transaction(player, s.ref("failed"), 10, 0)
+	s.ErrorIs(err, service.ErrTransactionRollback)
+	s.Equal([]string{">failed", "failed>tombstone_rolled_back"}, s.statuses(s.ref("failed")))
+
+	s.Equal(int64(50), s.balance(player))
+	s.Empty(s.statuses(s.ref("unknown")))
+}
+
+func (s *seamlessSuite) TestRollbackRound() {
+	player := s.player("EUR", 1000)
+	round := s.ref("round")
+	otherRound := s.ref("other-round")
+
+	leg := func(ref string, withdraw, deposit int64, gameRoundRef *string) error {
+		_, err := s.backend.Service.Transaction(s.ctx, player, "EUR", &model.Transaction{
+			Withdraw:       withdraw,
+			Deposit:        deposit,
+			TransactionRef: s.ref(ref),
+			GameRoundRef:   gameRoundRef,
+		})
+		return err
+	}
+
+	s.Require().NoError(leg("bet", 300, 0, &round))
+	s.Require().NoError(leg("win", 0, 120, &round))
+	s.Require().NoError(leg("bonus", 100, 0, &round))
+	s.Require().NoError(leg("other", 50, 0, &otherRound))
+	s.ErrorIs(leg("failed", 5000, 0, &round), service.ErrSpendingBudgetExceeded)
+	s.Require().NoError(s.rollback(player, s.ref("bonus")))
+	s.Equal(int64(770), s.balance(player))
+
+	balance, err := s.backend.Service.RollbackRound(s.ctx, player, "EUR", round)
+	s.Require().NoError(err)
+	s.Equal(int64(950), balance.Amount)
+	s.Equal(int64(950), s.balance(player))
+
+	s.Equal([]string{">committed", "committed>rolled_back"}, s.statuses(s.ref("bet")))
+	s.Equal([]string{">committed", "committed>rolled_back"}, s.statuses(s.ref("bonus")))
+	s.Equal([]string{">failed", "failed>tombstone_rolled_back"}, s.statuses(s.ref("failed")))
+	s.Equal([]string{">committed"}, s.statuses(s.ref("other")))
+
+	balance, err = s.backend.Service.RollbackRound(s.ctx, player, "EUR", round)
+	s.Require().NoError(err)
+	s.Equal(int64(950), balance.Amount)
+
+	s.ErrorIs(leg("win", 0, 120, &round), service.ErrTransactionRollback)
+
+	balance, err = s.backend.Service.RollbackRound(s.ctx, player, "EUR", s.ref("unknown"))
+	s.Require().NoError(err)
+	s.Equal(int64(950), balance.Amount)
+
+	_, err = s.backend.Service.RollbackRound(s.ctx, s.prefix+":unknown", "EUR", round)
+	s.ErrorIs(err, service.ErrNotEnoughMoneyCode)
+}
+
+func (s *seamlessSuite) TestRounds() {
+	player := s.player("EUR", 1000)
+
+	leg := func(ref, round string, withdraw, deposit int64, closeRound bool) {
+		_, err := s.backend.Service.Transaction(s.ctx, player, "EUR", &model.Transaction{
+			Withdraw:       withdraw,
+			Deposit:        deposit,
+			TransactionRef: s.ref(ref),
+			GameRoundRef:   &round,
+			CloseRound:     closeRound,
+		})
+		s.Require().NoError(err)
+	}
+
+	round := func(ref string) *model.Round {
+		round, err := s.backend.Rounds.Round(s.ctx, player, "EUR", ref)
+		s.Require().NoError(err)
+		return round
+	}
+
+	first, second, third := s.ref("round:1"), s.ref("round:2"), s.ref("round:3")
+
+	leg("bet:1", first, 300, 0, false)
+	leg("win:1", first, 0, 120, false)
+	leg("bet:2", second, 100, 0, false)
+	leg("bet:3", third, 50, 0, false)
+
+	r := round(first)
+	s.Equal(model.RoundOpen, r.Status)
+	s.Equal(int64(300), r.TotalBet)
+	s.Equal(int64(120), r.TotalWin)
+	s.Equal(2, r.Transactions)
+	s.Equal("EUR", r.Currency.Code)
+	s.Nil(r.ClosedAt)
+
+	open, err := s.backend.Rounds.OpenRounds(s.ctx, player)
+	s.Require().NoError(err)
+	s.Len(open, 3)
+
+	s.Require().NoError(s.rollback(player, s.ref("win:1")))
+	leg("win:1b", first, 0, 80, true)
+
+	r = round(first)
+	s.Equal(model.RoundClosed, r.Status)
+	s.Equal(int64(300), r.TotalBet)
+	s.Equal(int64(80), r.TotalWin)
+	s.Equal(2, r.Transactions)
+	s.NotNil(r.ClosedAt)
+
+	_, err = s.backend.Service.RollbackRound(s.ctx, player, "EUR", third)
+	s.Require().NoError(err)
+	r = round(third)
+	s.Equal(model.RoundRolledBack, r.Status)
+	s.Equal(int64(0), r.TotalBet)
+
+	closed, err := s.backend.Rounds.CloseInactiveRounds(s.ctx, time.Now().Add(time.Minute))
+	s.Require().NoError(err)
+	s.GreaterOrEqual(closed, 1)
+
+	r = round(second)
+	s.Equal(model.RoundClosed, r.Status)
+	s.Require().NotNil(r.CloseReason)
+	s.Equal("inactivity", *r.CloseReason)
+
+	open, err = s.backend.Rounds.OpenRounds(s.ctx, player)
+	s.Require().NoError(err)
+	s.Empty(open)
+
+	_, err = s.backend.Rounds.Round(s.ctx, player, "EUR", s.ref("round:unknown"))
+	s.ErrorIs(err, service.ErrRoundNotFound)
+}
+
+func (s *seamlessSuite) TestNegativeAmounts() {
+	player := s.player("EUR", 1000)
+
+	_, _, err := s.transaction(player, s.ref("deposit"), 10, -1)
+	s.ErrorIs(err, service.ErrNegativeDepositCode)
+
+	_, _, err = s.transaction(player, s.ref("withdraw"), -1, 10)
+	s.ErrorIs(err, service.ErrNegativeWithdrawalCode)
+
+	s.Equal(int64(1000), s.balance(player))
+}
+
+func (s *seamlessSuite) TestBudgetExceeded() {
+	player := s.player("EUR", 100)
+
+	_, _, err := s.transaction(player, s.ref("big"), 101, 500)
+	s.ErrorIs(err, service.ErrSpendingBudgetExceeded)
+	s.Equal(int64(100), s.balance(player))
+
+	_, balance, err := s.transaction(player, s.ref("big"), 100, 0)
+	s.Require().NoError(err)
+	s.Equal(int64(0), balance.Amount)
+}
+
+func (s *seamlessSuite) TestCurrencyRegistry() {
+	for code, exponent := range map[string]int{"BTC": 8, "ETH": 18, "USDT": 6} {
+		currency, err := s.backend.Service.CreateCurrency(s.ctx, code, exponent)
+		s.Require().NoError(err)
+		s.Equal(exponent, currency.Exponent)
+	}
+
+	_, err := s.backend.Service.CreateCurrency(s.ctx, "XTS", service.MaxExponent+1)
+	s.ErrorIs(err, service.ErrIllegalCurrencyCode)
+
+	_, err = s.backend.Service.CreateCurrency(s.ctx, "", 2)
+	s.ErrorIs(err, service.ErrIllegalCurrencyCode)
+
+	currencies, err := s.backend.Service.Currencies(s.ctx)
+	s.Require().NoError(err)
+
+	registered := make(map[string]int)
+	for _, currency := range currencies {
+		registered[currency.Code] = currency.Exponent
+	}
+	s.Equal(18, registered["ETH"])
+	s.Equal(8, registered["BTC"])
+	s.NotContains(registered, "XTS")
+
+	player := s.player("EUR", 0)
+	wei := int64(1_500_000_000_000_000_000)
+
+	balance, err := s.backend.Service.Transaction(s.ctx, player, "ETH", &model.Transaction{Deposit: wei, TransactionRef: s.ref("eth")})
+	s.Require().NoError(err)
+	s.Equal(wei, balance.Amount)
+	s.Equal(18, balance.Currency.Exponent)
+
+	_, err = s.backend.Service.Transaction(s.ctx, player, "ETH", &model.Transaction{Deposit: 8_000_000_000_000_000_000, TransactionRef: s.ref("eth-overflow")})
+	s.ErrorIs(err, service.ErrAmountOverflow)
+
+	balance, err = s.backend.Service.Transaction(s.ctx, player, "ETH", &model.Transaction{Deposit: 2 * wei, TransactionRef: s.ref("eth-more")})
+	s.Require().NoError(err)
+	s.Equal(3*wei, balance.Amount)
+}
+
+func (s *seamlessSuite) TestLargeAmounts() {
+	currency, err := s.backend.Service.CreateCurrency(s.ctx, "JPY", 0)
+	s.Require().NoError(err)
+	s.Equal(0, currency.Exponent)
+
+	player := s.player("JPY", 5_000_000_000)
+
+	balance, err := s.backend.Service.Transaction(s.ctx, player, "JPY", &model.Transaction{
+		Withdraw:       3_000_000_000,
+		Deposit:        10_000_000_000,
+		TransactionRef: s.ref("jpy"),
+	})
+	s.Require().NoError(err)
+	s.Equal(int64(12_000_000_000), balance.Amount)
+	s.Equal(0, balance.Currency.Exponent)
+}
+
+func (s *seamlessSuite) TestAmountOverflow() {
+	player := s.player("EUR", math.MaxInt64-10)
+
+	_, _, err := s.transaction(player, s.ref("overflow"), 0, 11)
+	s.ErrorIs(err, service.ErrAmountOverflow)
+	s.Equal(int64(math.MaxInt64-10), s.balance(player))
+
+	_, balance, err := s.transaction(player, s.ref("overflow"), 1, 11)
+	s.Require().NoError(err)
+	s.Equal(int64(math.MaxInt64), balance.Amount)
+}
+
+// charge plays free rounds of the bonusId campaign in gameID.
+func (s *seamlessSuite) charge(playerName, ref, bonusID string, gameID *string, rounds int, deposit int64) (*model.Balance, error) {
+	return s.backend.Service.Transaction(s.ctx, playerName, "EUR", &model.Transaction{
+		Deposit:          deposit,
+		TransactionRef:   s.ref(ref),
+		GameID:           gameID,
+		BonusId:          &bonusID,
+		ChargeFreeRounds: &rounds,
+	})
+}
+
+func (s *seamlessSuite) freeRound(playerName, bonusID string) model.FreeRound {
+	freeRounds, err := s.backend.FreeRounds.FreeRounds(s.ctx, playerName)
+	s.Require().NoError(err)
+
+	for _, freeRound := range freeRounds {
+		if freeRound.BonusID == bonusID {
+			return freeRound
+		}
+	}
+
+	s.FailNow("free rounds not found", bonusID)
+	return model.FreeRound{}
+}
+
+func (s *seamlessSuite) TestFreeRounds() {
+	player := s.player("EUR", 1000)
+
+	balance, err := s.backend.Service.Balance(s.ctx, player, "EUR")
+	s.Require().NoError(err)
+	s.Nil(balance.FreeRoundLeft)
+
+	_, err = s.charge(player, "free:none", "bonus", nil, 1, 0)
+	s.ErrorIs(err, service.ErrFreeRoundsNotFound)
+
+	s.Require().NoError(s.backend.FreeRounds.GrantFreeRounds(s.ctx, player, "EUR", &model.FreeRound{
+		BonusID:  "bonus",
+		BetValue: 20,
+		Rounds:   3,
+	}))
+
+	balance, err = s.charge(player, "free:1", "bonus", nil, 2, 50)
+	s.Require().NoError(err)
+	s.Require().NotNil(balance.FreeRoundLeft)
+	s.Equal(1, *balance.FreeRoundLeft)
+	s.Equal(int64(1050), balance.Amount)
+
+	_, err = s.charge(player, "free:2", "bonus", nil, 2, 0)
+	s.ErrorIs(err, service.ErrNotEnoughFreeRounds)
+	s.Equal([]string{">failed"}, s.statuses(s.ref("free:2")))
+
+	balance, err = s.charge(player, "free:3", "bonus", nil, 1, 0)
+	s.Require().NoError(err)
+	s.Nil(balance.FreeRoundLeft)
+	s.Equal(model.FreeRoundUsed, s.freeRound(player, "bonus").Status)
+
+	s.Require().NoError(s.rollback(player, s.ref("free:1")))
+	s.Equal(int64(1000), s.balance(player))
+
+	freeRound := s.freeRound(player, "bonus")
+	s.Equal(model.FreeRoundActive, freeRound.Status)
+	s.Equal(2, freeRound.RoundsLeft)
+	s.Equal(3, freeRound.Rounds)
+	s.Equal(int64(20), freeRound.BetValue)
+}
+
+func (s *seamlessSuite) TestFreeRoundCampaigns() {
+	player := s.player("EUR", 1000)
+
+	riot, other := "riot", "other"
+	past := time.Now().Add(-time.Minute)
+
+	grants := []model.FreeRound{
+		{BonusID: "riot", GameIDs: []string{riot}, Rounds: 5},
+		{BonusID: "expired", Rounds: 5, ExpiresAt: &past},
+		{BonusID: "cancelled", Rounds: 5},
+	}
+	for i := range grants {
+		s.Require().NoError(s.backend.FreeRounds.GrantFreeRounds(s.ctx, player, "EUR", &grants[i]))
+		s.Equal(model.FreeRoundActive, grants[i].Status)
+		s.Equal(5, grants[i].RoundsLeft)
+	}
+
+	err := s.backend.FreeRounds.GrantFreeRounds(s.ctx, player, "EUR", &model.FreeRound{BonusID: "riot", Rounds: 1})
+	s.ErrorIs(err, service.ErrFreeRoundsExists)
+
+	_, err = s.charge(player, "campaign:other", "riot", &other, 1, 0)
+	s.ErrorIs(err, service.ErrFreeRoundsNotFound)
+
+	balance, err := s.charge(player, "campaign:riot", "riot", &riot, 1, 0)
+	s.Require().NoError(err)
+	s.Equal(4+5, *balance.FreeRoundLeft)
+
+	_, err = s.charge(player, "campaign:expired", "expired", nil, 1, 0)
+	s.ErrorIs(err, service.ErrFreeRoundsNotFound)
+
+	cancelled, err := s.backend.FreeRounds.CancelFreeRounds(s.ctx, player, "EUR", "cancelled")
+	s.Require().NoError(err)
+	s.Equal(model.FreeRoundCancelled, cancelled.Status)
+
+	_, err = s.charge(player, "campaign:cancelled", "cancelled", nil, 1, 0)
+	s.ErrorIs(err, service.ErrFreeRoundsNotFound)
+
+	_, err = s.backend.FreeRounds.CancelFreeRounds(s.ctx, player, "EUR", "unknown")
+	s.ErrorIs(err, service.ErrFreeRoundsNotFound)
+
+	balance, err = s.backend.Service.Balance(s.ctx, player, "EUR")
+	s.Require().NoError(err)
+	s.Equal(4, *balance.FreeRoundLeft)
+
+	freeRounds, err := s.backend.FreeRounds.FreeRounds(s.ctx, player)
+	s.Require().NoError(err)
+	s.Require().Len(freeRounds, 3)
+	s.Equal([]string{riot}, freeRounds[0].GameIDs)
+	s.Equal(model.FreeRoundExpired, freeRounds[1].Status)
+	s.Equal(model.FreeRoundCancelled, freeRounds[2].Status)
+	s.Equal("EUR", freeRounds[0].Currency.Code)
+}
+
+// bet carries bonusID, an empty bonusID is a cash bet.
+func (s *seamlessSuite) bet(playerName, ref, bonusID string, withdraw, deposit int64) (*model.Transaction, *model.Balance, error) {
+	transaction := &model.Transaction{
+		Withdraw:       withdraw,
+		Deposit:        deposit,
+		TransactionRef: s.ref(ref),
+	}
+	if bonusID != "" {
+		transaction.BonusId = &bonusID
+	}
+
+	balance, err := s.backend.Service.Transaction(s.ctx, playerName, "EUR", transaction)
+	return transaction, balance, err
+}
+
+func (s *seamlessSuite) wallet(playerName string) (int64, int64) {
+	balance, err := s.backend.Service.Balance(s.ctx, playerName, "EUR")
+	s.Require().NoError(err)
+	return balance.Amount, balance.BonusAmount
+}
+
+func (s *seamlessSuite) bonus(playerName, bonusID string) model.Bonus {
+	bonuses, err := s.backend.Bonuses.Bonuses(s.ctx, playerName)
+	s.Require().NoError(err)
+
+	for _, bonus := range bonuses {
+		if bonus.BonusID == bonusID {
+			return bonus
+		}
+	}
+
+	s.FailNow("bonus not found", bonusID)
+	return model.Bonus{}
+}
+
+func (s *seamlessSuite) TestBonusWagering() {
+	player := s.player("EUR", 100)
+
+	bonus := model.Bonus{BonusID: "deposit", Granted: 500, Wagering: 1000}
+	s.Require().NoError(s.backend.Bonuses.GrantBonus(s.ctx, player, "EUR", &bonus))
+	s.Equal(model.BonusActive, bonus.Status)
+	s.Equal(int64(500), bonus.Amount)
+
+	err := s.backend.Bonuses.GrantBonus(s.ctx, player, "EUR", &model.Bonus{BonusID: "deposit", Granted: 1, Wagering: 1})
+	s.ErrorIs(err, service.ErrBonusExists)
+
+	cash, bonusAmount := s.wallet(player)
+	s.Equal(int64(100), cash)
+	s.Equal(int64(500), bonusAmount)
+
+	// Cash goes first, the rest of the bet is bonus money.
+	transaction, balance, err := s.bet(player, "bonus:1", "deposit", 300, 0)
+	s.Require().NoError(err)
+	s.Equal(int64(0), balance.Amount)
+	s.Equal(int64(300), balance.BonusAmount)
+	s.Equal(int64(200), transaction.BonusWithdraw)
+
+	_, _, err = s.bet(player, "bonus:cash", "", 50, 0)
+	s.ErrorIs(err, service.ErrSpendingBudgetExceeded)
+
+	_, balance, err = s.bet(player, "bonus:2", "deposit", 0, 100)
+	s.Require().NoError(err)
+	s.Equal(int64(400), balance.BonusAmount)
+	s.Equal(int64(300), s.bonus(player, "deposit").Wagered)
+
+	s.Require().NoError(s.rollback(player, s.ref("bonus:2")))
+	cash, bonusAmount = s.wallet(player)
+	s.Equal(int64(0), cash)
+	s.Equal(int64(300), bonusAmount)
+
+	s.Require().NoError(s.rollback(player, s.ref("bonus:1")))
+	cash, bonusAmount = s.wallet(player)
+	s.Equal(int64(100), cash)
+	s.Equal(int64(500), bonusAmount)
+	s.Equal(int64(0), s.bonus(player, "deposit").Wagered)
+
+	// The bet that completes the wagering converts the bonus to cash.
+	_, _, err = s.bet(player, "bonus:3", "deposit", 600, 900)
+	s.Require().NoError(err)
+	_, balance, err = s.bet(player, "bonus:4", "deposit", 400, 0)
+	s.Require().NoError(err)
+	s.Equal(int64(500), balance.Amount)
+	s.Equal(int64(0), balance.BonusAmount)
+
+	converted := s.bonus(player, "deposit")
+	s.Equal(model.BonusConverted, converted.Status)
+	s.Equal(int64(1000), converted.Wagered)
+	s.NotNil(converted.ConvertedAt)
+
+	// A converted bonus is cash, rollbacks take its money from cash.
+	s.Require().NoError(s.rollback(player, s.ref("bonus:4")))
+	cash, bonusAmount = s.wallet(player)
+	s.Equal(int64(900), cash)
+	s.Equal(int64(0), bonusAmount)
+}
+
+func (s *seamlessSuite) TestBonusSpendOrder() {
+	s.backend.SetSpendOrder(service.BonusFirst)
+	defer s.backend.SetSpendOrder(service.CashFirst)
+
+	player := s.player("EUR", 100)
+	s.Require().NoError(s.backend.Bonuses.GrantBonus(s.ctx, player, "EUR", &model.Bonus{BonusID: "deposit", Granted: 500, Wagering: 5000}))
+
+	_, balance, err := s.bet(player, "order:1", "deposit", 200, 0)
+	s.Require().NoError(err)
+	s.Equal(int64(100), balance.Amount)
+	s.Equal(int64(300), balance.BonusAmount)
+
+	_, balance, err = s.bet(player, "order:2", "deposit", 350, 0)
+	s.Require().NoError(err)
+	s.Equal(int64(50), balance.Amount)
+	s.Equal(int64(0), balance.BonusAmount)
+
+	_, _, err = s.bet(player, "order:3", "deposit", 51, 0)
+	s.ErrorIs(err, service.ErrSpendingBudgetExceeded)
+
+	_, balance, err = s.transaction(player, s.ref("order:4"), 50, 0)
+	s.Require().NoError(err)
+	s.Equal(int64(0), balance.Amount)
+}
+
+func (s *seamlessSuite) limitError(err error) *service.LimitError {
+	var limitErr *service.LimitError
+	s.Require().ErrorAs(err, &limitErr)
+	s.ErrorIs(err, service.ErrSpendingBudgetExceeded)
+	return limitErr
+}
+
+func (s *seamlessSuite) TestLimits() {
+	player := s.player("EUR", 1000)
+	limits := s.backend.Limits
+
+	s.ErrorIs(limits.SetLimit(s.ctx, player, "EUR", &model.Limit{Type: model.LimitLoss, Window: model.WindowSession, Amount: 10}), service.ErrInvalidLimit)
+	s.ErrorIs(limits.SetLimit(s.ctx, player, "EUR", &model.Limit{Type: model.LimitWager, Window: model.WindowDaily, Amount: -1}), service.ErrInvalidLimit)
+
+	s.Require().NoError(limits.SetLimit(s.ctx, player, "EUR", &model.Limit{Type: model.LimitLoss, Window: model.WindowDaily, Amount: 100}))
+	s.Require().NoError(limits.SetLimit(s.ctx, player, "EUR", &model.Limit{Type: model.LimitWager, Window: model.WindowWeekly, Amount: 500}))
+	s.Require().NoError(limits.SetLimit(s.ctx, player, "EUR", &model.Limit{Type: model.LimitDeposit, Window: model.WindowMonthly, Amount: 300}))
+
+	_, _, err := s.transaction(player, s.ref("limit:1"), 80, 0)
+	s.Require().NoError(err)
+
+	transaction, _, err := s.transaction(player, s.ref("limit:2"), 30, 0)
+	limitErr := s.limitError(err)
+	s.Equal(model.LimitLoss, limitErr.Limit.Type)
+	s.Equal(model.WindowDaily, limitErr.Limit.Window)
+	s.Equal(int64(80), limitErr.Used)
+	s.Require().NotNil(limitErr.ResetsAt)
+	s.True(limitErr.ResetsAt.After(time.Now()))
+	s.Equal(model.StatusFailed, transaction.Status)
+	s.Equal(int64(920), s.balance(player))
+
+	// A win lowers the loss, the wager limit still counts the bets.
+	_, _, err = s.transaction(player, s.ref("limit:3"), 100, 200)
+	s.Require().NoError(err)
+
+	_, _, err = s.transaction(player, s.ref("limit:4"), 400, 400)
+	limitErr = s.limitError(err)
+	s.Equal(model.LimitWager, limitErr.Limit.Type)
+	s.Equal(int64(180), limitErr.Used)
+
+	// A deposit limit never refuses a win, it counts manual deposits.
+	_, _, err = s.transaction(player, s.ref("limit:5"), 0, 400)
+	s.Require().NoError(err)
+
+	_, err = s.backend.Admin.Adjust(s.ctx, player, "EUR", &model.Adjustment{Amount: 200, Reason: model.AdjustmentDeposit})
+	s.Require().NoError(err)
+	_, err = s.backend.Admin.Adjust(s.ctx, player, "EUR", &model.Adjustment{Amount: 500, Reason: model.AdjustmentGoodwill})
+	s.Require().NoError(err)
+
+	_, err = s.backend.Admin.Adjust(s.ctx, player, "EUR", &model.Adjustment{Amount: 150, Reason: model.AdjustmentDeposit})
+	limitErr = s.limitError(err)
+	s.Equal(model.LimitDeposit, limitErr.Limit.Type)
+	s.Equal(int64(200), limitErr.Used)
+
+	// A negative manual deposit is refused and does not free the limit.
+	_, err = s.backend.Admin.Adjust(s.ctx, player, "EUR", &model.Adjustment{Amount: -200, Reason: model.AdjustmentDeposit})
+	s.ErrorIs(err, service.ErrInvalidAdjustment)
+
+	_, err = s.backend.Admin.Adjust(s.ctx, player, "EUR", &model.Adjustment{Amount: 150, Reason: model.AdjustmentDeposit})
+	limitErr = s.limitError(err)
+	s.Equal(int64(200), limitErr.Used)
+
+	// Replacing a limit keeps one limit per type and window.
+	s.Require().NoError(limits.SetLimit(s.ctx, player, "EUR", &model.Limit{Type: model.LimitDeposit, Window: model.WindowMonthly, Amount: 1000}))
+	_, err = s.backend.Admin.Adjust(s.ctx, player, "EUR", &model.Adjustment{Amount: 150, Reason: model.AdjustmentDeposit})
+	s.Require().NoError(err)
+
+	stored, err := limits.Limits(s.ctx, player)
+	s.Require().NoError(err)
+	s.Len(stored, 3)
+
+	s.Require().NoError(limits.RemoveLimit(s.ctx, player, "EUR", model.LimitWager, model.WindowWeekly))
+	stored, err = limits.Limits(s.ctx, player)
+	s.Require().NoError(err)
+	s.Len(stored, 2)
+}
+
+func (s *seamlessSuite) TestSessionLimit() {
+	player := s.player("EUR", 1000)
+	s.Require().NoError(s.backend.Limits.SetLimit(s.ctx, player, "EUR", &model.Limit{Type: model.LimitSession, Window: model.WindowSession, Amount: 0}))
+
+	session := s.ref("session")
+	bet := func(ref string, withdraw, deposit int64) error {
+		transaction := &model.Transaction{Withdraw: withdraw, Deposit: deposit, TransactionRef: s.ref(ref), SessionId: &session}
+		_, err := s.backend.Service.Transaction(s.ctx, player, "EUR", transaction)
+		return err
+	}
+
+	s.Require().NoError(bet("session:1", 10, 0))
+
+	limitErr := s.limitError(bet("session:2", 10, 0))
+	s.Equal(model.LimitSession, limitErr.Limit.Type)
+	s.Nil(limitErr.ResetsAt)
+
+	// Wins of a session that ran out are still paid.
+	s.Require().NoError(bet("session:3", 0, 50))
+	s.Equal(int64(1040), s.balance(player))
+}
+
+func (s *seamlessSuite) TestPlayerStatus() {
+	player := s.player("EUR", 1000)
+	players := s.backend.Players
+	until := time.Now().Add(time.Hour)
+
+	status, err := players.Player(s.ctx, player)
+	s.Require().NoError(err)
+	s.Equal(model.PlayerActive, status.Status)
+
+	_, err = players.Player(s.ctx, s.ref("unknown"))
+	s.ErrorIs(err, service.ErrPlayerNotFound)
+	s.ErrorIs(players.SetPlayerStatus(s.ctx, &model.Player{PlayerName: s.ref("unknown"), Status: model.PlayerBlocked, Reason: "fraud"}), service.ErrPlayerNotFound)
+	s.ErrorIs(players.SetPlayerStatus(s.ctx, &model.Player{PlayerName: player, Status: model.PlayerCoolOff, Reason: "request"}), service.ErrInvalidPlayerStatus)
+
+	s.Require().NoError(players.SetPlayerStatus(s.ctx, &model.Player{PlayerName: player, Status: model.PlayerSelfExcluded, Reason: "request", Until: &until}))
+
+	// Bets and free round charges are refused, wins and rollbacks settle.
+	_, _, err = s.transaction(player, s.ref("status:1"), 100, 0)
+	s.ErrorIs(err, service.ErrPlayerBlocked)
+	s.Equal([]string{">failed"}, s.statuses(s.ref("status:1")))
+
+	_, _, err = s.transaction(player, s.ref("status:2"), 0, 50)
+	s.Require().NoError(err)
+	s.Require().NoError(s.rollback(player, s.ref("status:2")))
+	s.Equal(int64(1000), s.balance(player))
+
+	// A self-exclusion in force can not be lifted or shortened.
+	s.ErrorIs(players.SetPlayerStatus(s.ctx, &model.Player{PlayerName: player, Status: model.PlayerActive, Reason: "mistake"}), service.ErrPlayerStatusLocked)
+	shorter := until.Add(-time.Minute)
+	s.ErrorIs(players.SetPlayerStatus(s.ctx, &model.Player{PlayerName: player, Status: model.PlayerSelfExcluded, Reason: "request", Until: &shorter}), service.ErrPlayerStatusLocked)
+	s.Require().NoError(players.SetPlayerStatus(s.ctx, &model.Player{PlayerName: player, Status: model.PlayerBlocked, Reason: "fraud"}))
+	s.Require().NoError(players.SetPlayerStatus(service.WithActor(s.ctx, "admin:alice"), &model.Player{PlayerName: player, Status: model.PlayerActive, Reason: "checked"}))
+
+	_, _, err = s.transaction(player, s.ref("status:1"), 100, 0)
+	s.Require().NoError(err)
+
+	history, err := players.PlayerStatusHistory(s.ctx, player)
+	s.Require().NoError(err)
+	s.Require().Len(history, 3)
+	s.Equal(model.PlayerActive, history[0].FromStatus)
+	s.Equal(model.PlayerSelfExcluded, history[0].ToStatus)
+	s.Equal(model.PlayerSelfExcluded, history[1].FromStatus)
+	s.Equal(model.PlayerBlocked, history[1].ToStatus)
+	s.Equal("checked", history[2].Reason)
+	s.Equal("admin:alice", history[2].Actor)
+}
+
+func (s *seamlessSuite) TestCoolOffEnds() {
+	player := s.player("EUR", 1000)
+	until := time.Now().Add(50 * time.Millisecond)
+
+	s.Require().NoError(s.backend.Players.SetPlayerStatus(s.ctx, &model.Player{PlayerName: player, Status: model.PlayerCoolOff, Reason: "request", Until: &until}))
+
+	_, _, err := s.transaction(player, s.ref("cooloff:1"), 100, 0)
+	s.ErrorIs(err, service.ErrPlayerBlocked)
+
+	time.Sleep(time.Until(until))
+
+	status, err := s.backend.Players.Player(s.ctx, player)
+	s.Require().NoError(err)
+	s.Equal(model.PlayerActive, status.Current(time.Now()))
+
+	_, _, err = s.transaction(player, s.ref("cooloff:1"), 100, 0)
+	s.Require().NoError(err)
+}
+
+// ledger checks that every posting of the wallet balances and that its
+// cash and bonus accounts hold the cached amounts.
+func (s *seamlessSuite) ledger(playerName string) []model.LedgerEntry {
+	entries, err := s.backend.Ledger.LedgerEntries(s.ctx, playerName, "EUR")
+	s.Require().NoError(err)
+
+	postings := make(map[int]int64)
+	var cash, bonus int64
+	for _, entry := range entries {
+		postings[entry.PostingID] += entry.Amount
+		switch entry.Account {
+		case model.AccountCash:
+			cash += entry.Amount
+		case model.AccountBonus:
+			s.Require().NotNil(entry.BonusID)
+			bonus += entry.Amount
+		}
+	}
+
+	for postingID, sum := range postings {
+		s.Zero(sum, "posting %d", postingID)
+	}
+
+	amount, bonusAmount := s.wallet(playerName)
+	s.Equal(amount, cash)
+	s.Equal(bonusAmount, bonus)
+
+	return entries
+}
+
+func (s *seamlessSuite) TestLedger() {
+	player := s.player("EUR", 1000)
+	s.Len(s.ledger(player), 2)
+
+	s.Require().NoError(s.backend.Bonuses.GrantBonus(s.ctx, player, "EUR", &model.Bonus{BonusID: "ledger:1", Granted: 500, Wagering: 600}))
+
+	// The bet completes the wagering and converts the bonus to cash.
+	transaction, _, err := s.bet(player, "ledger:1", "ledger:1", 700, 0)
+	s.Require().NoError(err)
+	s.Equal(int64(500), transaction.BonusConverted)
+	s.ledger(player)
+
+	_, _, err = s.transaction(player, s.ref("ledger:2"), 100, 300)
+	s.Require().NoError(err)
+	s.Require().NoError(s.rollback(player, s.ref("ledger:2")))
+
+	s.Require().NoError(s.backend.Bonuses.GrantBonus(s.ctx, player, "EUR", &model.Bonus{BonusID: "ledger:2", Granted: 500, Wagering: 10000}))
+	_, _, err = s.bet(player, "ledger:3", "ledger:2", 900, 50)
+	s.Require().NoError(err)
+
+	cash, bonus := s.wallet(player)
+	s.Equal(int64(0), cash)
+	s.Equal(int64(450), bonus)
+	s.ledger(player)
+
+	s.Require().NoError(s.rollback(player, s.ref("ledger:3")))
+	entries := s.ledger(player)
+
+	var house int64
+	for _, entry := range entries {
+		if entry.Account == model.AccountHouse {
+			house += entry.Amount
+		}
+	}
+	// The house paid both bonuses and won the first bet.
+	s.Equal(int64(700-500-500), house)
+
+	_, err = s.backend.Ledger.RebuildBalances(s.ctx)
+	s.Require().NoError(err)
+
+	cash, bonus = s.wallet(player)
+	s.Equal(int64(800), cash)
+	s.Equal(int64(500), bonus)
+}
+
+func (s *seamlessSuite) TestBalanceHistory() {
+	player := s.player("EUR", 1000)
+	ctx := service.WithActor(s.ctx, "caller:1")
+
+	transaction := &model.Transaction{Withdraw: 300, Deposit: 100, TransactionRef: s.ref("history:1")}
+	_, err := s.backend.Service.Transaction(ctx, player, "EUR", transaction)
+	s.Require().NoError(err)
+
+	// A refused bet does not change the wallet.
+	_, _, err = s.transaction(player, s.ref("history:2"), 5000, 0)
+	s.ErrorIs(err, service.ErrSpendingBudgetExceeded)
+
+	between := time.Now()
+	time.Sleep(10 * time.Millisecond)
+
+	s.Require().NoError(s.backend.Service.Rollback(ctx, player, &model.Transaction{TransactionRef: s.ref("history:1")}))
+	s.Require().NoError(s.backend.Bonuses.GrantBonus(s.ctx, player, "EUR", &model.Bonus{BonusID: "history:1", Granted: 200, Wagering: 400}))
+
+	history, err := s.backend.Ledger.BalanceHistory(s.ctx, player, "EUR", nil, nil)
+	s.Require().NoError(err)
+	s.Require().Len(history, 4)
+
+	s.Equal(model.CauseOpening, history[0].Cause)
+	s.Equal(int64(1000), history[0].AmountAfter)
+
+	bet := history[1]
+	s.Equal(model.CauseWithdrawAndDeposit, bet.Cause)
+	s.Equal("caller:1", bet.Actor)
+	s.Require().NotNil(bet.TransactionID)
+	s.Equal(transaction.ID, *bet.TransactionID)
+	s.Equal(int64(1000), bet.AmountBefore)
+	s.Equal(int64(800), bet.AmountAfter)
+	s.Equal(int64(-200), bet.Delta)
+
+	rollback := history[2]
+	s.Equal(model.CauseRollbackTransaction, rollback.Cause)
+	s.Equal("caller:1", rollback.Actor)
+	s.Equal(transaction.ID, *rollback.TransactionID)
+	s.Equal(int64(800), rollback.AmountBefore)
+	s.Equal(int64(1000), rollback.AmountAfter)
+
+	grant := history[3]
+	s.Equal(model.CauseGrantBonus, grant.Cause)
+	s.Zero(grant.Delta)
+	s.Equal(int64(200), grant.BonusDelta)
+
+	history, err = s.backend.Ledger.BalanceHistory(s.ctx, player, "EUR", &between, nil)
+	s.Require().NoError(err)
+	s.Len(history, 2)
+
+	history, err = s.backend.Ledger.BalanceHistory(s.ctx, player, "EUR", nil, &between)
+	s.Require().NoError(err)
+	s.Len(history, 2)
+}
+
+func (s *seamlessSuite) history(filter model.TransactionFilter) []string {
+	records, err := s.backend.Service.TransactionHistory(s.ctx, &filter)
+	s.Require().NoError(err)
+
+	refs := make([]string, 0, len(records))
+	for _, record := range records {
+		refs = append(refs, strings.TrimPrefix(record.TransactionRef, s.prefix+":"))
+	}
+	return refs
+}
+
+func (s *seamlessSuite) TestTransactionHistory() {
+	player := s.player("EUR", 1000)
+	_, err := s.backend.Service.CreateCurrency(s.ctx, "USD", 2)
+	s.Require().NoError(err)
+
+	gameID, roundRef := "history:game", "history:round"
+	for i, ref := range []string{"txhistory:1", "txhistory:2", "txhistory:3"} {
+		transaction := &model.Transaction{
+			Withdraw:       100,
+			TransactionRef: s.ref(ref),
+			GameID:         &gameID,
+			SpinDetails:    &model.SpinDetails{BetType: "spin", WinType: "standart"},
+		}
+		if i > 0 {
+			transaction.GameRoundRef = &roundRef
+		}
+		_, err := s.backend.Service.Transaction(s.ctx, player, "EUR", transaction)
+		s.Require().NoError(err)
+	}
+
+	_, _, err = s.transaction(player, s.ref("txhistory:4"), 5000, 0)
+	s.Require().Error(err)
+	s.Require().NoError(s.rollback(player, s.ref("txhistory:2")))
+
+	usd := &model.Transaction{Deposit: 10, TransactionRef: s.ref("txhistory:5")}
+	_, err = s.backend.Service.Transaction(s.ctx, player, "USD", usd)
+	s.Require().NoError(err)
+
+	s.Equal([]string{"txhistory:5", "txhistory:4", "txhistory:3", "txhistory:2", "txhistory:1"},
+		s.history(model.TransactionFilter{PlayerName: player}))
+
+	currency := "EUR"
+	s.Equal([]string{"txhistory:4", "txhistory:3", "txhistory:2", "txhistory:1"},
+		s.history(model.TransactionFilter{PlayerName: player, Currency: &currency}))
+	s.Equal([]string{"txhistory:3", "txhistory:2"},
+		s.history(model.TransactionFilter{PlayerName: player, GameID: &gameID, GameRoundRef: &roundRef}))
+
+	rolledBack := model.StatusRolledBack
+	s.Equal([]string{"txhistory:2"}, s.history(model.TransactionFilter{PlayerName: player, Status: &rolledBack}))
+
+	failed := model.StatusFailed
+	s.Equal([]string{"txhistory:4"}, s.history(model.TransactionFilter{PlayerName: player, Status: &failed}))
+
+	// Pages continue before the last transaction of the previous one.
+	page, err := s.backend.Service.TransactionHistory(s.ctx, &model.TransactionFilter{PlayerName: player, Limit: 2})
+	s.Require().NoError(err)
+	s.Require().Len(page, 2)
+	s.Equal([]string{"txhistory:3", "txhistory:2"},
+		s.history(model.TransactionFilter{PlayerName: player, BeforeID: page[1].ID, Limit: 2}))
+
+	future := time.Now().Add(time.Hour)
+	s.Empty(s.history(model.TransactionFilter{PlayerName: player, From: &future}))
+	s.Len(s.history(model.TransactionFilter{PlayerName: player, To: &future}), 5)
+
+	records, err := s.backend.Service.TransactionHistory(s.ctx, &model.TransactionFilter{PlayerName: player, Currency: &currency, Limit: 2})
+	s.Require().NoError(err)
+	s.Require().Len(records, 2)
+	s.Equal("EUR", records[1].Currency)
+	s.Require().NotNil(records[1].SpinDetails)
+	s.Equal("spin", records[1].SpinDetails.BetType)
+	s.Equal(int64(700), *records[1].BalanceAfter)
+}
+
+func (s *seamlessSuite) TestAdminWallets() {
+	player := fmt.Sprintf("%s:admin%d", s.prefix, playerSeq.Add(1))
+
+	_, err := s.backend.Service.CreateCurrency(s.ctx, "EUR", 2)
+	s.Require().NoError(err)
+
+	balance, err := s.backend.Admin.CreateWallet(s.ctx, player, "EUR", nil)
+	s.Require().NoError(err)
+	s.Equal(int64(0), balance.Amount)
+	s.Equal("EUR", balance.Currency.Code)
+
+	history, err := s.backend.Ledger.BalanceHistory(s.ctx, player, "EUR", nil, nil)
+	s.Require().NoError(err)
+	s.Empty(history)
+
+	_, err = s.backend.Admin.CreateWallet(s.ctx, player, "EUR", nil)
+	s.ErrorIs(err, service.ErrWalletExists)
+
+	_, err = s.backend.Admin.CreateWallet(s.ctx, player, "XXX", nil)
+	s.ErrorIs(err, service.ErrIllegalCurrencyCode)
+
+	ctx := service.WithActor(s.ctx, "admin:alice")
+	credit := &model.Adjustment{Amount: 500, Reason: model.AdjustmentDeposit, Comment: "wire"}
+	balance, err = s.backend.Admin.Adjust(ctx, player, "EUR", credit)
+	s.Require().NoError(err)
+	s.Equal(int64(500), balance.Amount)
+	s.NotZero(credit.ID)
+
+	_, err = s.backend.Admin.Adjust(ctx, player, "EUR", &model.Adjustment{Amount: -600, Reason: model.AdjustmentCorrection})
+	s.ErrorIs(err, service.ErrNotEnoughMoneyCode)
+
+	_, err = s.backend.Admin.Adjust(ctx, player, "EUR", &model.Adjustment{Amount: -100, Reason: "oops"})
+	s.ErrorIs(err, service.ErrInvalidAdjustment)
+
+	_, err = s.backend.Admin.Adjust(ctx, player, "EUR", &model.Adjustment{Amount: -100, Reason: model.AdjustmentDeposit})
+	s.ErrorIs(err, service.ErrInvalidAdjustment)
+
+	_, err = s.backend.Admin.Adjust(ctx, player, "EUR", &model.Adjustment{Amount: 100, Reason: model.AdjustmentWithdrawal})
+	s.ErrorIs(err, service.ErrInvalidAdjustment)
+	s.Equal(int64(500), s.balance(player))
+
+	balance, err = s.backend.Admin.Adjust(ctx, player, "EUR", &model.Adjustment{Amount: -100, Reason: model.AdjustmentChargeback})
+	s.Require().NoError(err)
+	s.Equal(int64(400), balance.Amount)
+	s.Equal(int64(400), s.balance(player))
+
+	adjustments, err := s.backend.Admin.Adjustments(s.ctx, player)
+	s.Require().NoError(err)
+	s.Require().Len(adjustments, 2)
+	s.Equal(model.AdjustmentDeposit, adjustments[0].Reason)
+	s.Equal("wire", adjustments[0].Comment)
+	s.Equal("admin:alice", adjustments[0].Actor)
+	s.Equal("EUR", adjustments[1].Currency.Code)
+	s.Equal(int64(-100), adjustments[1].Amount)
+
+	// Manual adjustments go against the cashier, not the house.
+	for _, entry := range s.ledger(player) {
+		s.Equal(model.EntryManual, entry.Kind)
+		s.NotEqual(model.AccountHouse, entry.Account)
+	}
+
+	history, err = s.backend.Ledger.BalanceHistory(s.ctx, player, "EUR", nil, nil)
+	s.Require().NoError(err)
+	s.Require().Len(history, 2)
+	s.Equal(model.CauseManualAdjustment, history[1].Cause)
+	s.Equal("admin:alice", history[1].Actor)
+}
+
+func (s *seamlessSuite) TestAdminTransactions() {
+	player := s.player("EUR", 1000)
+
+	transaction, _, err := s.transaction(player, s.ref("admin:1"), 300, 0)
+	s.Require().NoError(err)
+
+	record, err := s.backend.Admin.TransactionByRef(s.ctx, s.ref("admin:1"))
+	s.Require().NoError(err)
+	s.Equal(transaction.ID, record.ID)
+	s.Equal(player, record.PlayerName)
+	s.Equal("EUR", record.Currency)
+	s.Equal(model.StatusCommitted, record.Status)
+
+	_, err = s.backend.Admin.TransactionByRef(s.ctx, s.ref("admin:unknown"))
+	s.ErrorIs(err, service.ErrTransactionNotFound)
+
+	s.Require().NoError(s.backend.FreeRounds.GrantFreeRounds(s.ctx, player, "EUR", &model.FreeRound{BonusID: "admin:fr", Rounds: 5}))
+	gameID := "riot"
+	_, err = s.charge(player, "admin:charge", "admin:fr", &gameID, 5, 0)
+	s.Require().NoError(err)
+
+	left := 3
+	freeRound, err := s.backend.FreeRounds.EditFreeRounds(s.ctx, player, "EUR", "admin:fr", &model.FreeRoundEdit{RoundsLeft: &left})
+	s.Require().NoError(err)
+	s.Equal(model.FreeRoundActive, freeRound.Status)
+	s.Equal(3, freeRound.RoundsLeft)
+	s.Equal(8, freeRound.Rounds)
+
+	expiresAt := time.Now().Add(-time.Minute)
+	freeRound, err = s.backend.FreeRounds.EditFreeRounds(s.ctx, player, "EUR", "admin:fr", &model.FreeRoundEdit{ExpiresAt: &expiresAt})
+	s.Require().NoError(err)
+	s.True(freeRound.Expired(time.Now()))
+
+	_, err = s.backend.FreeRounds.EditFreeRounds(s.ctx, player, "EUR", "admin:fr", &model.FreeRoundEdit{RoundsLeft: &left})
+	s.ErrorIs(err, service.ErrFreeRoundsNotFound)
+
+	_, err = s.backend.FreeRounds.EditFreeRounds(s.ctx, player, "EUR", "admin:none", &model.FreeRoundEdit{RoundsLeft: &left})
+	s.ErrorIs(err, service.ErrFreeRoundsNotFound)
+}
+
+func (s *seamlessSuite) TestConcurrentBets() {
+	player := s.player("EUR", 300)
+
+	var wg sync.WaitGroup
+	var succeeded, exceeded atomic.Int64
+
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			_, _, err := s.transaction(player, s.ref(fmt.Sprintf("concurrent:%d", i)), 10, 0)
+			switch err {
+			case nil:
+				succeeded.Add(1)
+			case service.ErrSpendingBudgetExceeded:
+				exceeded.Add(1)
+			default:
+				s.T().Errorf("unexpected error: %v", err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	s.Equal(int64(30), succeeded.Load())
+	s.Equal(int64(20), exceeded.Load())
+	s.Equal(int64(0), s.balance(player))
+}
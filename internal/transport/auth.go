@@ -0,0 +1,41 @@
+package transport
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"seamless-api-wrapper/internal/config"
+	"strings"
+)
+
+type auth struct {
+	tokens []config.Token
+}
+
+// newAuth returns nil when no tokens are configured, then requests are not
+// authenticated.
+func newAuth(cfg config.Auth) *auth {
+	if len(cfg.Tokens) == 0 {
+		return nil
+	}
+
+	return &auth{tokens: cfg.Tokens}
+}
+
+// user returns the name of the token the request carries. Every token is
+// compared so the time taken does not tell which one is close.
+func (a *auth) user(r *http.Request) (string, bool) {
+	header := r.Header.Get("Authorization")
+	if len(header) < len("Bearer ") || !strings.EqualFold(header[:len("Bearer ")], "Bearer ") {
+		return "", false
+	}
+	token := []byte(header[len("Bearer "):])
+
+	name, ok := "", false
+	for _, t := range a.tokens {
+		if subtle.ConstantTimeCompare(token, []byte(t.Token)) == 1 && !ok {
+			name, ok = t.Name, true
+		}
+	}
+
+	return name, ok
+}
@@ -0,0 +1,221 @@
+package transport
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"github.com/klauspost/compress/zstd"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const (
+	encodingGzip     = "gzip"
+	encodingZstd     = "zstd"
+	encodingIdentity = "identity"
+)
+
+var (
+	errUnsupportedEncoding = errors.New("rpc: unsupported content-encoding")
+	errBodyTooLarge        = errors.New("rpc: request body too large")
+)
+
+var (
+	gzipWriterPool sync.Pool
+	gzipReaderPool sync.Pool
+	zstdWriterPool sync.Pool
+	zstdReaderPool sync.Pool
+)
+
+// decodeBody wraps the request body according to its Content-Encoding. The
+// returned closer releases pooled decoders and must be called once the body
+// has been read.
+func decodeBody(r *http.Request) (io.Reader, func(), error) {
+	encoding := strings.ToLower(strings.TrimSpace(r.Header.Get("Content-Encoding")))
+
+	switch encoding {
+	case "", encodingIdentity:
+		return r.Body, func() {}, nil
+	case encodingGzip:
+		zr, _ := gzipReaderPool.Get().(*gzip.Reader)
+		if zr == nil {
+			var err error
+			if zr, err = gzip.NewReader(r.Body); err != nil {
+				return nil, nil, err
+			}
+		} else if err := zr.Reset(r.Body); err != nil {
+			gzipReaderPool.Put(zr)
+			return nil, nil, err
+		}
+		return zr, func() { gzipReaderPool.Put(zr) }, nil
+	case encodingZstd:
+		zr, _ := zstdReaderPool.Get().(*zstd.Decoder)
+		if zr == nil {
+			var err error
+			if zr, err = zstd.NewReader(r.Body, zstd.WithDecoderConcurrency(1)); err != nil {
+				return nil, nil, err
+			}
+		} else if err := zr.Reset(r.Body); err != nil {
+			zstdReaderPool.Put(zr)
+			return nil, nil, err
+		}
+		return zr, func() {
+			_ = zr.Reset(nil)
+			zstdReaderPool.Put(zr)
+		}, nil
+	}
+
+	return nil, nil, errUnsupportedEncoding
+}
+
+// limitReader fails reads past n bytes and remembers it, so a small
+// compressed body cannot be inflated without bound.
+type limitReader struct {
+	r        io.Reader
+	n        int64
+	exceeded bool
+}
+
+func (l *limitReader) Read(p []byte) (int, error) {
+	if l.n <= 0 {
+		var probe [1]byte
+		if n, _ := l.r.Read(probe[:]); n == 0 {
+			return 0, io.EOF
+		}
+		l.exceeded = true
+		return 0, errBodyTooLarge
+	}
+
+	if int64(len(p)) > l.n {
+		p = p[:l.n]
+	}
+	n, err := l.r.Read(p)
+	l.n -= int64(n)
+	return n, err
+}
+
+// acceptEncoding picks the response encoding from the Accept-Encoding header,
+// zstd is preferred over gzip when the client weights them equally.
+func acceptEncoding(header string) string {
+	best, bestQ := "", 0.0
+	for _, part := range strings.Split(header, ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name != encodingGzip && name != encodingZstd {
+			continue
+		}
+
+		q := 1.0
+		if params = strings.TrimSpace(params); strings.HasPrefix(params, "q=") {
+			parsed, err := strconv.ParseFloat(params[2:], 64)
+			if err != nil {
+				continue
+			}
+			q = parsed
+		}
+
+		if q > bestQ || (q > 0 && q == bestQ && name == encodingZstd) {
+			best, bestQ = name, q
+		}
+	}
+
+	return best
+}
+
+// compressWriter holds the response back until minSize bytes are written, so
+// small bodies go out as is and larger ones are compressed with encoding.
+type compressWriter struct {
+	http.ResponseWriter
+	encoding string
+	minSize  int
+	status   int
+	buf      bytes.Buffer
+	writer   io.WriteCloser
+}
+
+func newCompressWriter(w http.ResponseWriter, encoding string, minSize int) *compressWriter {
+	w.Header().Add("Vary", "Accept-Encoding")
+	return &compressWriter{ResponseWriter: w, encoding: encoding, minSize: minSize, status: http.StatusOK}
+}
+
+func (w *compressWriter) WriteHeader(status int) {
+	w.status = status
+}
+
+func (w *compressWriter) Write(data []byte) (int, error) {
+	if w.writer != nil {
+		return w.writer.Write(data)
+	}
+
+	w.buf.Write(data)
+	if w.buf.Len() < w.minSize {
+		return len(data), nil
+	}
+
+	if err := w.startCompression(); err != nil {
+		return 0, err
+	}
+	return len(data), nil
+}
+
+func (w *compressWriter) startCompression() error {
+	header := w.Header()
+	header.Set("Content-Encoding", w.encoding)
+	header.Del("Content-Length")
+	w.ResponseWriter.WriteHeader(w.status)
+
+	switch w.encoding {
+	case encodingGzip:
+		zw, _ := gzipWriterPool.Get().(*gzip.Writer)
+		if zw == nil {
+			zw = gzip.NewWriter(w.ResponseWriter)
+		} else {
+			zw.Reset(w.ResponseWriter)
+		}
+		w.writer = zw
+	case encodingZstd:
+		zw, _ := zstdWriterPool.Get().(*zstd.Encoder)
+		if zw == nil {
+			var err error
+			zw, err = zstd.NewWriter(w.ResponseWriter, zstd.WithEncoderConcurrency(1), zstd.WithEncoderLevel(zstd.SpeedFastest))
+			if err != nil {
+				return err
+			}
+		} else {
+			zw.Reset(w.ResponseWriter)
+		}
+		w.writer = zw
+	}
+
+	_, err := w.writer.Write(w.buf.Bytes())
+	w.buf.Reset()
+	return err
+}
+
+// Close flushes a held back body uncompressed or finishes the compressed
+// stream and returns the encoder to its pool.
+func (w *compressWriter) Close() error {
+	if w.writer == nil {
+		w.ResponseWriter.WriteHeader(w.status)
+		if w.buf.Len() == 0 {
+			return nil
+		}
+		_, err := w.ResponseWriter.Write(w.buf.Bytes())
+		return err
+	}
+
+	err := w.writer.Close()
+	switch zw := w.writer.(type) {
+	case *gzip.Writer:
+		gzipWriterPool.Put(zw)
+	case *zstd.Encoder:
+		zw.Reset(nil)
+		zstdWriterPool.Put(zw)
+	}
+	w.writer = nil
+
+	return err
+}
@@ -0,0 +1,95 @@
+package transport
+
+import (
+	"net/http"
+	"seamless-api-wrapper/internal/config"
+	"strconv"
+	"strings"
+)
+
+var defaultAllowedHeaders = []string{"Content-Type", "Content-Encoding", "Accept-Encoding"}
+
+type cors struct {
+	origins        map[string]struct{}
+	anyOrigin      bool
+	allowedHeaders string
+	exposedHeaders string
+	maxAge         string
+	methods        []string
+}
+
+func newCors(conf config.CORS) *cors {
+	if len(conf.AllowedOrigins) == 0 {
+		return nil
+	}
+
+	c := &cors{
+		origins: make(map[string]struct{}, len(conf.AllowedOrigins)),
+		methods: conf.Methods,
+	}
+
+	for _, origin := range conf.AllowedOrigins {
+		if origin == "*" {
+			c.anyOrigin = true
+		}
+		c.origins[strings.ToLower(origin)] = struct{}{}
+	}
+
+	headers := conf.AllowedHeaders
+	if len(headers) == 0 {
+		headers = defaultAllowedHeaders
+	}
+	c.allowedHeaders = strings.Join(headers, ", ")
+	c.exposedHeaders = strings.Join(conf.ExposedHeaders, ", ")
+
+	if conf.MaxAge.Duration > 0 {
+		c.maxAge = strconv.Itoa(int(conf.MaxAge.Seconds()))
+	}
+
+	return c
+}
+
+func (c *cors) allowed(origin string) bool {
+	if c.anyOrigin {
+		return true
+	}
+
+	_, ok := c.origins[strings.ToLower(origin)]
+	return ok
+}
+
+// handle sets the CORS headers for a cross-origin request. It reports true
+// when the request was a preflight and has been answered.
+func (c *cors) handle(w http.ResponseWriter, r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	header := w.Header()
+	header.Add("Vary", "Origin")
+
+	preflight := r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != ""
+	if !c.allowed(origin) {
+		if preflight {
+			w.WriteHeader(http.StatusForbidden)
+		}
+		return preflight
+	}
+
+	header.Set("Access-Control-Allow-Origin", origin)
+
+	if !preflight {
+		if c.exposedHeaders != "" {
+			header.Set("Access-Control-Expose-Headers", c.exposedHeaders)
+		}
+		return false
+	}
+
+	header.Add("Vary", "Access-Control-Request-Method")
+	header.Add("Vary", "Access-Control-Request-Headers")
+	header.Set("Access-Control-Allow-Methods", http.MethodPost)
+	header.Set("Access-Control-Allow-Headers", c.allowedHeaders)
+	if c.maxAge != "" {
+		header.Set("Access-Control-Max-Age", c.maxAge)
+	}
+	w.WriteHeader(http.StatusNoContent)
+
+	return true
+}
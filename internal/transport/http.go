@@ -1,55 +1,78 @@
 package transport
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	log "github.com/sirupsen/logrus"
 	"net"
 	"net/http"
+	"seamless-api-wrapper/internal/config"
 	"seamless-api-wrapper/internal/rpc"
 	"strings"
+	"sync/atomic"
 	"time"
 )
 
+const defaultMaxBodySize = 1 << 20
+
 type HttpServer struct {
 	addr         string
 	readTimeout  time.Duration
 	writeTimeout time.Duration
+	drainDelay   time.Duration
+	maxBodySize  int64
+	compression  config.Compression
+	status       config.Status
+	cors         *cors
+	auth         *auth
+	draining     atomic.Bool
 }
 
-func NewHttpTransport(addr string, readTimeout, writeTimeout time.Duration) *HttpServer {
+func NewHttpTransport(server *config.Server) *HttpServer {
+	maxBodySize := server.MaxBodySize
+	if maxBodySize <= 0 {
+		maxBodySize = defaultMaxBodySize
+	}
+
 	return &HttpServer{
-		addr:         addr,
-		readTimeout:  readTimeout,
-		writeTimeout: writeTimeout,
+		addr:         server.Address,
+		readTimeout:  server.ReadTimeout.Duration,
+		writeTimeout: server.WriteTimeout.Duration,
+		drainDelay:   server.DrainDelay.Duration,
+		maxBodySize:  maxBodySize,
+		compression:  server.Compression,
+		status:       server.Status,
+		cors:         newCors(server.CORS),
+		auth:         newAuth(server.Auth),
 	}
 }
 
+// Run serves until ctx is cancelled. Requests get a context of their own that
+// is only cancelled once Shutdown returns, so the ones accepted during
+// drain-delay still reach the storage.
 func (s *HttpServer) Run(ctx context.Context, resolver rpc.Resolver) error {
-	srv := http.Server{
-		Addr: s.addr,
-		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			status, err := validate(r)
-			if err != nil {
-				w.WriteHeader(status)
-				return
-			}
+	serveCtx, stopServing := context.WithCancel(context.Background())
+	defer stopServing()
 
-			w.Header().Set("Content-Type", "application/json")
-			w.WriteHeader(http.StatusOK)
-
-			resolver.Resolve(ctx, w, r.Body)
-			r.Body.Close()
-		}),
+	srv := http.Server{
+		Addr:         s.addr,
+		Handler:      s.handler(serveCtx, resolver),
 		ReadTimeout:  s.readTimeout,
 		WriteTimeout: s.writeTimeout,
 		BaseContext: func(l net.Listener) context.Context {
-			return ctx
+			return serveCtx
 		},
 	}
+	stopped := make(chan struct{})
 	go func() {
+		defer close(stopped)
+		defer stopServing()
 		<-ctx.Done()
 
+		s.draining.Store(true)
+		time.Sleep(s.drainDelay)
+
 		ctxDone, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 		defer cancel()
 
@@ -61,9 +84,112 @@ func (s *HttpServer) Run(ctx context.Context, resolver rpc.Resolver) error {
 	if err := srv.ListenAndServe(); err != http.ErrServerClosed {
 		return err
 	}
+
+	<-stopped
 	return nil
 }
 
+func (s *HttpServer) handler(ctx context.Context, resolver rpc.Resolver) http.Handler {
+	crossOriginCtx := ctx
+	if s.cors != nil && len(s.cors.methods) > 0 {
+		crossOriginCtx = rpc.WithAllowedMethods(ctx, s.cors.methods)
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := ctx
+		if s.cors != nil && r.Header.Get("Origin") != "" {
+			if s.cors.handle(w, r) {
+				return
+			}
+			ctx = crossOriginCtx
+		}
+
+		status, err := validate(r)
+		if err != nil {
+			w.WriteHeader(status)
+			return
+		}
+
+		if s.auth != nil {
+			user, ok := s.auth.user(r)
+			if !ok {
+				w.Header().Set("WWW-Authenticate", "Bearer")
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			ctx = rpc.WithUser(ctx, user)
+		}
+
+		if s.status.Draining != 0 && s.draining.Load() {
+			w.Header().Set("Connection", "close")
+			w.WriteHeader(s.status.Draining)
+			return
+		}
+
+		body, release, err := decodeBody(r)
+		if errors.Is(err, errUnsupportedEncoding) {
+			w.WriteHeader(http.StatusUnsupportedMediaType)
+			return
+		}
+
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		defer release()
+		limited := &limitReader{r: body, n: s.maxBodySize}
+
+		w.Header().Set("Content-Type", "application/json")
+
+		if encoding := s.responseEncoding(r); encoding != "" {
+			cw := newCompressWriter(w, encoding, s.compression.MinSize)
+			defer func() {
+				if err := cw.Close(); err != nil {
+					log.Error(err)
+				}
+			}()
+			w = cw
+		}
+
+		buf := responsePool.Get().(*bytes.Buffer)
+		defer func() {
+			buf.Reset()
+			responsePool.Put(buf)
+		}()
+
+		outcome := resolver.Resolve(rpc.WithPeer(ctx, r.RemoteAddr), buf, limited)
+		r.Body.Close()
+
+		if limited.exceeded {
+			w.Header().Del("Content-Type")
+			w.Header().Set("Connection", "close")
+			w.WriteHeader(http.StatusRequestEntityTooLarge)
+			return
+		}
+
+		status = statusCode(s.status, outcome)
+		if !bodyAllowed(status) {
+			w.Header().Del("Content-Type")
+			w.WriteHeader(status)
+			return
+		}
+
+		w.WriteHeader(status)
+
+		if _, err := w.Write(buf.Bytes()); err != nil {
+			log.Error(err)
+		}
+	})
+}
+
+func (s *HttpServer) responseEncoding(r *http.Request) string {
+	if !s.compression.Enabled {
+		return ""
+	}
+
+	return acceptEncoding(r.Header.Get("Accept-Encoding"))
+}
+
 func validate(r *http.Request) (int, error) {
 	if r.Method != http.MethodPost {
 		return http.StatusMethodNotAllowed, errors.New("rpc: POST method required, received " + r.Method)
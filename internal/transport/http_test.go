@@ -0,0 +1,359 @@
+package transport
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"github.com/klauspost/compress/zstd"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"seamless-api-wrapper/internal/config"
+	"seamless-api-wrapper/internal/rpc"
+	"strings"
+	"testing"
+	"time"
+)
+
+type echoResolver struct {
+	outcome rpc.Outcome
+}
+
+func (e echoResolver) Resolve(_ context.Context, w io.Writer, r io.Reader) rpc.Outcome {
+	if e.outcome.Kind != rpc.OutcomeNotification {
+		_, _ = io.Copy(w, r)
+	}
+	return e.outcome
+}
+
+func newTestHandler(server config.Server) http.Handler {
+	return NewHttpTransport(&server).handler(context.Background(), echoResolver{})
+}
+
+func newRequest(body []byte, header map[string]string) *http.Request {
+	req := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range header {
+		req.Header.Set(k, v)
+	}
+	return req
+}
+
+func TestCompressedRequest(t *testing.T) {
+	handler := newTestHandler(config.Server{})
+	payload := `{"jsonrpc":"2.0","method":"getBalance","id":1}`
+
+	var gz bytes.Buffer
+	zw := gzip.NewWriter(&gz)
+	_, _ = zw.Write([]byte(payload))
+	_ = zw.Close()
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, newRequest(gz.Bytes(), map[string]string{"Content-Encoding": "gzip"}))
+	if rec.Code != http.StatusOK || rec.Body.String() != payload {
+		t.Errorf("gzip: got %d %q", rec.Code, rec.Body.String())
+	}
+
+	enc, _ := zstd.NewWriter(nil)
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, newRequest(enc.EncodeAll([]byte(payload), nil), map[string]string{"Content-Encoding": "zstd"}))
+	if rec.Code != http.StatusOK || rec.Body.String() != payload {
+		t.Errorf("zstd: got %d %q", rec.Code, rec.Body.String())
+	}
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, newRequest([]byte(payload), map[string]string{"Content-Encoding": "br"}))
+	if rec.Code != http.StatusUnsupportedMediaType {
+		t.Errorf("br: got %d", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, newRequest([]byte(payload), map[string]string{"Content-Encoding": "gzip"}))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("corrupt gzip: got %d", rec.Code)
+	}
+}
+
+func TestBodyLimit(t *testing.T) {
+	handler := newTestHandler(config.Server{MaxBodySize: 64})
+	payload := `{"jsonrpc":"2.0","method":"getBalance","id":1}`
+	bomb := bytes.Repeat([]byte(" "), 1<<20)
+
+	var gz bytes.Buffer
+	zw := gzip.NewWriter(&gz)
+	_, _ = zw.Write(bomb)
+	_ = zw.Close()
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, newRequest(gz.Bytes(), map[string]string{"Content-Encoding": "gzip"}))
+	if rec.Code != http.StatusRequestEntityTooLarge || rec.Body.Len() != 0 {
+		t.Errorf("gzip bomb: got %d %q", rec.Code, rec.Body.String())
+	}
+
+	enc, _ := zstd.NewWriter(nil)
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, newRequest(enc.EncodeAll(bomb, nil), map[string]string{"Content-Encoding": "zstd"}))
+	if rec.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("zstd bomb: got %d", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, newRequest(bomb, nil))
+	if rec.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("plain body: got %d", rec.Code)
+	}
+
+	exact := payload + strings.Repeat(" ", 64-len(payload))
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, newRequest([]byte(exact), nil))
+	if rec.Code != http.StatusOK || rec.Body.String() != exact {
+		t.Errorf("body at the limit: got %d %q", rec.Code, rec.Body.String())
+	}
+}
+
+func TestCompressedResponse(t *testing.T) {
+	handler := newTestHandler(config.Server{Compression: config.Compression{Enabled: true, MinSize: 64}})
+	large := []byte(`[` + strings.Repeat(`{"jsonrpc":"2.0","result":{"balance":10000},"id":1},`, 20) + `{}]`)
+	small := []byte(`{"jsonrpc":"2.0","result":{},"id":1}`)
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, newRequest(large, map[string]string{"Accept-Encoding": "gzip, deflate"}))
+	if rec.Header().Get("Content-Encoding") != "gzip" {
+		t.Fatalf("expected gzip response, got %q", rec.Header().Get("Content-Encoding"))
+	}
+	zr, err := gzip.NewReader(rec.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := io.ReadAll(zr); !bytes.Equal(data, large) {
+		t.Errorf("gzip: got %q", data)
+	}
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, newRequest(large, map[string]string{"Accept-Encoding": "gzip;q=0.5, zstd"}))
+	if rec.Header().Get("Content-Encoding") != "zstd" {
+		t.Fatalf("expected zstd response, got %q", rec.Header().Get("Content-Encoding"))
+	}
+	dec, _ := zstd.NewReader(nil)
+	if data, err := dec.DecodeAll(rec.Body.Bytes(), nil); err != nil || !bytes.Equal(data, large) {
+		t.Errorf("zstd: got %q, %v", data, err)
+	}
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, newRequest(small, map[string]string{"Accept-Encoding": "gzip"}))
+	if rec.Header().Get("Content-Encoding") != "" || !bytes.Equal(rec.Body.Bytes(), small) {
+		t.Errorf("small body: got %q %q", rec.Header().Get("Content-Encoding"), rec.Body.String())
+	}
+}
+
+func TestAcceptEncoding(t *testing.T) {
+	cases := map[string]string{
+		"":                     "",
+		"deflate, br":          "",
+		"gzip":                 "gzip",
+		"gzip, zstd":           "zstd",
+		"zstd;q=0.1, gzip":     "gzip",
+		"gzip;q=0, zstd;q=0":   "",
+		"GZIP;q=0.8, identity": "gzip",
+	}
+
+	for header, expected := range cases {
+		if got := acceptEncoding(header); got != expected {
+			t.Errorf("%q: got %q, expected %q", header, got, expected)
+		}
+	}
+}
+
+func TestStatusMapping(t *testing.T) {
+	server := config.Server{Status: config.Status{
+		Notification:   http.StatusNoContent,
+		ParseError:     http.StatusBadRequest,
+		InvalidRequest: http.StatusBadRequest,
+		InternalError:  http.StatusInternalServerError,
+		Draining:       http.StatusServiceUnavailable,
+	}}
+	payload := []byte(`{"jsonrpc":"2.0","error":{"code":-32700,"message":"parse error"},"id":null}`)
+
+	cases := map[rpc.Outcome]int{
+		{Kind: rpc.OutcomeOK}:                                        http.StatusOK,
+		{Kind: rpc.OutcomeNotification}:                              http.StatusNoContent,
+		{Kind: rpc.OutcomeParseError}:                                http.StatusBadRequest,
+		{Kind: rpc.OutcomeInvalidRequest}:                            http.StatusBadRequest,
+		{Kind: rpc.OutcomeInternalError}:                             http.StatusInternalServerError,
+		{Kind: rpc.OutcomeError, Status: http.StatusPaymentRequired}: http.StatusOK,
+	}
+
+	for outcome, expected := range cases {
+		handler := NewHttpTransport(&server).handler(context.Background(), echoResolver{outcome: outcome})
+
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, newRequest(payload, nil))
+		if rec.Code != expected {
+			t.Errorf("%s: got %d, expected %d", outcome, rec.Code, expected)
+		}
+
+		if expected == http.StatusNoContent && rec.Body.Len() != 0 {
+			t.Errorf("%s: unexpected body %q", outcome, rec.Body.String())
+		}
+	}
+
+	server.Status.ErrorStatus = true
+	rec := httptest.NewRecorder()
+	errorOutcome := rpc.Outcome{Kind: rpc.OutcomeError, Status: http.StatusPaymentRequired}
+	NewHttpTransport(&server).handler(context.Background(), echoResolver{outcome: errorOutcome}).ServeHTTP(rec, newRequest(payload, nil))
+	if rec.Code != http.StatusPaymentRequired {
+		t.Errorf("error status: got %d", rec.Code)
+	}
+
+	srv := NewHttpTransport(&server)
+	srv.draining.Store(true)
+
+	rec = httptest.NewRecorder()
+	srv.handler(context.Background(), echoResolver{}).ServeHTTP(rec, newRequest(payload, nil))
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("draining: got %d", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	newTestHandler(config.Server{}).ServeHTTP(rec, newRequest(payload, nil))
+	if rec.Code != http.StatusOK {
+		t.Errorf("default mapping: got %d", rec.Code)
+	}
+}
+
+type ctxResolver struct {
+	errs chan error
+}
+
+func (c ctxResolver) Resolve(ctx context.Context, w io.Writer, r io.Reader) rpc.Outcome {
+	_, _ = io.Copy(w, r)
+	c.errs <- ctx.Err()
+	return rpc.Outcome{Kind: rpc.OutcomeOK}
+}
+
+func TestDrainContext(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := l.Addr().String()
+	_ = l.Close()
+
+	srv := NewHttpTransport(&config.Server{Address: addr, DrainDelay: config.Duration{Duration: 300 * time.Millisecond}})
+	resolver := ctxResolver{errs: make(chan error, 1)}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	stopped := make(chan error, 1)
+	go func() { stopped <- srv.Run(ctx, resolver) }()
+
+	for i := 0; ; i++ {
+		conn, err := net.Dial("tcp", addr)
+		if err == nil {
+			_ = conn.Close()
+			break
+		}
+		if i == 50 {
+			t.Fatal(err)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	cancel()
+	time.Sleep(50 * time.Millisecond)
+
+	resp, err := http.Post("http://"+addr, "application/json", strings.NewReader(`{"jsonrpc":"2.0","method":"getBalance","id":1}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = resp.Body.Close()
+
+	if err := <-resolver.errs; err != nil {
+		t.Errorf("request during drain-delay got a cancelled context: %v", err)
+	}
+
+	if err := <-stopped; err != nil {
+		t.Error(err)
+	}
+}
+
+func TestCors(t *testing.T) {
+	handler := newTestHandler(config.Server{CORS: config.CORS{
+		AllowedOrigins: []string{"https://backoffice.example.com"},
+		ExposedHeaders: []string{"Content-Encoding"},
+		MaxAge:         config.Duration{Duration: 10 * time.Minute},
+	}})
+	payload := []byte(`{"jsonrpc":"2.0","method":"getBalance","id":1}`)
+
+	preflight := httptest.NewRequest(http.MethodOptions, "/", nil)
+	preflight.Header.Set("Origin", "https://backoffice.example.com")
+	preflight.Header.Set("Access-Control-Request-Method", http.MethodPost)
+	preflight.Header.Set("Access-Control-Request-Headers", "content-type")
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, preflight)
+	if rec.Code != http.StatusNoContent {
+		t.Errorf("preflight: got %d", rec.Code)
+	}
+	if rec.Header().Get("Access-Control-Allow-Origin") != "https://backoffice.example.com" ||
+		rec.Header().Get("Access-Control-Allow-Methods") != http.MethodPost ||
+		rec.Header().Get("Access-Control-Max-Age") != "600" {
+		t.Errorf("preflight: unexpected headers %v", rec.Header())
+	}
+
+	preflight.Header.Set("Origin", "https://evil.example.com")
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, preflight)
+	if rec.Code != http.StatusForbidden || rec.Header().Get("Access-Control-Allow-Origin") != "" {
+		t.Errorf("foreign preflight: got %d %v", rec.Code, rec.Header())
+	}
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, newRequest(payload, map[string]string{"Origin": "https://backoffice.example.com"}))
+	if rec.Code != http.StatusOK || rec.Header().Get("Access-Control-Expose-Headers") != "Content-Encoding" {
+		t.Errorf("cross-origin post: got %d %v", rec.Code, rec.Header())
+	}
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodOptions, "/", nil))
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("plain options: got %d", rec.Code)
+	}
+}
+
+type userResolver struct{}
+
+func (userResolver) Resolve(ctx context.Context, w io.Writer, _ io.Reader) rpc.Outcome {
+	_, _ = io.WriteString(w, rpc.User(ctx))
+	return rpc.Outcome{}
+}
+
+func TestAuth(t *testing.T) {
+	server := config.Server{Auth: config.Auth{Tokens: []config.Token{
+		{Name: "alice", Token: "secret-a"},
+		{Name: "bob", Token: "secret-b"},
+	}}}
+	handler := NewHttpTransport(&server).handler(context.Background(), userResolver{})
+	payload := []byte(`{"jsonrpc":"2.0","method":"createWallet","id":1}`)
+
+	for _, test := range []struct {
+		header string
+		status int
+		user   string
+	}{
+		{header: "", status: http.StatusUnauthorized},
+		{header: "Bearer wrong", status: http.StatusUnauthorized},
+		{header: "Basic secret-a", status: http.StatusUnauthorized},
+		{header: "Bearer secret-a", status: http.StatusOK, user: "alice"},
+		{header: "bearer secret-b", status: http.StatusOK, user: "bob"},
+	} {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, newRequest(payload, map[string]string{"Authorization": test.header}))
+		if rec.Code != test.status {
+			t.Errorf("%q: got %d, expected %d", test.header, rec.Code, test.status)
+		}
+		if test.status == http.StatusOK && rec.Body.String() != test.user {
+			t.Errorf("%q: got user %q, expected %q", test.header, rec.Body.String(), test.user)
+		}
+	}
+}
@@ -0,0 +1,46 @@
+package transport
+
+import (
+	"bytes"
+	"net/http"
+	"seamless-api-wrapper/internal/config"
+	"seamless-api-wrapper/internal/rpc"
+	"sync"
+)
+
+var responsePool = sync.Pool{
+	New: func() any {
+		return bytes.NewBuffer(make([]byte, 0, 4096))
+	},
+}
+
+// statusCode returns the HTTP status configured for an RPC outcome. With
+// ErrorStatus set the status carried by the error of a single call wins.
+func statusCode(status config.Status, outcome rpc.Outcome) int {
+	if status.ErrorStatus && outcome.Status != 0 {
+		return outcome.Status
+	}
+
+	code := 0
+	switch outcome.Kind {
+	case rpc.OutcomeNotification:
+		code = status.Notification
+	case rpc.OutcomeParseError:
+		code = status.ParseError
+	case rpc.OutcomeInvalidRequest:
+		code = status.InvalidRequest
+	case rpc.OutcomeInternalError:
+		code = status.InternalError
+	}
+
+	if code == 0 {
+		return http.StatusOK
+	}
+	return code
+}
+
+// bodyAllowed reports whether a response with the given status may carry a
+// body.
+func bodyAllowed(status int) bool {
+	return status != http.StatusNoContent && status != http.StatusNotModified && status >= http.StatusOK
+}
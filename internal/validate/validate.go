@@ -3,12 +3,21 @@ package validate
 import (
 	"github.com/go-playground/validator/v10"
 	"reflect"
+	"regexp"
 	"strings"
 )
 
 var validate = validator.New()
 
+// currencyCode is the shape of a currency code. Whether the currency exists
+// is decided by the currency registry of the wallet, not here.
+var currencyCode = regexp.MustCompile(`^[A-Z0-9]{2,12}$`)
+
 func init() {
+	validate.RegisterValidation("currency", func(fl validator.FieldLevel) bool {
+		return currencyCode.MatchString(fl.Field().String())
+	})
+
 	validate.RegisterTagNameFunc(func(fld reflect.StructField) string {
 		name := strings.SplitN(fld.Tag.Get("json"), ",", 2)[0]
 		if name == "-" {
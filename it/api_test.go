@@ -45,7 +45,7 @@ func (s *apiTestSuite) SetupSuite() {
 
 	serverConf := cfg.Server
 
-	httpTransport := transport.NewHttpTransport(serverConf.Address, serverConf.ReadTimeout.Duration, serverConf.WriteTimeout.Duration)
+	httpTransport := transport.NewHttpTransport(&serverConf)
 
 	rpcServer := rpc.NewServer(httpTransport)
 
@@ -58,9 +58,7 @@ func (s *apiTestSuite) SetupSuite() {
 
 	api := seamless.NewSeamless(seamlessService)
 
-	rpcServer.Register("getBalance", rpc.HandlerWithPointer(api.GetBalance))
-	rpcServer.Register("withdrawAndDeposit", rpc.HandlerWithPointer(api.WithdrawAndDeposit))
-	rpcServer.Register("rollbackTransaction", rpc.HandlerWithPointer(api.RollbackTransaction))
+	api.Register(rpcServer)
 
 	go func() {
 		if err := rpcServer.Run(ctx); err != nil {
@@ -92,13 +90,31 @@ func (s *apiTestSuite) Test_Seamless() {
 	s.senMessage(rollbackReq, rollbackResp)
 
 	getBalanceReq = `{"jsonrpc":"2.0","method":"getBalance","params":{"callerId":1,"playerName":"player1","currency":"EUR","gameId":"riot"},"id":0}`
-	getBalanceResp = `{"jsonrpc":"2.0","result":{"balance":10000,"freeroundsLeft":0},"id":0}`
+	getBalanceResp = `{"jsonrpc":"2.0","result":{"balance":10000},"id":0}`
 	s.senMessage(getBalanceReq, getBalanceResp)
 }
 
+// addBalance opens a wallet with its opening posting and balance change, so
+// the ledger and the history agree with balances.amount.
+func (s *apiTestSuite) addBalance(playerName, currency string, amount int64) error {
+	_, err := s.dbConn.Exec(`WITH balance AS (
+		INSERT INTO balances(player_name, currency_id, amount, game_id, created_at, updated_at) 
+		SELECT $1, id, $3, 'riot', NOW(), NOW() FROM currencies WHERE code = $2 
+		RETURNING id, currency_id, amount
+	), posting AS (
+		SELECT nextval('ledger_posting_seq') id
+	), history AS (
+		INSERT INTO balance_history(balance_id, cause, actor, amount_before, amount_after, delta, bonus_delta, created_at) 
+		SELECT id, 'opening', '', 0, amount, amount, 0, NOW() FROM balance
+	) 
+	INSERT INTO ledger_entries(posting_id, balance_id, currency_id, account, kind, amount, created_at) 
+	SELECT posting.id, balance.id, balance.currency_id, entry.account, 'opening', entry.sign * balance.amount, NOW() 
+	FROM balance, posting, (VALUES ('cash', 1), ('cashier', -1)) entry(account, sign)`, playerName, currency, amount)
+	return err
+}
+
 func (s *apiTestSuite) Test_SeamlessRollback() {
-	_, err := s.dbConn.Exec(`INSERT INTO balances(player_name, currency_id, amount, game_id, created_at, updated_at) VALUES ('player2', 1, 20000, 'riot', NOW(), NOW())`)
-	s.NoError(err)
+	s.NoError(s.addBalance("player2", "EUR", 20000))
 
 	getBalanceReq := `{"jsonrpc":"2.0","method":"getBalance","params":{"callerId":1,"playerName":"player2","currency":"EUR","gameId":"riot"},"id":0}`
 	getBalanceResp := `{"jsonrpc":"2.0","result":{"balance":20000},"id":0}`
@@ -114,15 +130,14 @@ func (s *apiTestSuite) Test_SeamlessRollback() {
 }
 
 func (s *apiTestSuite) Test_SeamlessTransactions() {
-	_, err := s.dbConn.Exec(`INSERT INTO balances(player_name, currency_id, amount, game_id, created_at, updated_at) VALUES ('player3', 1, 200, 'riot', NOW(), NOW())`)
-	s.NoError(err)
+	s.NoError(s.addBalance("player3", "EUR", 200))
 
 	transactionReq := `{"jsonrpc":"2.0","method":"withdrawAndDeposit","params":{"callerId":1,"playerName":"player3","withdraw":100,"deposit":50,"currency":"EUR","transactionRef":"3:UOwGgNHPgq3OkqRE","gameRoundRef":"1wawxl:39","gameId":"riot","reason":"GAME_PLAY_FINAL","sessionId":"qx9sgvvpihtrlug","spinDetails":{"betType":"spin","winType":"standart"}},"id":0}`
-	transactionResp := `{"jsonrpc":"2.0","result":{"newBalance":150,"transactionId":"5"},"id":0}`
+	transactionResp := `{"jsonrpc":"2.0","result":{"newBalance":150,"transactionId":"3"},"id":0}`
 	s.senMessage(transactionReq, transactionResp)
 
 	transactionReq = `{"jsonrpc":"2.0","method":"withdrawAndDeposit","params":{"callerId":1,"playerName":"player3","withdraw":100,"deposit":50,"currency":"EUR","transactionRef":"3:UOwGgNHPgq3OkqRE","gameRoundRef":"1wawxl:39","gameId":"riot","reason":"GAME_PLAY_FINAL","sessionId":"qx9sgvvpihtrlug","spinDetails":{"betType":"spin","winType":"standart"}},"id":0}`
-	transactionResp = `{"jsonrpc":"2.0","result":{"newBalance":150,"transactionId":"5"},"id":0}`
+	transactionResp = `{"jsonrpc":"2.0","result":{"newBalance":150,"transactionId":"3"},"id":0}`
 	s.senMessage(transactionReq, transactionResp)
 
 	getBalanceReq := `{"jsonrpc":"2.0","method":"getBalance","params":{"callerId":1,"playerName":"player3","currency":"EUR","gameId":"riot"},"id":0}`
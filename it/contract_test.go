@@ -0,0 +1,28 @@
+package it
+
+import (
+	"seamless-api-wrapper/internal/postgres"
+	"seamless-api-wrapper/internal/service/servicetest"
+	"testing"
+)
+
+// Test_ServiceContract runs after the Test_Seamless* cases, which expect
+// fresh transaction ids.
+func (s *apiTestSuite) Test_ServiceContract() {
+	seamlessService := postgres.NewSeamlessService(s.dbConn)
+
+	servicetest.Run(s.T(), func(t *testing.T) *servicetest.Backend {
+		return &servicetest.Backend{
+			Service:       seamlessService,
+			Rounds:        seamlessService,
+			FreeRounds:    seamlessService,
+			Bonuses:       seamlessService,
+			Limits:        seamlessService,
+			Players:       seamlessService,
+			Ledger:        seamlessService,
+			Admin:         seamlessService,
+			SetSpendOrder: seamlessService.SetSpendOrder,
+			AddBalance:    s.addBalance,
+		}
+	})
+}
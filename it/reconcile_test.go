@@ -0,0 +1,64 @@
+package it
+
+import (
+	"context"
+	"seamless-api-wrapper/internal/model"
+	"seamless-api-wrapper/internal/postgres"
+)
+
+func (s *apiTestSuite) Test_ServiceReconcile() {
+	seamlessService := postgres.NewSeamlessService(s.dbConn)
+	ctx := context.Background()
+
+	s.Require().NoError(s.addBalance("reconcile", "EUR", 1000))
+	s.Require().NoError(seamlessService.GrantFreeRounds(ctx, "reconcile", "EUR", &model.FreeRound{BonusID: "reconcile", Rounds: 5}))
+
+	bonusID, charge := "reconcile", 2
+	_, err := seamlessService.Transaction(ctx, "reconcile", "EUR", &model.Transaction{
+		TransactionRef:   "reconcile:1",
+		Withdraw:         100,
+		Deposit:          40,
+		BonusId:          &bonusID,
+		ChargeFreeRounds: &charge,
+	})
+	s.Require().NoError(err)
+
+	balance, err := seamlessService.Balance(ctx, "reconcile", "EUR")
+	s.Require().NoError(err)
+
+	// Drift the way manual fixes do.
+	_, err = s.dbConn.Exec(`UPDATE balances SET amount = amount + 5 WHERE id = $1`, balance.ID)
+	s.Require().NoError(err)
+	_, err = s.dbConn.Exec(`DELETE FROM ledger_entries WHERE balance_id = $1 AND kind = 'win'`, balance.ID)
+	s.Require().NoError(err)
+	_, err = s.dbConn.Exec(`UPDATE free_rounds SET rounds_left = 5 WHERE balance_id = $1`, balance.ID)
+	s.Require().NoError(err)
+
+	found := s.discrepancies(seamlessService, balance.ID)
+	s.Equal([]model.Discrepancy{
+		{Check: model.CheckCash, BalanceID: balance.ID, PlayerName: "reconcile", Currency: "EUR", Stored: 945, Expected: 900},
+		{Check: model.CheckFreeRounds, BalanceID: balance.ID, PlayerName: "reconcile", Currency: "EUR", Ref: "reconcile", Stored: 5, Expected: 3},
+		{Check: model.CheckTransactions, BalanceID: balance.ID, PlayerName: "reconcile", Currency: "EUR", Stored: -100, Expected: -60},
+	}, found)
+
+	s.Require().NoError(seamlessService.Repair(ctx, found))
+	s.Empty(s.discrepancies(seamlessService, balance.ID))
+
+	balance, err = seamlessService.Balance(ctx, "reconcile", "EUR")
+	s.Require().NoError(err)
+	s.Equal(int64(940), balance.Amount)
+	s.Equal(3, *balance.FreeRoundLeft)
+}
+
+func (s *apiTestSuite) discrepancies(seamlessService *postgres.SeamlessService, balanceID int) []model.Discrepancy {
+	discrepancies, err := seamlessService.Reconcile(context.Background())
+	s.Require().NoError(err)
+
+	var found []model.Discrepancy
+	for _, discrepancy := range discrepancies {
+		if discrepancy.BalanceID == balanceID {
+			found = append(found, discrepancy)
+		}
+	}
+	return found
+}
@@ -0,0 +1,295 @@
+-- Upgrades a database created from the original init.sql (one wallet per
+-- player, is_rollback flag, free_round_left on balances) to the current
+-- schema. Run it once, with the server stopped:
+--   psql -v ON_ERROR_STOP=1 -f migrate.sql
+BEGIN;
+
+-- Currencies: the original EUR row was inserted with an explicit id.
+ALTER TABLE currencies
+    ADD COLUMN "exponent" SMALLINT NOT NULL DEFAULT 2 CHECK (exponent BETWEEN 0 AND 18);
+
+CREATE UNIQUE INDEX currencies_code ON currencies (code);
+
+SELECT setval('currencies_id_seq', (SELECT max(id) FROM currencies));
+
+INSERT INTO currencies(code, exponent)
+VALUES ('EUR', 2),
+       ('USD', 2),
+       ('GBP', 2),
+       ('JPY', 0),
+       ('IDR', 2),
+       ('BTC', 8),
+       ('ETH', 18),
+       ('USDT', 6)
+ON CONFLICT (code) DO NOTHING;
+
+-- Balances: one wallet per player and currency, 64-bit minor units.
+DROP INDEX balances_player;
+CREATE UNIQUE INDEX balances_player_currency ON balances (player_name, currency_id);
+
+ALTER TABLE balances
+    ALTER COLUMN amount TYPE BIGINT;
+
+-- Transactions: statuses replace is_rollback. A rolled back row without
+-- amounts is a rollback that arrived before its transaction.
+ALTER TABLE transactions
+    ALTER COLUMN withdraw TYPE BIGINT,
+    ALTER COLUMN deposit TYPE BIGINT,
+    ADD COLUMN "status"                VARCHAR,
+    ADD COLUMN "wagered_bonus_id"      BIGINT,
+    ADD COLUMN "bonus_withdraw"        BIGINT  NOT NULL DEFAULT 0,
+    ADD COLUMN "bonus_deposit"         BIGINT  NOT NULL DEFAULT 0,
+    ADD COLUMN "bonus_converted"       BIGINT  NOT NULL DEFAULT 0,
+    ADD COLUMN "balance_after"         BIGINT,
+    ADD COLUMN "bonus_amount_after"    BIGINT,
+    ADD COLUMN "free_round_left_after" INTEGER,
+    ADD COLUMN "close_round"           BOOLEAN NOT NULL DEFAULT FALSE;
+
+UPDATE transactions
+SET status = CASE
+                 WHEN NOT is_rollback THEN 'committed'
+                 WHEN withdraw = 0 AND deposit = 0 AND charge_free_rounds IS NULL THEN 'tombstone_rolled_back'
+                 ELSE 'rolled_back' END;
+
+ALTER TABLE transactions
+    ALTER COLUMN status SET NOT NULL,
+    DROP COLUMN is_rollback;
+
+DROP INDEX transactions_balance_idx;
+CREATE INDEX transactions_balance_idx ON transactions (balance_id, id);
+CREATE INDEX transactions_round_idx ON transactions (balance_id, game_round_ref);
+CREATE INDEX transactions_created_idx ON transactions (balance_id, created_at);
+
+CREATE TABLE transaction_transitions
+(
+    "id"             BIGSERIAL NOT NULL PRIMARY KEY,
+    "transaction_id" BIGINT    NOT NULL,
+    "from_status"    VARCHAR,
+    "to_status"      VARCHAR   NOT NULL,
+    "reason"         VARCHAR   NOT NULL,
+    "created_at"     TIMESTAMP WITHOUT TIME ZONE NOT NULL,
+    CONSTRAINT
+        fk_transaction FOREIGN KEY (transaction_id) REFERENCES transactions (id)
+);
+
+CREATE INDEX transaction_transitions_transaction_idx ON transaction_transitions (transaction_id);
+
+INSERT INTO transaction_transitions(transaction_id, from_status, to_status, reason, created_at)
+SELECT id, NULL, status, 'migration', coalesce(updated_at, NOW())
+FROM transactions;
+
+-- Game rounds of the stored transactions are closed.
+CREATE TABLE game_rounds
+(
+    "id"             BIGSERIAL NOT NULL PRIMARY KEY,
+    "balance_id"     BIGINT    NOT NULL,
+    "game_round_ref" VARCHAR   NOT NULL,
+    "game_id"        VARCHAR,
+    "status"         VARCHAR   NOT NULL,
+    "total_bet"      BIGINT    NOT NULL DEFAULT 0,
+    "total_win"      BIGINT    NOT NULL DEFAULT 0,
+    "transactions"   INTEGER   NOT NULL DEFAULT 0,
+    "close_reason"   VARCHAR,
+    "opened_at"      TIMESTAMP WITHOUT TIME ZONE NOT NULL,
+    "updated_at"     TIMESTAMP WITHOUT TIME ZONE NOT NULL,
+    "closed_at"      TIMESTAMP WITHOUT TIME ZONE,
+    CONSTRAINT
+        fk_balance FOREIGN KEY (balance_id) REFERENCES balances (id)
+);
+
+CREATE UNIQUE INDEX game_rounds_uniq_idx ON game_rounds (balance_id, game_round_ref);
+CREATE INDEX game_rounds_open_idx ON game_rounds (updated_at) WHERE status = 'open';
+
+INSERT INTO game_rounds(balance_id, game_round_ref, game_id, status, total_bet, total_win, transactions,
+                        close_reason, opened_at, updated_at, closed_at)
+SELECT balance_id, game_round_ref, max(game_id), 'closed', sum(withdraw), sum(deposit), count(*),
+       'migration', coalesce(min(created_at), NOW()), coalesce(max(updated_at), NOW()), coalesce(max(updated_at), NOW())
+FROM transactions
+WHERE game_round_ref IS NOT NULL AND status = 'committed'
+GROUP BY balance_id, game_round_ref;
+
+-- Free rounds: the rounds left on a wallet become a campaign with bonusId
+-- "legacy", withdrawAndDeposit charges them with that bonusId.
+CREATE TABLE free_rounds
+(
+    "id"          BIGSERIAL NOT NULL PRIMARY KEY,
+    "balance_id"  BIGINT    NOT NULL,
+    "bonus_id"    VARCHAR   NOT NULL,
+    "game_ids"    VARCHAR[] NOT NULL DEFAULT '{}',
+    "bet_value"   BIGINT    NOT NULL DEFAULT 0,
+    "rounds"      INTEGER   NOT NULL CHECK (rounds > 0),
+    "rounds_left" INTEGER   NOT NULL CHECK (rounds_left >= 0),
+    "status"      VARCHAR   NOT NULL,
+    "expires_at"  TIMESTAMP WITHOUT TIME ZONE,
+    "created_at"  TIMESTAMP WITHOUT TIME ZONE NOT NULL,
+    "updated_at"  TIMESTAMP WITHOUT TIME ZONE NOT NULL,
+    CONSTRAINT
+        fk_balance FOREIGN KEY (balance_id) REFERENCES balances (id)
+);
+
+CREATE UNIQUE INDEX free_rounds_uniq_idx ON free_rounds (balance_id, bonus_id);
+
+INSERT INTO free_rounds(balance_id, bonus_id, rounds, rounds_left, status, created_at, updated_at)
+SELECT id, 'legacy', free_round_left, free_round_left, 'active', NOW(), NOW()
+FROM balances
+WHERE free_round_left > 0;
+
+ALTER TABLE balances
+    DROP COLUMN free_round_left;
+
+CREATE TABLE bonuses
+(
+    "id"           BIGSERIAL NOT NULL PRIMARY KEY,
+    "balance_id"   BIGINT    NOT NULL,
+    "bonus_id"     VARCHAR   NOT NULL,
+    "granted"      BIGINT    NOT NULL CHECK (granted > 0),
+    "amount"       BIGINT    NOT NULL,
+    "wagering"     BIGINT    NOT NULL CHECK (wagering > 0),
+    "wagered"      BIGINT    NOT NULL DEFAULT 0,
+    "status"       VARCHAR   NOT NULL,
+    "created_at"   TIMESTAMP WITHOUT TIME ZONE NOT NULL,
+    "updated_at"   TIMESTAMP WITHOUT TIME ZONE NOT NULL,
+    "converted_at" TIMESTAMP WITHOUT TIME ZONE,
+    CONSTRAINT
+        fk_balance FOREIGN KEY (balance_id) REFERENCES balances (id)
+);
+
+CREATE UNIQUE INDEX bonuses_uniq_idx ON bonuses (balance_id, bonus_id);
+
+CREATE TABLE limits
+(
+    "id"           BIGSERIAL NOT NULL PRIMARY KEY,
+    "balance_id"   BIGINT    NOT NULL,
+    "limit_type"   VARCHAR   NOT NULL,
+    "limit_window" VARCHAR   NOT NULL,
+    "amount"       BIGINT    NOT NULL CHECK (amount >= 0),
+    "created_at"   TIMESTAMP WITHOUT TIME ZONE NOT NULL,
+    "updated_at"   TIMESTAMP WITHOUT TIME ZONE NOT NULL,
+    CONSTRAINT
+        fk_balance FOREIGN KEY (balance_id) REFERENCES balances (id)
+);
+
+CREATE UNIQUE INDEX limits_uniq_idx ON limits (balance_id, limit_type, limit_window);
+
+CREATE TABLE players
+(
+    "player_name" VARCHAR NOT NULL PRIMARY KEY,
+    "status"      VARCHAR NOT NULL,
+    "reason"      VARCHAR NOT NULL,
+    "until"       TIMESTAMP WITHOUT TIME ZONE,
+    "updated_at"  TIMESTAMP WITHOUT TIME ZONE NOT NULL
+);
+
+CREATE TABLE player_status_history
+(
+    "id"          BIGSERIAL NOT NULL PRIMARY KEY,
+    "player_name" VARCHAR   NOT NULL,
+    "from_status" VARCHAR   NOT NULL,
+    "to_status"   VARCHAR   NOT NULL,
+    "reason"      VARCHAR   NOT NULL,
+    "until"       TIMESTAMP WITHOUT TIME ZONE,
+    "actor"       VARCHAR   NOT NULL,
+    "created_at"  TIMESTAMP WITHOUT TIME ZONE NOT NULL
+);
+
+CREATE INDEX player_status_history_player_idx ON player_status_history (player_name);
+
+-- Ledger: every committed transaction is posted as a bet and a win against
+-- the house, the rest of the balance is an opening against the cashier, so
+-- cmd/reconcile finds no discrepancies after the upgrade.
+CREATE SEQUENCE ledger_posting_seq;
+
+CREATE TABLE ledger_entries
+(
+    "id"             BIGSERIAL NOT NULL PRIMARY KEY,
+    "posting_id"     BIGINT    NOT NULL,
+    "balance_id"     BIGINT    NOT NULL,
+    "currency_id"    BIGINT    NOT NULL,
+    "transaction_id" BIGINT,
+    "bonus_id"       BIGINT,
+    "account"        VARCHAR   NOT NULL,
+    "kind"           VARCHAR   NOT NULL,
+    "amount"         BIGINT    NOT NULL,
+    "created_at"     TIMESTAMP WITHOUT TIME ZONE NOT NULL,
+    CONSTRAINT
+        fk_balance FOREIGN KEY (balance_id) REFERENCES balances (id),
+    CONSTRAINT
+        fk_currency FOREIGN KEY (currency_id) REFERENCES currencies (id)
+);
+
+CREATE INDEX ledger_entries_balance_idx ON ledger_entries (balance_id, account);
+CREATE INDEX ledger_entries_bonus_idx ON ledger_entries (bonus_id) WHERE bonus_id IS NOT NULL;
+CREATE INDEX ledger_entries_posting_idx ON ledger_entries (posting_id);
+
+CREATE TEMPORARY TABLE migrated_postings ON COMMIT DROP AS
+SELECT nextval('ledger_posting_seq') posting_id, transactions.id transaction_id, transactions.balance_id,
+       balances.currency_id, transactions.withdraw, transactions.deposit,
+       coalesce(transactions.created_at, NOW()) created_at
+FROM transactions
+JOIN balances ON transactions.balance_id = balances.id
+WHERE transactions.status = 'committed' AND (transactions.withdraw <> 0 OR transactions.deposit <> 0);
+
+INSERT INTO ledger_entries(posting_id, balance_id, currency_id, transaction_id, account, kind, amount, created_at)
+SELECT posting_id, balance_id, currency_id, transaction_id, entry.account, entry.kind, entry.amount, created_at
+FROM migrated_postings,
+     LATERAL (VALUES ('cash', 'bet', -withdraw),
+                     ('house', 'bet', withdraw),
+                     ('house', 'win', -deposit),
+                     ('cash', 'win', deposit)) entry(account, kind, amount)
+WHERE entry.amount <> 0;
+
+INSERT INTO ledger_entries(posting_id, balance_id, currency_id, account, kind, amount, created_at)
+SELECT nextval('ledger_posting_seq'), balances.id, balances.currency_id, 'cash', 'opening',
+       balances.amount - coalesce(moved.amount, 0), NOW()
+FROM balances
+LEFT JOIN (
+    SELECT balance_id, sum(deposit - withdraw) amount FROM transactions WHERE status = 'committed' GROUP BY balance_id
+) moved ON moved.balance_id = balances.id
+WHERE balances.amount <> coalesce(moved.amount, 0);
+
+INSERT INTO ledger_entries(posting_id, balance_id, currency_id, account, kind, amount, created_at)
+SELECT posting_id, balance_id, currency_id, 'cashier', 'opening', -amount, created_at
+FROM ledger_entries
+WHERE kind = 'opening';
+
+CREATE TABLE balance_history
+(
+    "id"             BIGSERIAL NOT NULL PRIMARY KEY,
+    "balance_id"     BIGINT    NOT NULL,
+    "transaction_id" BIGINT,
+    "cause"          VARCHAR   NOT NULL,
+    "actor"          VARCHAR   NOT NULL,
+    "amount_before"  BIGINT    NOT NULL,
+    "amount_after"   BIGINT    NOT NULL,
+    "delta"          BIGINT    NOT NULL,
+    "bonus_delta"    BIGINT    NOT NULL,
+    "created_at"     TIMESTAMP WITHOUT TIME ZONE NOT NULL,
+    CONSTRAINT
+        fk_balance FOREIGN KEY (balance_id) REFERENCES balances (id),
+    CONSTRAINT
+        fk_transaction FOREIGN KEY (transaction_id) REFERENCES transactions (id)
+);
+
+CREATE INDEX balance_history_balance_idx ON balance_history (balance_id, created_at);
+
+INSERT INTO balance_history(balance_id, cause, actor, amount_before, amount_after, delta, bonus_delta, created_at)
+SELECT id, 'opening', '', 0, amount, amount, 0, NOW()
+FROM balances
+WHERE amount <> 0;
+
+CREATE TABLE adjustments
+(
+    "id"          BIGSERIAL NOT NULL PRIMARY KEY,
+    "balance_id"  BIGINT    NOT NULL,
+    "amount"      BIGINT    NOT NULL,
+    "reason_code" VARCHAR   NOT NULL,
+    "comment"     VARCHAR   NOT NULL,
+    "actor"       VARCHAR   NOT NULL,
+    "created_at"  TIMESTAMP WITHOUT TIME ZONE NOT NULL,
+    CONSTRAINT
+        fk_balance FOREIGN KEY (balance_id) REFERENCES balances (id)
+);
+
+CREATE INDEX adjustments_balance_idx ON adjustments (balance_id);
+
+COMMIT;
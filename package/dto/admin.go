@@ -0,0 +1,75 @@
+package dto
+
+import "time"
+
+// Admin requests are served on the admin server, its callers are identified
+// by their auth token instead of a callerId.
+
+type CreateWalletReq struct {
+	PlayerName string  `json:"playerName" validate:"required"`
+	Currency   string  `json:"currency" validate:"required,currency"`
+	GameId     *string `json:"gameId"`
+}
+
+// Wallet balance is the cash and the bonus money together.
+type Wallet struct {
+	PlayerName   string `json:"playerName"`
+	Currency     string `json:"currency"`
+	Balance      int64  `json:"balance"`
+	CashBalance  int64  `json:"cashBalance"`
+	BonusBalance int64  `json:"bonusBalance"`
+}
+
+// AdjustBalanceReq credits a positive amount to the wallet's cash or debits
+// a negative one. ReasonCode is correction, goodwill, chargeback,
+// manual_deposit (positive amounts only) or manual_withdrawal (negative
+// only).
+type AdjustBalanceReq struct {
+	PlayerName string `json:"playerName" validate:"required"`
+	Currency   string `json:"currency" validate:"required,currency"`
+	Amount     int64  `json:"amount" validate:"required"`
+	ReasonCode string `json:"reasonCode" validate:"required,oneof=correction goodwill chargeback manual_deposit manual_withdrawal"`
+	Comment    string `json:"comment"`
+}
+
+type AdjustBalanceResp struct {
+	AdjustmentId string `json:"adjustmentId"`
+	Wallet       Wallet `json:"wallet"`
+}
+
+type ListAdjustmentsReq struct {
+	PlayerName string `json:"playerName" validate:"required"`
+}
+
+type Adjustment struct {
+	AdjustmentId string    `json:"adjustmentId"`
+	Currency     string    `json:"currency"`
+	Amount       int64     `json:"amount"`
+	ReasonCode   string    `json:"reasonCode"`
+	Comment      string    `json:"comment,omitempty"`
+	Actor        string    `json:"actor"`
+	CreatedAt    time.Time `json:"createdAt"`
+}
+
+type ListAdjustmentsResp struct {
+	Adjustments []Adjustment `json:"adjustments"`
+}
+
+// ForceRollbackReq rolls a transaction back in whichever wallet holds it.
+type ForceRollbackReq struct {
+	TransactionRef string `json:"transactionRef" validate:"required"`
+}
+
+type GetTransactionReq struct {
+	TransactionRef string `json:"transactionRef" validate:"required"`
+}
+
+// EditFreeRoundsReq changes the rounds left or the expiry of an active or
+// used campaign, a missing field is kept.
+type EditFreeRoundsReq struct {
+	PlayerName     string     `json:"playerName" validate:"required"`
+	Currency       string     `json:"currency" validate:"required,currency"`
+	BonusId        string     `json:"bonusId" validate:"required"`
+	FreeroundsLeft *int       `json:"freeroundsLeft" validate:"omitempty,min=0"`
+	ExpiresAt      *time.Time `json:"expiresAt"`
+}
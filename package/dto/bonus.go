@@ -0,0 +1,37 @@
+package dto
+
+import "time"
+
+// GrantBonusReq grants Amount of bonus money to the wallet in Currency. It is
+// converted to cash once bets carrying BonusId reach WageringRequirement.
+// It is an admin request.
+type GrantBonusReq struct {
+	PlayerName          string `json:"playerName" validate:"required"`
+	Currency            string `json:"currency" validate:"required,currency"`
+	BonusId             string `json:"bonusId" validate:"required"`
+	Amount              int64  `json:"amount" validate:"min=1"`
+	WageringRequirement int64  `json:"wageringRequirement" validate:"min=1"`
+}
+
+// Bonus is bonus money of a wallet, status is active or converted. Amounts
+// are in minor units of the currency.
+type Bonus struct {
+	BonusId             string     `json:"bonusId"`
+	Currency            string     `json:"currency"`
+	Granted             int64      `json:"granted"`
+	Amount              int64      `json:"amount"`
+	WageringRequirement int64      `json:"wageringRequirement"`
+	Wagered             int64      `json:"wagered"`
+	Status              string     `json:"status"`
+	CreatedAt           time.Time  `json:"createdAt"`
+	ConvertedAt         *time.Time `json:"convertedAt,omitempty"`
+}
+
+type ListBonusesReq struct {
+	CallerId   int    `json:"callerId" validate:"required"`
+	PlayerName string `json:"playerName" validate:"required"`
+}
+
+type ListBonusesResp struct {
+	Bonuses []Bonus `json:"bonuses"`
+}
@@ -0,0 +1,14 @@
+package dto
+
+type GetCurrenciesReq struct{}
+
+// Currency describes a registered currency, amounts in it are sent in units
+// of 10^-Exponent.
+type Currency struct {
+	Code     string `json:"code"`
+	Exponent int    `json:"exponent"`
+}
+
+type GetCurrenciesResp struct {
+	Currencies []Currency `json:"currencies"`
+}
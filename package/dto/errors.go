@@ -0,0 +1,48 @@
+package dto
+
+// Wallet error codes sent in the JSON-RPC error object.
+const (
+	CodeNotEnoughMoney         = 1
+	CodeIllegalCurrency        = 2
+	CodeNegativeDeposit        = 3
+	CodeNegativeWithdrawal     = 4
+	CodeSpendingBudgetExceeded = 5
+	CodeTransactionRollback    = 6
+	CodeAmountOverflow         = 7
+	CodeTransactionConflict    = 8
+	CodeRoundNotFound          = 9
+	CodeFreeRoundsNotFound     = 10
+	CodeNotEnoughFreeRounds    = 11
+	CodeFreeRoundsExists       = 12
+	CodeBonusExists            = 13
+	CodePlayerBlocked          = 14
+	CodePlayerStatusLocked     = 15
+	CodePlayerNotFound         = 16
+	CodeWalletExists           = 17
+	CodeTransactionNotFound    = 18
+	CodeWalletNotFound         = 19
+	CodeForeignTransaction     = 20
+	CodeInvalidTransition      = 21
+	CodeTransactionBusy        = 22
+	CodeSnapshotMissing        = 23
+	CodeInvalidFreeRound       = 24
+	CodeInvalidBonus           = 25
+	CodeInvalidLimit           = 26
+	CodeInvalidPlayerStatus    = 27
+	CodeInvalidAdjustment      = 28
+	CodeLedgerUnbalanced       = 29
+	CodeLedgerMismatch         = 30
+)
+
+type GetErrorCodesReq struct{}
+
+type ErrorCode struct {
+	Code       int    `json:"code"`
+	Message    string `json:"message"`
+	Retryable  bool   `json:"retryable"`
+	HttpStatus int    `json:"httpStatus"`
+}
+
+type GetErrorCodesResp struct {
+	Errors []ErrorCode `json:"errors"`
+}
@@ -0,0 +1,45 @@
+package dto
+
+import "time"
+
+// GrantFreeRoundsReq grants Freerounds rounds of BetValue each to the wallet
+// in Currency. GameIds limits the games they may be played in, empty allows
+// every game. It is an admin request.
+type GrantFreeRoundsReq struct {
+	PlayerName string     `json:"playerName" validate:"required"`
+	Currency   string     `json:"currency" validate:"required,currency"`
+	BonusId    string     `json:"bonusId" validate:"required"`
+	GameIds    []string   `json:"gameIds"`
+	BetValue   int64      `json:"betValue" validate:"min=0"`
+	Freerounds int        `json:"freerounds" validate:"min=1"`
+	ExpiresAt  *time.Time `json:"expiresAt"`
+}
+
+type CancelFreeRoundsReq struct {
+	PlayerName string `json:"playerName" validate:"required"`
+	Currency   string `json:"currency" validate:"required,currency"`
+	BonusId    string `json:"bonusId" validate:"required"`
+}
+
+// FreeRounds is a free round campaign, status is active, used, expired or
+// cancelled. BetValue is in minor units of the currency.
+type FreeRounds struct {
+	BonusId        string     `json:"bonusId"`
+	Currency       string     `json:"currency"`
+	GameIds        []string   `json:"gameIds,omitempty"`
+	BetValue       int64      `json:"betValue"`
+	Freerounds     int        `json:"freerounds"`
+	FreeroundsLeft int        `json:"freeroundsLeft"`
+	Status         string     `json:"status"`
+	ExpiresAt      *time.Time `json:"expiresAt,omitempty"`
+	CreatedAt      time.Time  `json:"createdAt"`
+}
+
+type ListFreeRoundsReq struct {
+	CallerId   int    `json:"callerId" validate:"required"`
+	PlayerName string `json:"playerName" validate:"required"`
+}
+
+type ListFreeRoundsResp struct {
+	FreeRounds []FreeRounds `json:"freeRounds"`
+}
@@ -0,0 +1,49 @@
+package dto
+
+import "time"
+
+type GetLedgerEntriesReq struct {
+	PlayerName string `json:"playerName" validate:"required"`
+	Currency   string `json:"currency" validate:"required,currency"`
+}
+
+// LedgerEntry is one leg of a posting, the entries of a posting sum to zero.
+type LedgerEntry struct {
+	PostingId     string    `json:"postingId"`
+	TransactionId string    `json:"transactionId,omitempty"`
+	Account       string    `json:"account"`
+	Kind          string    `json:"kind"`
+	Amount        int64     `json:"amount"`
+	CreatedAt     time.Time `json:"createdAt"`
+}
+
+type GetLedgerEntriesResp struct {
+	Entries []LedgerEntry `json:"entries"`
+}
+
+// GetBalanceHistoryReq asks for the changes of a wallet made in [from, to),
+// a missing bound is open.
+type GetBalanceHistoryReq struct {
+	PlayerName string     `json:"playerName" validate:"required"`
+	Currency   string     `json:"currency" validate:"required,currency"`
+	From       *time.Time `json:"from,omitempty"`
+	To         *time.Time `json:"to,omitempty"`
+}
+
+// BalanceChange is one change of a wallet's cash. Cause is the method that
+// made it, Actor is who called it, as caller:<callerId> for wallet API calls
+// and admin:<name> for admin ones.
+type BalanceChange struct {
+	TransactionId string    `json:"transactionId,omitempty"`
+	Cause         string    `json:"cause"`
+	Actor         string    `json:"actor"`
+	BalanceBefore int64     `json:"balanceBefore"`
+	BalanceAfter  int64     `json:"balanceAfter"`
+	Delta         int64     `json:"delta"`
+	BonusDelta    int64     `json:"bonusDelta"`
+	CreatedAt     time.Time `json:"createdAt"`
+}
+
+type GetBalanceHistoryResp struct {
+	Changes []BalanceChange `json:"changes"`
+}
@@ -0,0 +1,50 @@
+package dto
+
+import "time"
+
+// SetLimitReq sets a responsible gambling limit. Loss, wager and deposit
+// limits take an amount in minor units and a daily, weekly or monthly
+// window, a session limit takes seconds and the session window. It is an
+// admin request.
+type SetLimitReq struct {
+	PlayerName string `json:"playerName" validate:"required"`
+	Currency   string `json:"currency" validate:"required,currency"`
+	Type       string `json:"type" validate:"required,oneof=loss wager deposit session"`
+	Window     string `json:"window" validate:"required,oneof=daily weekly monthly session"`
+	Amount     int64  `json:"amount" validate:"min=0"`
+}
+
+type RemoveLimitReq struct {
+	PlayerName string `json:"playerName" validate:"required"`
+	Currency   string `json:"currency" validate:"required,currency"`
+	Type       string `json:"type" validate:"required"`
+	Window     string `json:"window" validate:"required"`
+}
+
+type Limit struct {
+	Currency  string    `json:"currency"`
+	Type      string    `json:"type"`
+	Window    string    `json:"window"`
+	Amount    int64     `json:"amount"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+type GetLimitsReq struct {
+	CallerId   int    `json:"callerId" validate:"required"`
+	PlayerName string `json:"playerName" validate:"required"`
+}
+
+type GetLimitsResp struct {
+	Limits []Limit `json:"limits"`
+}
+
+// LimitExceeded is the data of an ErrSpendingBudgetExceeded error raised by a
+// limit. Used is what the window already holds, ResetsAt is missing for a
+// session limit.
+type LimitExceeded struct {
+	Type     string     `json:"type"`
+	Window   string     `json:"window"`
+	Limit    int64      `json:"limit"`
+	Used     int64      `json:"used"`
+	ResetsAt *time.Time `json:"resetsAt,omitempty"`
+}
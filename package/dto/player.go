@@ -0,0 +1,42 @@
+package dto
+
+import "time"
+
+// SetPlayerStatusReq changes the account status of a player. Self-exclusion
+// and cool-off need until, the other statuses have none. It is an admin
+// request.
+type SetPlayerStatusReq struct {
+	PlayerName string     `json:"playerName" validate:"required"`
+	Status     string     `json:"status" validate:"required,oneof=active blocked self_excluded cool_off"`
+	Reason     string     `json:"reason" validate:"required"`
+	Until      *time.Time `json:"until"`
+}
+
+type GetPlayerStatusReq struct {
+	PlayerName string `json:"playerName" validate:"required"`
+}
+
+type PlayerStatus struct {
+	PlayerName string     `json:"playerName"`
+	Status     string     `json:"status"`
+	Reason     string     `json:"reason,omitempty"`
+	Until      *time.Time `json:"until,omitempty"`
+	UpdatedAt  *time.Time `json:"updatedAt,omitempty"`
+}
+
+type GetPlayerStatusHistoryReq struct {
+	PlayerName string `json:"playerName" validate:"required"`
+}
+
+type PlayerStatusChange struct {
+	FromStatus string     `json:"fromStatus"`
+	ToStatus   string     `json:"toStatus"`
+	Reason     string     `json:"reason"`
+	Until      *time.Time `json:"until,omitempty"`
+	Actor      string     `json:"actor"`
+	CreatedAt  time.Time  `json:"createdAt"`
+}
+
+type GetPlayerStatusHistoryResp struct {
+	Changes []PlayerStatusChange `json:"changes"`
+}
@@ -0,0 +1,34 @@
+package dto
+
+import "time"
+
+type GetRoundReq struct {
+	CallerId     int    `json:"callerId" validate:"required"`
+	PlayerName   string `json:"playerName" validate:"required"`
+	Currency     string `json:"currency" validate:"required,currency"`
+	GameRoundRef string `json:"gameRoundRef" validate:"required"`
+}
+
+// Round is the outcome of a game round, status is open, closed or
+// rolled_back. Totals are in minor units of the currency.
+type Round struct {
+	GameRoundRef string     `json:"gameRoundRef"`
+	GameId       *string    `json:"gameId,omitempty"`
+	Currency     string     `json:"currency"`
+	Status       string     `json:"status"`
+	TotalBet     int64      `json:"totalBet"`
+	TotalWin     int64      `json:"totalWin"`
+	Transactions int        `json:"transactions"`
+	CloseReason  *string    `json:"closeReason,omitempty"`
+	OpenedAt     time.Time  `json:"openedAt"`
+	ClosedAt     *time.Time `json:"closedAt,omitempty"`
+}
+
+type ListOpenRoundsReq struct {
+	CallerId   int    `json:"callerId" validate:"required"`
+	PlayerName string `json:"playerName" validate:"required"`
+}
+
+type ListOpenRoundsResp struct {
+	Rounds []Round `json:"rounds"`
+}
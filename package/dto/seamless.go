@@ -1,18 +1,27 @@
+// Package dto holds the wallet request and response objects.
+//
+// Every amount is an integer in minor units of the request currency, the
+// smallest unit the currency has: cents for EUR, yen for JPY. Amounts fit in
+// a signed 64-bit integer.
 package dto
 
 type GetBalanceReq struct {
 	CallerId             int     `json:"callerId" validate:"required"`
 	PlayerName           string  `json:"playerName" validate:"required"`
-	Currency             string  `json:"currency" validate:"required,iso4217"`
+	Currency             string  `json:"currency" validate:"required,currency"`
 	GameId               *string `json:"gameId"`
 	SessionId            *string `json:"sessionId"`
 	SessionAlternativeId *string `json:"sessionAlternativeId"`
 	BonusId              *string `json:"bonusId"`
 }
 
+// GetBalanceResp balance is the cash and the bonus money together, they are
+// also sent apart when the wallet has bonus money.
 type GetBalanceResp struct {
-	Balance        int  `json:"balance" validate:"required"`
-	FreeRoundsLeft *int `json:"freeroundsLeft,omitempty"`
+	Balance        int64  `json:"balance" validate:"required"`
+	CashBalance    *int64 `json:"cashBalance,omitempty"`
+	BonusBalance   *int64 `json:"bonusBalance,omitempty"`
+	FreeRoundsLeft *int   `json:"freeroundsLeft,omitempty"`
 }
 
 type SpinDetails struct {
@@ -23,9 +32,9 @@ type SpinDetails struct {
 type WithdrawAndDepositReq struct {
 	CallerId             int          `json:"callerId" validate:"required"`
 	PlayerName           string       `json:"playerName" validate:"required"`
-	Withdraw             int          `json:"withdraw" validate:"required"`
-	Deposit              int          `json:"deposit" validate:"required"`
-	Currency             string       `json:"currency" validate:"required,iso4217"`
+	Withdraw             int64        `json:"withdraw"`
+	Deposit              int64        `json:"deposit"`
+	Currency             string       `json:"currency" validate:"required,currency"`
 	TransactionRef       string       `json:"transactionRef" validate:"required"`
 	GameRoundRef         *string      `json:"gameRoundRef"`
 	GameId               *string      `json:"gameId"`
@@ -34,12 +43,13 @@ type WithdrawAndDepositReq struct {
 	SessionId            *string      `json:"sessionId"`
 	SessionAlternativeId *string      `json:"sessionAlternativeId"`
 	SpinDetails          *SpinDetails `json:"spinDetails"`
-	BonusId              *string      `json:"bonusId"`
-	ChargeFreeRounds     *int         `json:"chargeFreerounds"`
+	BonusId              *string      `json:"bonusId" validate:"required_with=ChargeFreeRounds"`
+	ChargeFreeRounds     *int         `json:"chargeFreerounds" validate:"omitempty,min=1"`
+	RoundClosed          bool         `json:"roundClosed"`
 }
 
 type WithdrawAndDepositResp struct {
-	NewBalance     int    `json:"newBalance" validate:"required"`
+	NewBalance     int64  `json:"newBalance" validate:"required"`
 	TransactionId  string `json:"transactionId" validate:"required"`
 	FreeRoundsLeft *int   `json:"freeroundsLeft,omitempty"`
 }
@@ -51,5 +61,20 @@ type RollbackTransactionReq struct {
 	GameId               *string `json:"gameId"`
 	SessionId            *string `json:"sessionId"`
 	SessionAlternativeId *string `json:"sessionAlternativeId"`
-	GameRoundRef         *string `json:"roundId"`
+	GameRoundRef         *string `json:"gameRoundRef"`
+}
+
+type RollbackRoundReq struct {
+	CallerId             int     `json:"callerId" validate:"required"`
+	PlayerName           string  `json:"playerName" validate:"required"`
+	Currency             string  `json:"currency" validate:"required,currency"`
+	GameRoundRef         string  `json:"gameRoundRef" validate:"required"`
+	GameId               *string `json:"gameId"`
+	SessionId            *string `json:"sessionId"`
+	SessionAlternativeId *string `json:"sessionAlternativeId"`
+}
+
+type RollbackRoundResp struct {
+	NewBalance     int64 `json:"newBalance"`
+	FreeRoundsLeft *int  `json:"freeroundsLeft,omitempty"`
 }
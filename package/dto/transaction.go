@@ -0,0 +1,45 @@
+package dto
+
+import "time"
+
+// GetTransactionHistoryReq lists the transactions of a player newest first.
+// Every filter is optional, from and to bound the creation time to
+// [from, to). The next page is asked for with the cursor of the previous
+// response, limit is 50 by default. It is an admin request.
+type GetTransactionHistoryReq struct {
+	PlayerName   string     `json:"playerName" validate:"required"`
+	Currency     *string    `json:"currency" validate:"omitempty,currency"`
+	From         *time.Time `json:"from,omitempty"`
+	To           *time.Time `json:"to,omitempty"`
+	GameId       *string    `json:"gameId"`
+	GameRoundRef *string    `json:"gameRoundRef"`
+	BonusId      *string    `json:"bonusId"`
+	Status       *string    `json:"status" validate:"omitempty,oneof=committed rolled_back tombstone_rolled_back failed"`
+	Cursor       string     `json:"cursor"`
+	Limit        int        `json:"limit" validate:"omitempty,min=1,max=500"`
+}
+
+type Transaction struct {
+	TransactionId    string       `json:"transactionId"`
+	TransactionRef   string       `json:"transactionRef"`
+	PlayerName       string       `json:"playerName"`
+	Currency         string       `json:"currency"`
+	Withdraw         int64        `json:"withdraw"`
+	Deposit          int64        `json:"deposit"`
+	GameId           *string      `json:"gameId,omitempty"`
+	GameRoundRef     *string      `json:"gameRoundRef,omitempty"`
+	SessionId        *string      `json:"sessionId,omitempty"`
+	BonusId          *string      `json:"bonusId,omitempty"`
+	ChargeFreeRounds *int         `json:"chargeFreerounds,omitempty"`
+	Status           string       `json:"status"`
+	BalanceAfter     *int64       `json:"balanceAfter,omitempty"`
+	SpinDetails      *SpinDetails `json:"spinDetails,omitempty"`
+	CreatedAt        time.Time    `json:"createdAt"`
+	UpdatedAt        time.Time    `json:"updatedAt"`
+}
+
+// GetTransactionHistoryResp nextCursor is missing on the last page.
+type GetTransactionHistoryResp struct {
+	Transactions []Transaction `json:"transactions"`
+	NextCursor   string        `json:"nextCursor,omitempty"`
+}